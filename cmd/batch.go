@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/batch"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// batchOptions は batch コマンドのフラグです。
+type batchOptions struct {
+	ListFile    string
+	OutputDir   string
+	Audio       bool
+	Manifest    string
+	Incremental bool
+}
+
+var batchOpts batchOptions
+
+// batchCmd は入力リストの各URL・ファイルについて、重複を除外してからパイプラインを実行するコマンドです。
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "入力リストのURL・ファイルを重複を除外してまとめて処理します。",
+	Long: `--batch-list に1行1件で記載したURLまたはファイルパスを順に処理します。
+URLは正規化（計測用パラメータやフラグメントの除去など）した結果で、ファイルは内容のハッシュで重複を判定し、
+同じ入力は一度だけ処理します。行頭に "[high] " のように優先度 (low, normal, high) を付けた入力は、
+優先度の高い順に処理します (指定の無い入力は --priority の優先度)。--incremental を指定すると、--batch-manifest に記録された処理済みの入力をスキップし、
+未処理の入力のみを実行します。`,
+	RunE: batchCommand,
+}
+
+func init() {
+	batchCmd.Flags().StringVar(&batchOpts.ListFile, "batch-list", "", "処理する入力 (URLまたはファイルパス) を1行1件で記載したファイル ('-'を指定すると標準入力から読み込みます)。")
+	batchCmd.Flags().StringVar(&batchOpts.OutputDir, "batch-output-dir", "", "各入力の生成結果を出力するディレクトリ。")
+	batchCmd.Flags().BoolVar(&batchOpts.Audio, "batch-audio", false, "スクリプトに加えてVOICEVOXで音声を合成し、WAVを出力します。")
+	batchCmd.Flags().StringVar(&batchOpts.Manifest, "batch-manifest", "", "処理済みの入力を記録する出力マニフェスト (JSON) のパス。")
+	batchCmd.Flags().BoolVar(&batchOpts.Incremental, "incremental", false, "出力マニフェストと照合し、未処理の入力のみを実行します (--batch-manifest が必要)。")
+}
+
+// batchCommand は、入力リストを読み込んで重複・処理済みの入力を除外し、残りの入力ごとにパイプラインを実行します。
+func batchCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if batchOpts.ListFile == "" || batchOpts.OutputDir == "" {
+		return fmt.Errorf("--batch-list と --batch-output-dir を指定してください")
+	}
+	if batchOpts.Incremental && batchOpts.Manifest == "" {
+		return fmt.Errorf("--incremental には --batch-manifest の指定が必要です")
+	}
+
+	sources, err := readBatchList(cmd, batchOpts.ListFile)
+	if err != nil {
+		return err
+	}
+
+	var items []batch.Item
+	for _, source := range sources {
+		item, err := batch.NewItem(source)
+		if err != nil {
+			slog.WarnContext(ctx, "入力の重複判定キーを算出できないためスキップします。", "source", source, "error", err)
+			continue
+		}
+		items = append(items, item)
+	}
+	items, duplicates := batch.Dedupe(items)
+	if err := sortByPriority(items); err != nil {
+		return err
+	}
+	for _, dup := range duplicates {
+		slog.InfoContext(ctx, "重複した入力をスキップします。", "source", dup.Source, "key", dup.Key)
+	}
+
+	manifest := &batch.Manifest{}
+	if batchOpts.Manifest != "" {
+		manifest, err = batch.LoadManifest(batchOpts.Manifest)
+		if err != nil {
+			return err
+		}
+	}
+
+	var succeeded, failed, skipped int
+	for _, item := range items {
+		if batchOpts.Incremental {
+			if prev, ok := manifest.Processed(item.Key); ok {
+				slog.InfoContext(ctx, "処理済みの入力をスキップします。", "source", item.Source, "output", prev.Output, "processed_at", prev.ProcessedAt)
+				skipped++
+				continue
+			}
+		}
+
+		cfg := opts
+		if item.Priority != "" {
+			cfg.Priority = item.Priority
+		}
+		cfg.ScriptURLs = nil
+		cfg.ScriptFile = ""
+		// スクリプトの履歴は入力ごとに分ける
+		cfg.ScriptID = ""
+		if item.IsURL {
+			cfg.ScriptURLs = []string{item.Source}
+		} else {
+			cfg.ScriptFile = item.Source
+		}
+		// gs:// などのURIを壊さないよう、filepath.Join ではなく文字列で連結する
+		base := strings.TrimRight(batchOpts.OutputDir, "/") + "/" + item.Name()
+		cfg.OutputFile = base + ".txt"
+		cfg.VoicevoxOutput = ""
+		output := cfg.OutputFile
+		if batchOpts.Audio {
+			cfg.OutputFile = ""
+			cfg.VoicevoxOutput = base + ".wav"
+			output = cfg.VoicevoxOutput
+		}
+
+		slog.InfoContext(ctx, "入力を処理します。", "source", item.Source, "output", output, "priority", cfg.Priority)
+		if err := executePipeline(ctx, &cfg); err != nil {
+			slog.ErrorContext(ctx, "入力の処理に失敗しました。", "source", item.Source, "error", err)
+			failed++
+			continue
+		}
+		succeeded++
+
+		if batchOpts.Manifest != "" {
+			manifest.Record(item, output)
+			// 中断しても処理済みの記録が残るよう、1件ごとに保存する
+			if err := manifest.Save(batchOpts.Manifest); err != nil {
+				slog.WarnContext(ctx, "出力マニフェストの保存に失敗しました。", "path", batchOpts.Manifest, "error", err)
+			}
+		}
+	}
+
+	slog.InfoContext(ctx, "バッチ処理が完了しました。",
+		"succeeded", succeeded, "failed", failed, "skipped", skipped, "duplicates", len(duplicates))
+	if failed > 0 {
+		return fmt.Errorf("%d 件の入力の処理に失敗しました", failed)
+	}
+	return nil
+}
+
+// sortByPriority は入力を優先度の高い順に並べ替えます。同じ優先度の入力はリストの順序を保ちます。
+// 優先度の指定が無い入力は --priority の優先度とみなします。
+func sortByPriority(items []batch.Item) error {
+	defaultPriority, err := voicevox.ParsePriority(opts.Priority)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		if _, err := voicevox.ParsePriority(item.Priority); err != nil {
+			return fmt.Errorf("入力 '%s' の優先度が不正です: %w", item.Source, err)
+		}
+	}
+
+	rank := func(item batch.Item) voicevox.Priority {
+		if item.Priority == "" {
+			return defaultPriority
+		}
+		p, _ := voicevox.ParsePriority(item.Priority) // 検証済み
+		return p
+	}
+	slices.SortStableFunc(items, func(a, b batch.Item) int {
+		return cmp.Compare(rank(b), rank(a))
+	})
+	return nil
+}
+
+// readBatchList は入力リストをファイルまたは標準入力から読み込みます。
+func readBatchList(cmd *cobra.Command, path string) ([]string, error) {
+	var r io.Reader = cmd.InOrStdin()
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("入力リストを開けませんでした (%s): %w", path, err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return batch.ReadList(r)
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// extractScriptOptions は extract-script コマンドのフラグです。
+type extractScriptOptions struct {
+	JSON bool
+}
+
+var extractScriptOpts extractScriptOptions
+
+// extractScriptCmd は --embed-script で音声に埋め込んだスクリプトを読み出すコマンドです。
+var extractScriptCmd = &cobra.Command{
+	Use:   "extract-script <wav>",
+	Short: "--embed-script で音声に埋め込んだスクリプト全文を読み出します。",
+	Long: `--embed-script で合成した WAV のメタデータチャンクから、スクリプト全文を標準出力に書き出します。
+WAV にスクリプトが埋め込まれていない場合や WAV 以外の形式の場合は、サイドカーファイル (<出力名>.script.json) から読み出します。
+--json を指定すると、生成・合成時の設定を含めて JSON で出力します。`,
+	Args: cobra.ExactArgs(1),
+	RunE: extractScriptCommand,
+}
+
+func init() {
+	extractScriptCmd.Flags().BoolVar(&extractScriptOpts.JSON, "json", false, "スクリプト全文に加えて、生成・合成時の設定と作成日時を JSON で出力します。")
+}
+
+// extractScriptCommand は、音声ファイルまたはそのサイドカーファイルから埋め込まれたスクリプトを読み出して出力します。
+func extractScriptCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	path := args[0]
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("音声ファイルの読み込みに失敗しました (%s): %w", path, err)
+	}
+	embedded, err := voicevox.ReadEmbeddedScript(data)
+	if sidecar := voicevox.ScriptSidecarPath(path); err != nil && sidecar != path {
+		// WAV 以外の形式の場合は、合成時に書き込んだサイドカーファイルから読み出す
+		sidecarData, readErr := os.ReadFile(sidecar)
+		if readErr != nil {
+			return fmt.Errorf("%s からスクリプトを読み出せませんでした。サイドカーファイル (%s) もありません。--embed-script で合成した音声を指定してください: %w", path, sidecar, err)
+		}
+		slog.InfoContext(ctx, "サイドカーファイルからスクリプトを読み出します。", "path", sidecar)
+		embedded, err = voicevox.ReadEmbeddedScript(sidecarData)
+	}
+	if err != nil {
+		return err
+	}
+
+	if extractScriptOpts.JSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(embedded); err != nil {
+			return fmt.Errorf("スクリプトの出力に失敗しました: %w", err)
+		}
+		return nil
+	}
+	if _, err := io.WriteString(cmd.OutOrStdout(), embedded.Script); err != nil {
+		return fmt.Errorf("スクリプトの出力に失敗しました: %w", err)
+	}
+	return nil
+}
@@ -1,12 +1,21 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"prototypus-ai-doc-go/internal/builder"
+	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/profile"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/scriptversion"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // generateCmd はナレーションスクリプト生成のメインコマンドです。
@@ -14,7 +23,8 @@ var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "AIにナレーションスクリプトを生成させます。",
 	Long: `AIに渡す元となる文章を指定し、ナレーションスクリプトを生成します。
-Webページやファイル、標準入力から文章を読み込むことができます。`,
+Webページやファイル、標準入力から文章を読み込むことができます。
+--voicevox を省略するとスクリプトのテキストのみを出力します。確認・編集したスクリプトは synthesize で音声化できます。`,
 	RunE: generateCommand,
 }
 
@@ -26,8 +36,73 @@ func generateCommand(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("voicevox") && cmd.Flags().Changed("output-file") {
 		return fmt.Errorf("--voicevoxオプションと--output-fileオプションは同時に指定できません")
 	}
+	if opts.ScriptFormat != "" && !slices.Contains(script.Formats, opts.ScriptFormat) {
+		return fmt.Errorf("--script-format には %s のいずれかを指定してください: '%s'", strings.Join(script.Formats, ", "), opts.ScriptFormat)
+	}
+	if opts.SourceType != "" && !slices.Contains(config.SourceTypes, opts.SourceType) {
+		return fmt.Errorf("--source-type には %s のいずれかを指定してください: '%s'", strings.Join(config.SourceTypes, ", "), opts.SourceType)
+	}
+	if !slices.Contains(script.StrayBracketModes, opts.StrayBrackets) {
+		return fmt.Errorf("--stray-brackets には %s のいずれかを指定してください: '%s'", strings.Join(script.StrayBracketModes, ", "), opts.StrayBrackets)
+	}
+	if opts.GenerateMetadata && !cmd.Flags().Changed("voicevox") && !cmd.Flags().Changed("output-file") {
+		return fmt.Errorf("--generate-metadata はメタ情報を出力先の隣に書き込むため、--voicevox または --output-file を指定してください")
+	}
+	if opts.ScriptID != "" {
+		if err := scriptversion.ValidateSubjectID(opts.ScriptID); err != nil {
+			return fmt.Errorf("--script-id: %w", err)
+		}
+	}
+	if opts.PrintPrompt {
+		if cmd.Flags().Changed("voicevox") || cmd.Flags().Changed("output-file") {
+			return fmt.Errorf("--print-prompt はプロンプトを標準出力に出力して終了するため、--voicevox・--output-file と同時に指定できません")
+		}
+		return printPrompt(ctx, &opts, cmd.OutOrStdout())
+	}
+
+	return executePipeline(ctx, &opts)
+}
+
+// printPrompt は AI を呼び出さずに、スクリプト生成に使うプロンプト全文を組み立てて out に出力します (--print-prompt)。
+func printPrompt(ctx context.Context, cfg *config.Config, out io.Writer) error {
+	appCtx, err := builder.BuildPromptContainer(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("コンテナの構築に失敗しました: %w", err)
+	}
+	defer func() {
+		if closeErr := appCtx.Close(); closeErr != nil {
+			slog.ErrorContext(ctx, "コンテナのクローズに失敗しました", "error", closeErr)
+		}
+	}()
+
+	prompt, err := appCtx.Prompter.Prompt(ctx)
+	if err != nil {
+		return fmt.Errorf("プロンプトの組み立てに失敗しました: %w", err)
+	}
+	_, err = fmt.Fprintln(out, prompt)
+	return err
+}
+
+// executePipeline は、設定からコンテナを構築してパイプラインを実行し、最後にリソースを解放します。
+// --record-db が指定されている場合は、実行結果を実行履歴に記録します。
+func executePipeline(ctx context.Context, cfg *config.Config) error {
+	return recordRun(ctx, cfg.RecordDB, pipelineRecord(cfg), func(ctx context.Context) error {
+		return runPipeline(ctx, cfg)
+	})
+}
+
+// runPipeline は、設定からコンテナを構築してパイプラインを実行し、最後にリソースを解放します。
+// 入力のフロントマターで読み込んだ話者定義は、batch などで次の入力に持ち越さないよう実行後に元へ戻します。
+func runPipeline(ctx context.Context, cfg *config.Config) error {
+	defer voicevox.SaveSpeakers()()
+
+	if cfg.ProfileStages {
+		var prof *profile.Profile
+		ctx, prof = profile.WithProfile(ctx)
+		defer prof.Log(ctx)
+	}
 
-	appCtx, err := builder.BuildContainer(ctx, &opts)
+	appCtx, err := builder.BuildContainer(ctx, cfg)
 	if err != nil {
 		// コンテナの構築エラーをラップして返す
 		return fmt.Errorf("コンテナの構築に失敗しました: %w", err)
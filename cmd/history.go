@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/history"
+)
+
+// historyOptions は history コマンドのフラグです。
+type historyOptions struct {
+	Query  string
+	Status string
+	Since  string
+	Limit  int
+	JSON   bool
+}
+
+var historyOpts historyOptions
+
+// historyCmd は --record-db に記録した実行履歴を検索・表示するコマンドです。
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "--record-db に記録した実行履歴を検索して表示します。",
+	Long: `--record-db に記録した実行履歴を新しい順に表示します。
+--query で入力・出力先・エラーに含まれる文字列、--mode・--model で生成モード・モデル、
+--status で成否、--since で開始日時を絞り込めます。`,
+	RunE: historyCommand,
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyOpts.Query, "query", "", "入力 (URL・ファイルパス)・出力先・エラーに含まれる文字列で絞り込みます。")
+	historyCmd.Flags().StringVar(&historyOpts.Status, "status", "", "実行結果 (succeeded, failed) で絞り込みます。")
+	historyCmd.Flags().StringVar(&historyOpts.Since, "since", "", "指定した日付 (例: 2025-01-31) 以降、または指定した期間 (例: 72h) 以内に開始した実行に絞り込みます。")
+	historyCmd.Flags().IntVar(&historyOpts.Limit, "limit", 20, "表示する件数の上限。0の場合は全件表示します。")
+	historyCmd.Flags().BoolVar(&historyOpts.JSON, "json", false, "表ではなく、1行1件のJSONで出力します。")
+}
+
+// historyCommand は、実行履歴を読み込んで条件で絞り込み、新しい順に表示します。
+func historyCommand(cmd *cobra.Command, args []string) error {
+	if opts.RecordDB == "" {
+		return fmt.Errorf("--record-db で実行履歴のパスを指定してください")
+	}
+	statuses := []string{history.StatusSucceeded, history.StatusFailed}
+	if historyOpts.Status != "" && !slices.Contains(statuses, historyOpts.Status) {
+		return fmt.Errorf("--status には %s のいずれかを指定してください: '%s'", strings.Join(statuses, ", "), historyOpts.Status)
+	}
+
+	q := history.Query{Text: historyOpts.Query, Status: historyOpts.Status, Limit: historyOpts.Limit}
+	// --mode・--model は既定値を持つため、明示的に指定した場合のみ絞り込みに使う
+	if cmd.Flags().Changed("mode") {
+		q.Mode = opts.Mode
+	}
+	if cmd.Flags().Changed("model") {
+		q.Model = opts.AIModel
+	}
+	if historyOpts.Since != "" {
+		since, err := parseSince(historyOpts.Since)
+		if err != nil {
+			return err
+		}
+		q.Since = since
+	}
+
+	records, err := history.Load(opts.RecordDB)
+	if err != nil {
+		return err
+	}
+	records = history.Search(records, q)
+
+	if historyOpts.JSON {
+		enc := json.NewEncoder(cmd.OutOrStdout())
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				return fmt.Errorf("実行履歴の出力に失敗しました: %w", err)
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STARTED\tCOMMAND\tMODE\tMODEL\tSTATUS\tELAPSED\tAUDIO\tTOKENS(IN/OUT)\tCOST(USD)\tINPUT\tOUTPUT")
+	for _, rec := range records {
+		cost := "-"
+		if rec.CostUSD > 0 {
+			cost = fmt.Sprintf("%.4f", rec.CostUSD)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%.1fs\t%.1fs\t%d/%d\t%s\t%s\t%s\n",
+			rec.StartedAt.Local().Format("2006-01-02 15:04:05"), rec.Command, orDash(rec.Mode), orDash(rec.Model), rec.Status,
+			rec.ElapsedSec, rec.AudioSec, rec.PromptTokens, rec.OutputTokens, cost, orDash(rec.Input), orDash(rec.Output))
+	}
+	return tw.Flush()
+}
+
+// parseSince は --since の値を、日付 (ローカル時刻の0時) または現在からの期間として解釈します。
+func parseSince(v string) (time.Time, error) {
+	if t, err := time.ParseInLocation(time.DateOnly, v, time.Local); err == nil {
+		return t, nil
+	}
+	if d, err := time.ParseDuration(v); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("--since には日付 (例: 2025-01-31) または期間 (例: 72h) を指定してください: '%s'", v)
+}
+
+// orDash は空の値を表示用の "-" に置き換えます。
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// recordRun は --record-db が指定されている場合に run の実行を計測し、rec に結果を加えて実行履歴に追記します。
+// 履歴の記録に失敗しても警告を出すのみで、run のエラーをそのまま返します。
+func recordRun(ctx context.Context, path string, rec history.Record, run func(context.Context) error) error {
+	if path == "" {
+		return run(ctx)
+	}
+
+	ctx, r := history.WithRun(ctx)
+	started := time.Now()
+	err := run(ctx)
+
+	r.Fill(&rec, started, err)
+	if recordErr := history.Append(path, rec); recordErr != nil {
+		slog.WarnContext(ctx, "実行履歴の記録に失敗しました。", "path", path, "error", recordErr)
+	}
+	return err
+}
+
+// pipelineRecord は生成パイプラインの実行履歴のうち、設定から決まる項目を返します。
+// URL もファイルも指定されていない場合の入力は標準入力 ("-") として記録します。
+func pipelineRecord(cfg *config.Config) history.Record {
+	rec := history.Record{
+		Command: "generate",
+		Input:   "-",
+		Mode:    cfg.Mode,
+		Model:   cfg.AIModel,
+		Output:  cmp.Or(cfg.VoicevoxOutput, cfg.OutputFile),
+	}
+	if len(cfg.ScriptURLs) > 0 {
+		rec.Input = strings.Join(cfg.ScriptURLs, " ")
+	} else if cfg.ScriptFile != "" {
+		rec.Input = cfg.ScriptFile
+	}
+	return rec
+}
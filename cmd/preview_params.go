@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/builder"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// previewParamsOptions は preview-params コマンドのフラグです。
+type previewParamsOptions struct {
+	Text       string
+	Speaker    string
+	Style      string
+	OutputDir  string
+	Speed      []float64
+	Pitch      []float64
+	Intonation []float64
+}
+
+var previewOpts previewParamsOptions
+
+// previewParamsCmd は合成パラメータを変えた複数設定でサンプル文を合成し、聴き比べ用のWAVを出力するコマンドです。
+var previewParamsCmd = &cobra.Command{
+	Use:   "preview-params",
+	Short: "合成パラメータを変えた複数設定でサンプル文を合成し、比較用のWAVを出力します。",
+	Long: `短いサンプル文を、話速・ピッチ・抑揚を変えた設定ごとに VOICEVOX エンジンで合成し、
+preview_speed_1.2.wav のようなファイル名で並べて出力します。各設定の尺も一覧で表示します。
+AIによるスクリプト生成は行わないため、Gemini API キーは不要です。`,
+	RunE: previewParamsCommand,
+}
+
+func init() {
+	previewParamsCmd.Flags().StringVar(&previewOpts.Text, "text", "こんにちは、ずんだもんなのだ。今日は合成パラメータの違いを聴き比べてみるのだ。", "合成するサンプル文。")
+	previewParamsCmd.Flags().StringVar(&previewOpts.Speaker, "speaker", "ずんだもん", "合成する話者 (ずんだもん, めたん)。")
+	previewParamsCmd.Flags().StringVar(&previewOpts.Style, "style", "ノーマル", "合成するスタイル (ノーマル, あまあま など)。")
+	previewParamsCmd.Flags().StringVar(&previewOpts.OutputDir, "preview-dir", ".", "比較用WAVの出力先ディレクトリ (例: ./preview, gs://my-bucket/preview)。")
+	previewParamsCmd.Flags().Float64SliceVar(&previewOpts.Speed, "speed", []float64{0.8, 1.0, 1.2}, "比較する話速 (speedScale) の値。")
+	previewParamsCmd.Flags().Float64SliceVar(&previewOpts.Pitch, "pitch", nil, "比較するピッチ (pitchScale) の値 (例: -0.05,0,0.05)。")
+	previewParamsCmd.Flags().Float64SliceVar(&previewOpts.Intonation, "intonation", nil, "比較する抑揚 (intonationScale) の値 (例: 0.8,1.0,1.2)。")
+}
+
+// previewCase は1つの比較設定です。
+type previewCase struct {
+	name   string
+	params voicevox.SynthesisParams
+}
+
+// previewResult は比較設定ごとの合成結果です。
+type previewResult struct {
+	name     string
+	path     string
+	duration float64 // 秒
+}
+
+// buildPreviewCases はフラグで指定された値から比較設定を組み立てます。先頭はパラメータを上書きしない基準設定です。
+func buildPreviewCases(o previewParamsOptions) []previewCase {
+	cases := []previewCase{{name: "base"}}
+	add := func(param string, values []float64, set func(*voicevox.SynthesisParams, *float64)) {
+		for _, v := range values {
+			var p voicevox.SynthesisParams
+			set(&p, &v)
+			cases = append(cases, previewCase{
+				name:   fmt.Sprintf("%s_%s", param, strconv.FormatFloat(v, 'f', -1, 64)),
+				params: p,
+			})
+		}
+	}
+	add("speed", o.Speed, func(p *voicevox.SynthesisParams, v *float64) { p.SpeedScale = v })
+	add("pitch", o.Pitch, func(p *voicevox.SynthesisParams, v *float64) { p.PitchScale = v })
+	add("intonation", o.Intonation, func(p *voicevox.SynthesisParams, v *float64) { p.IntonationScale = v })
+	return cases
+}
+
+// previewParamsCommand は、比較設定ごとにサンプル文を合成して出力し、尺の一覧を表示します。
+func previewParamsCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if strings.TrimSpace(previewOpts.Text) == "" {
+		return fmt.Errorf("--text にサンプル文を指定してください")
+	}
+
+	appCtx, err := builder.BuildVoiceContainer(ctx, &opts)
+	if err != nil {
+		return fmt.Errorf("アプリケーションの初期化に失敗しました: %w", err)
+	}
+	defer func() {
+		if closeErr := appCtx.Close(); closeErr != nil {
+			slog.WarnContext(ctx, "リソースのクローズに失敗しました", "error", closeErr)
+		}
+	}()
+
+	speakerTag := "[" + strings.Trim(previewOpts.Speaker, "[]") + "]"
+	styleTag := "[" + strings.Trim(previewOpts.Style, "[]") + "]"
+	// gs:// などのURIを壊さないよう、filepath.Join ではなく文字列で連結する
+	dir := strings.TrimRight(previewOpts.OutputDir, "/")
+
+	var results []previewResult
+	for _, c := range buildPreviewCases(previewOpts) {
+		wavData, err := appCtx.Engine.SynthesizeText(ctx, previewOpts.Text, speakerTag, styleTag, c.params)
+		if err != nil {
+			return fmt.Errorf("設定 %s の合成に失敗しました: %w", c.name, err)
+		}
+		w, err := audio.ParseWAV(wavData)
+		if err != nil {
+			return fmt.Errorf("設定 %s のWAV解析に失敗しました: %w", c.name, err)
+		}
+
+		path := dir + "/preview_" + c.name + ".wav"
+		if err := appCtx.RemoteIO.Writer.Write(ctx, path, bytes.NewReader(wavData), "audio/wav"); err != nil {
+			return fmt.Errorf("音声ファイルの書き込みに失敗しました (%s): %w", path, err)
+		}
+		slog.InfoContext(ctx, "プレビュー音声を出力しました。", "setting", c.name, "path", path, "duration", w.Duration())
+		results = append(results, previewResult{name: c.name, path: path, duration: w.Duration()})
+	}
+
+	return printPreviewReport(cmd, results)
+}
+
+// printPreviewReport は比較設定ごとの尺を基準設定との差分とともに表形式で出力します。
+func printPreviewReport(cmd *cobra.Command, results []previewResult) error {
+	tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SETTING\tDURATION\tDIFF\tFILE")
+	base := results[0].duration
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%.2fs\t%+.2fs\t%s\n", r.name, r.duration, r.duration-base, r.path)
+	}
+	return tw.Flush()
+}
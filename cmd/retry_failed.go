@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/retryqueue"
+	"prototypus-ai-doc-go/internal/scriptversion"
+)
+
+// maxRetryAttempts は retry-failed で再処理する失敗回数の上限です。これ以上失敗したエントリはスキップします。
+const maxRetryAttempts = 5
+
+// retryFailedCmd は再試行キューに保存された入力をまとめて再処理するコマンドです。
+var retryFailedCmd = &cobra.Command{
+	Use:   "retry-failed",
+	Short: "生成に失敗して再試行キューに保存された入力を再処理します。",
+	Long: `--retry-queue-dir で指定した再試行キューから、AIによる生成に失敗した入力を読み込み、
+保存時のオプション（モード・モデル・出力先など）で再度パイプラインを実行します。
+成功したエントリはキューから削除されます。`,
+	RunE: retryFailedCommand,
+}
+
+// retryFailedCommand は、再試行キューの各エントリについてパイプラインを再実行します。
+func retryFailedCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if opts.RetryQueueDir == "" {
+		return fmt.Errorf("--retry-queue-dir で再試行キューのディレクトリを指定してください")
+	}
+	queue := retryqueue.New(opts.RetryQueueDir)
+	entries, err := queue.List()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		slog.InfoContext(ctx, "再試行キューは空です。", "dir", opts.RetryQueueDir)
+		return nil
+	}
+
+	var succeeded, failed, skipped int
+	for _, entry := range entries {
+		if entry.Attempts >= maxRetryAttempts {
+			slog.WarnContext(ctx, "失敗回数が上限に達したためスキップします。", "id", entry.ID, "source", entry.Source, "attempts", entry.Attempts, "last_error", entry.LastError)
+			skipped++
+			continue
+		}
+
+		// 元の入力ソースではなく、キューに保存された入力本文から再生成する
+		cfg := opts
+		cfg.ScriptURLs = nil
+		cfg.ScriptFile = queue.InputPath(entry.ID)
+		if entry.Source != "" {
+			// スクリプトの履歴は、キューのファイルではなく元の入力ソースの題材にまとめる
+			cfg.ScriptID = scriptversion.SubjectID(entry.Source)
+		}
+		cfg.Mode = entry.Options.Mode
+		cfg.AIModel = entry.Options.AIModel
+		cfg.OutputFile = entry.Options.OutputFile
+		cfg.VoicevoxOutput = entry.Options.VoicevoxOutput
+		cfg.SourceType = entry.Options.SourceType
+		cfg.ScriptFormat = entry.Options.ScriptFormat
+		cfg.StructuredOutput = entry.Options.StructuredOutput
+		cfg.Bookends = entry.Options.Bookends
+		cfg.MarkConclusion = entry.Options.MarkConclusion
+
+		slog.InfoContext(ctx, "再試行キューのエントリを再処理します。", "id", entry.ID, "source", entry.Source, "attempts", entry.Attempts)
+		if err := executePipeline(ctx, &cfg); err != nil {
+			slog.ErrorContext(ctx, "再処理に失敗しました。", "id", entry.ID, "error", err)
+			failed++
+			continue
+		}
+
+		if err := queue.Remove(entry.ID); err != nil {
+			slog.WarnContext(ctx, "再処理に成功しましたが、キューからの削除に失敗しました。", "id", entry.ID, "error", err)
+		}
+		succeeded++
+	}
+
+	slog.InfoContext(ctx, "再試行キューの処理が完了しました。", "succeeded", succeeded, "failed", failed, "skipped", skipped)
+	if failed > 0 {
+		return fmt.Errorf("%d 件の再処理に失敗しました", failed)
+	}
+	return nil
+}
@@ -3,8 +3,11 @@ package cmd
 import (
 	"github.com/shouni/clibase"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 
 	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // ReviewConfig は、レビュー実行のパラメータです
@@ -18,6 +21,14 @@ func Execute() {
 		PreRunE:  initAppPreRunE,
 		Commands: []*cobra.Command{
 			generateCmd,
+			retryFailedCmd,
+			previewParamsCmd,
+			speakCmd,
+			synthesizeCmd,
+			batchCmd,
+			historyCmd,
+			extractScriptCmd,
+			scriptHistoryCmd,
 		},
 	})
 }
@@ -27,16 +38,114 @@ func initAppPreRunE(cmd *cobra.Command, args []string) error {
 	opts.FillDefaults(config.LoadConfig())
 	opts.Normalize()
 
+	opts.ChangedFlags = make(map[string]bool)
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		opts.ChangedFlags[f.Name] = true
+	})
+
+	if opts.SpeakerConfig != "" {
+		if err := voicevox.LoadSpeakersFromConfig(opts.SpeakerConfig, opts.SpeakerConfigMerge); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // addAppPersistentFlags は、アプリケーション固有の永続フラグをルートコマンドに追加します。
 func addAppPersistentFlags(rootCmd *cobra.Command) {
-	rootCmd.PersistentFlags().StringVarP(&opts.ScriptURL, "script-url", "u", "", "Webページからコンテンツを取得するためのURL。")
+	rootCmd.PersistentFlags().StringArrayVarP(&opts.ScriptURLs, "script-url", "u", nil, "Webページからコンテンツを取得するためのURL。複数回指定すると各ページの本文を見出し付きで連結して1つの入力にします。")
 	rootCmd.PersistentFlags().StringVarP(&opts.ScriptFile, "script-file", "f", "", "入力スクリプトファイルのパス ('-'を指定すると標準入力から読み込みます。)")
 	rootCmd.PersistentFlags().StringVarP(&opts.OutputFile, "output-file", "o", "", "生成されたスクリプトを保存するファイルのパス。省略時は標準出力 (stdout) に出力します。")
-	rootCmd.PersistentFlags().StringVarP(&opts.Mode, "mode", "m", "duet", "スクリプト生成モード。'dialogue', 'solo', 'duet' などを指定します。")
-	rootCmd.PersistentFlags().StringVarP(&opts.VoicevoxOutput, "voicevox", "v", "", "生成されたスクリプトをVOICEVOXエンジンで合成し、指定されたパスに出力します (例: output.wav, gs://my-bucket/audio.wav)。")
+	rootCmd.PersistentFlags().StringVarP(&opts.Mode, "mode", "m", "duet", "スクリプト生成モード。'dialogue', 'solo', 'duet', 'faq' などを指定します。")
+	rootCmd.PersistentFlags().StringVarP(&opts.VoicevoxOutput, "voicevox", "v", "", "生成されたスクリプトをVOICEVOXエンジンで合成し、指定されたパスに出力します (例: output.wav, gs://my-bucket/audio.wav, s3://my-bucket/audio.wav)。")
+	rootCmd.PersistentFlags().StringVar(&opts.S3Endpoint, "s3-endpoint", "", "s3:// への出力に使う S3 互換ストレージのエンドポイントURL (例: http://localhost:9000)。MinIO などに向けてパス形式で接続します。省略時は AWS_ENDPOINT_URL_S3・AWS_ENDPOINT_URL、それも無い場合は AWS S3 に接続します。")
 	rootCmd.PersistentFlags().StringVarP(&opts.AIModel, "model", "g", config.DefaultModel, "使用する Google Gemini モデル名 (例: gemini-2.5-flash, gemini-2.5-pro)")
-	rootCmd.PersistentFlags().DurationVar(&opts.HTTPTimeout, "http-timeout", config.DefaultHTTPTimeout, "Webリクエストのタイムアウト時間 (例: 15s, 1m)。")
+	rootCmd.PersistentFlags().Float64Var(&opts.Temperature, "temperature", 0, "生成時の温度 (0〜2)。未指定の場合は 0.7 を使います。")
+	rootCmd.PersistentFlags().IntVar(&opts.MaxOutputTokens, "max-output-tokens", 0, "1回の生成の最大出力トークン数。指定した場合は上限で打ち切り、継続生成しません。0の場合はモデルの既定値を使います。")
+	rootCmd.PersistentFlags().IntVar(&opts.MaxInputChars, "max-input-chars", config.DefaultMaxInputChars, "入力コンテンツの最大文字数。超過した場合はエラーにします (--auto-summarize 指定時は要約します)。0の場合は制限しません。")
+	rootCmd.PersistentFlags().BoolVar(&opts.AutoSummarize, "auto-summarize", false, "入力が --max-input-chars を超える場合に、分割して先にAIに要約させてからスクリプトを生成します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.EstimateCost, "estimate-cost", false, "生成前に、プロンプトの文字数から概算したトークン数とモデルの単価から生成の費用を見積もって表示し、生成後に実測と比較します。")
+	rootCmd.PersistentFlags().StringVar(&opts.PricingFile, "pricing-file", "", "--estimate-cost と --record-db の費用の算出に使うモデルごとの100万トークンあたりの単価 (米ドル) を定義したYAMLのパス。組み込みの単価表を上書きします。")
+	rootCmd.PersistentFlags().BoolVar(&opts.Stream, "stream", false, "AIの生成結果をストリーミングで受け取り、受信したテキストを逐次標準エラーに表示します。")
+	rootCmd.PersistentFlags().IntVar(&opts.OutputSampleRate, "output-sample-rate", 0, "合成した音声を指定したサンプルレート (Hz) に変換して出力します (例: 48000)。0の場合はエンジンの出力 (通常 24000) のまま出力します。")
+	rootCmd.PersistentFlags().StringVar(&opts.MaxMemory, "max-memory", "", "合成した音声を保持するメモリの上限 (例: 512MiB, 2G)。結合した音声が上限を超える場合はストリーミングで書き込み、それでも超える見込みの場合は合成の前にエラーで終了します。合成の同時実行数も上限に収まるよう抑えます。Go ランタイムのメモリ上限にも設定します。")
+	rootCmd.PersistentFlags().StringVar(&opts.MaxTempDisk, "max-temp-disk", "", "圧縮形式 (.mp3 など) へのエンコードで使う一時ファイルの大きさの上限 (例: 200MiB)。尺とビットレートから見積もり、超える場合は合成の前にエラーで終了します。")
+	rootCmd.PersistentFlags().StringVar(&opts.AudioBitrate, "audio-bitrate", "", "音声を .mp3/.opus/.m4a で出力する際のビットレート (例: 64k)。省略時は形式ごとの既定値 (128k/48k/96k) を使います。")
+	rootCmd.PersistentFlags().DurationVar(&opts.HTTPTimeout, "http-timeout", config.DefaultHTTPTimeout, "Webリクエストと、VOICEVOXエンジンへの合成以外のリクエストのタイムアウト時間 (例: 15s, 1m)。")
+	rootCmd.PersistentFlags().IntVar(&opts.HTTPMaxRetries, "http-max-retries", config.DefaultHTTPMaxRetries, "Webページの取得に失敗した場合のリトライ回数。0の場合はリトライしません。4xx エラーはリトライしません。")
+	rootCmd.PersistentFlags().DurationVar(&opts.HTTPRetryInterval, "http-retry-interval", config.DefaultHTTPRetryInterval, "Webページの取得を最初にリトライするまでの間隔 (例: 2s)。以降は失敗ごとに倍に延ばします (最大 30s、指定値の方が長い場合は指定値)。")
+	rootCmd.PersistentFlags().BoolVar(&opts.Reflow, "reflow", false, "入力の文の途中に入った改行を取り除き、文境界で段落を整形し直します。見出しやリストは保持します (PDF抽出テキスト向け)。")
+	rootCmd.PersistentFlags().StringVar(&opts.Glossary, "glossary", "", "用語の正規形と表記揺れを定義した辞書 (YAML) のパス。生成後のスクリプトの表記を正規形に統一します。")
+	rootCmd.PersistentFlags().StringVar(&opts.PromptFile, "prompt-file", "", "埋め込みのモード別プロンプトの代わりに使う、独自のプロンプトテンプレート (Markdown) のパス。入力本文は {{.InputText}} で参照します。")
+	rootCmd.PersistentFlags().StringVar(&opts.ScriptFormat, "script-format", "", "スクリプトの出力形式 (plain, json, xml, voicevox, markdown)。省略時は生成結果をそのまま出力します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.ReviewFormat, "review-format", false, "生成スクリプトを、各セグメントにレビュー用のコメント欄を設けたMarkdownの表として出力します。コメントを記入したファイルは speak コマンドで合成できます。")
+	rootCmd.PersistentFlags().BoolVar(&opts.ReadableOutput, "readable-output", false, "出力するスクリプトの話者が交代する箇所に空行を挿入し、人が読みやすく整形します。表示用の整形のみで、合成結果には影響しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.SourceType, "source-type", "", "入力ソースの種類 (news, paper, blog, manual)。省略時はURLとページの og:type から推定します。")
+	rootCmd.PersistentFlags().DurationVar(&opts.ChunkDuration, "chunk-duration", 0, "合成したWAVを指定した尺ごとのチャンクに分割し、個別に逐次アップロードします (例: 10m)。0の場合は分割しません。")
+	rootCmd.PersistentFlags().BoolVar(&opts.ProfileStages, "profile-stages", false, "生成・パース・ID解決・合成・結合・書き込みなど各ステージの所要時間を計測し、完了時に内訳をログに出力します。")
+	rootCmd.PersistentFlags().StringVar(&opts.RecordDB, "record-db", "", "実行ごとに入力・モード・モデル・出力先・音声の尺・トークン数・費用の見積もり・所要時間・日時を記録する実行履歴データベース (JSON Lines) のパス。history コマンドで検索できます。")
+	rootCmd.PersistentFlags().BoolVar(&opts.PrintPrompt, "print-prompt", false, "AIを呼び出さずに、スクリプト生成に使うプロンプト全文 (テンプレートに入力を埋め込んだ結果) を標準出力に出力して終了します。APIキーは不要です。")
+	rootCmd.PersistentFlags().BoolVar(&opts.DryRun, "dry-run", false, "音声合成を行わず、セグメント数と過去の合成実行の実測スループットから合成の所要時間を見積もります (--voicevox と併用)。")
+	rootCmd.PersistentFlags().StringVar(&opts.SpeakerConfig, "speaker-config", "", "使用する話者 (VOICEVOX上の話者名とタグ) とスタイル名の対応を定義したJSONのパス。省略時は組み込みの定義 (ずんだもん・めたん) を使います。")
+	rootCmd.PersistentFlags().BoolVar(&opts.SpeakerConfigMerge, "speaker-config-merge", false, "--speaker-config の定義で組み込みの定義を置き換えず、追加・上書きします。")
+	rootCmd.PersistentFlags().BoolVar(&opts.RefreshSpeakers, "refresh-speakers", false, "キャッシュした話者一覧を使わず、VOICEVOXエンジンから再取得します。")
+	rootCmd.PersistentFlags().StringVar(&opts.EngineCACert, "engine-ca-cert", "", "HTTPS経由でVOICEVOXエンジンに接続する際に信頼するCA証明書 (PEM) のパス。自己署名証明書のエンジン向けです。")
+	rootCmd.PersistentFlags().BoolVar(&opts.EngineInsecure, "insecure", false, "VOICEVOXエンジンのTLS証明書の検証をスキップします (非推奨。閉域環境での検証用)。")
+	rootCmd.PersistentFlags().IntVar(&opts.EngineConcurrency, "engine-concurrency", voicevox.DefaultConcurrency, "VOICEVOXエンジンへ同時に投入するセグメント数の上限。エンジンの性能に合わせて調整します。0以下の場合は既定値を使います。")
+	rootCmd.PersistentFlags().BoolVar(&opts.Progress, "progress", false, "音声合成の進捗を「12/30 セグメント完了」の形式で標準エラーに表示します。")
+	rootCmd.PersistentFlags().Float64Var(&opts.EngineRPS, "engine-rps", 0, "VOICEVOXエンジンへの秒間リクエスト数の上限 (例: 2, 0.5)。同時実行数とは独立に投入間隔を平滑化します。0の場合は制限しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.Priority, "priority", "normal", "同じVOICEVOXエンジンを共有するジョブ間での合成の優先度 (low, normal, high)。優先度の低いジョブは、より高い優先度のジョブの実行中は合成を待機します。")
+	rootCmd.PersistentFlags().DurationVar(&opts.SegmentTimeout, "segment-timeout-base", voicevox.DefaultSegmentTimeoutBase, "1セグメントの合成のタイムアウトの基準値。実際のタイムアウトはこれにセグメントの文字数 × --segment-timeout-per-char を加えた値です。")
+	rootCmd.PersistentFlags().DurationVar(&opts.SegmentTimeoutChar, "segment-timeout-per-char", voicevox.DefaultSegmentTimeoutPerChar, "1セグメントの合成のタイムアウトに、1文字あたり加算する時間。")
+	rootCmd.PersistentFlags().StringVar(&opts.AuditLog, "audit-log", "", "VOICEVOXエンジンへの全リクエスト (URL・StyleID・テキスト長・レスポンスサイズ・所要時間) を指定したパスにJSONLで追記します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.AuditLogText, "audit-log-text", false, "監査ログに合成テキストの本文も記録します (--audit-log と併用)。既定では機密配慮のため文字数のみを記録します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.ScheduleByStyle, "schedule-by-style", false, "同一スタイルのセグメントをまとめてエンジンに投入し、スタイル切り替えによるモデル再ロードを減らします。")
+	rootCmd.PersistentFlags().BoolVar(&opts.MergeSegments, "merge-segments", false, "同じ話者・スタイル・合成パラメータで隣接する短いセグメントを、1セグメントの最大文字数 (250文字) 以内で結合してから合成し、エンジンの呼び出し回数を減らします。")
+	rootCmd.PersistentFlags().DurationVar(&opts.SegmentGap, "segment-gap", 0, "合成した音声のセグメント間に挿入する無音の長さ (例: 300ms)。0の場合は隙間なく連結します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.GapOnSpeakerChange, "gap-on-speaker-change", false, "--segment-gap の無音を話者が切り替わる箇所にのみ挿入します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.DurationCheck, "duration-sanity-check", false, "合成後に各セグメントの合成尺を文字数からの推定尺と比較し、大きく乖離するセグメント (記号の羅列など) を警告します。")
+	rootCmd.PersistentFlags().StringArrayVar(&opts.Automate, "automate", nil, "合成パラメータをスクリプトの先頭から末尾にかけて線形に変化させます (例: speedScale:1.0->1.2)。speedScale, pitchScale, intonationScale, volumeScale を指定でき、複数回指定すると併用します。")
+	rootCmd.PersistentFlags().StringVar(&opts.ForeignWords, "foreign-word-handling", script.ForeignWordKeep, "セリフ中の英語の語の読み上げ方法 (keep: そのままエンジンの読みに任せる, katakana: 組み込みの辞書と略語の読みでカタカナに変換してから合成する)。")
+	rootCmd.PersistentFlags().StringVar(&opts.UserDict, "user-dict", "", "固有名詞や専門用語の読み (カタカナ) とアクセント型を定義したユーザー辞書 (JSON または CSV) のパス。合成時に表記を読みに置き換え、アクセント位置を上書きします。")
+	rootCmd.PersistentFlags().BoolVar(&opts.AllowPartial, "allow-partial", false, "一部のセグメントの合成に失敗しても、失敗したセグメントを省略して残りで音声を出力し、失敗した一覧を警告します。すべて失敗した場合はエラーです。")
+	rootCmd.PersistentFlags().Float64Var(&opts.FailFastRate, "fail-fast-rate", 0, "--allow-partial で完了したセグメントのうち合成に失敗した割合がこの値 (例: 0.5) を超えたら、残りの合成を中断してエラーにします。0の場合は中断しません。")
+	rootCmd.PersistentFlags().BoolVar(&opts.SavePartial, "save-partial", false, "キャンセルやエラーで合成が中断した場合に、先頭から完成したセグメントまでを結合した部分WAV (<出力名>.partial.wav) と、含まれる範囲を記録したメタ情報 (<出力名>.partial.json) を保存します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.EmbedScript, "embed-script", false, "合成した音声のWAVのメタデータチャンクに、スクリプト全文と生成・合成時の設定を埋め込みます。WAV以外の形式で出力する場合は <出力名>.script.json に書き込みます。extract-script コマンドで読み出せます。")
+	rootCmd.PersistentFlags().BoolVar(&opts.PreciseSplit, "precise-split", false, "長すぎるセリフを句読点ではなく、VOICEVOXエンジンに問い合わせたアクセント句の境界に沿って分割します。分割位置ごとにエンジンへの問い合わせが発生します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.SegmentMarker, "segment-marker", false, "後段ツールでの自動分割のため、合成した音声の各セグメント境界に短い区切りマーカー音 (正弦波トーン) を挿入します。")
+	rootCmd.PersistentFlags().Float64Var(&opts.SegmentMarkerFreq, "segment-marker-freq", voicevox.DefaultSegmentMarkerFrequency, "--segment-marker で挿入するマーカー音の周波数 (Hz)。出力のサンプルレートの半分未満を指定します。")
+	rootCmd.PersistentFlags().DurationVar(&opts.SegmentMarkerLen, "segment-marker-duration", voicevox.DefaultSegmentMarkerDuration, "--segment-marker で挿入するマーカー音の長さ (例: 20ms)。")
+	rootCmd.PersistentFlags().DurationVar(&opts.NormalizeSilence, "normalize-silence", 0, "結合した音声から指定した長さより長い無音を検出し、指定した長さに詰めます (例: 0.4s)。短い自然な間はそのまま保ちます。0の場合は詰めません。")
+	rootCmd.PersistentFlags().DurationVar(&opts.AutoChapterSilence, "auto-chapter-silence", 0, "指定した長さ以上の無音をチャプター境界とみなし、チャプター一覧 (<出力名>.chapters.json) を出力します (例: 1.5s)。0の場合は検出しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.SegmentDir, "segment-dir", "", "結合した音声に加えて、各セグメントの音声を 01_ずんだもん.wav のような番号と話者名のファイル名で指定したディレクトリに出力します (--voicevox と併用)。")
+	rootCmd.PersistentFlags().BoolVar(&opts.SegmentsOnly, "segments-only", false, "--segment-dir へのセグメントごとの出力のみを行い、結合した音声は出力しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.ABPoints, "ab-points", "", "合成した音声の各セグメントの開始・終了時刻をA-B区間リピート用のJSONとして指定したパスに出力します (--voicevox と併用)。")
+	rootCmd.PersistentFlags().StringVar(&opts.Subtitle, "subtitle", "", "合成した音声の各セグメントの本文と開始・終了時刻から、SRT形式の字幕ファイルを指定したパスに出力します (例: output.srt、--voicevox と併用)。")
+	rootCmd.PersistentFlags().BoolVar(&opts.SubtitlePrefix, "subtitle-speaker-prefix", false, "--subtitle の各字幕に「ずんだもん: 」のような話者名のプレフィックスを付けます。")
+	rootCmd.PersistentFlags().StringVar(&opts.SpeakerStats, "speaker-stats", "", "合成した音声の実際の尺から、話者別の総発話時間・発話回数・平均発話長を集計したJSONを指定したパスに出力します (例: stats.json、--voicevox と併用)。")
+	rootCmd.PersistentFlags().StringVar(&opts.StatsOutput, "stats-output", "", "生成スクリプトの話者別の発話回数・文字数・セグメント数・再生時間を集計したJSONを指定したパスに出力します (例: stats.json)。音声を合成した場合は実際の音声の長さ、それ以外は文字数からの概算です。")
+	rootCmd.PersistentFlags().StringVar(&opts.RetryQueueDir, "retry-queue-dir", "", "AIによる生成に失敗した入力を保存する再試行キューのディレクトリ。retry-failed コマンドでまとめて再処理できます。")
+	rootCmd.PersistentFlags().StringVar(&opts.ScriptHistoryDir, "script-history-dir", "", "生成したスクリプトを、モデル・プロンプトとともに内容ハッシュで識別するバージョンとして保存するディレクトリ。script-history コマンドで履歴と差分を確認できます。")
+	rootCmd.PersistentFlags().StringVar(&opts.ScriptID, "script-id", "", "--script-history-dir で履歴をまとめる題材のID (英数字と . _ -)。省略時は入力元のURL・ファイルパスから算出します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.StructuredOutput, "structured-output", false, "話者・スタイル・テキストのセグメント配列をJSONの構造化出力で生成させます。非対応のモデルでは従来のテキスト生成にフォールバックします。")
+	rootCmd.PersistentFlags().StringVar(&opts.StrayBrackets, "stray-brackets", script.StrayBracketWarn, "生成スクリプトの本文に残った定義済みタグ以外の括弧表現 ([補足] など) の扱い (warn: 警告のみ, unwrap: 括弧を外して語句を残す, strip: 語句ごと除去する)。")
+	rootCmd.PersistentFlags().BoolVar(&opts.StrictScript, "strict-script", false, "生成スクリプトの検査で未知の話者タグや壊れた括弧など合成できない問題が見つかった場合、警告ではなくエラーにして音声合成の前に中断します。")
+	rootCmd.PersistentFlags().IntVar(&opts.FormatRetries, "format-retries", 2, "生成スクリプトに既知の話者タグ付きのセリフが1件も無い場合に、フォーマットの厳守を指示して再生成する最大回数。0の場合は再生成しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.ForbiddenTopics, "forbidden-topics", "", "禁止トピック (医療アドバイス、投資助言など) とそのキーワードを定義したYAMLのパス。生成スクリプトで検出した場合は理由を警告し、音声合成の前に中断します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.Bookends, "bookends", false, "モードごとの定型の冒頭・締めの挨拶を生成本文の前後に挿入します。AIには本編のみを書かせます。")
+	rootCmd.PersistentFlags().BoolVar(&opts.MarkConclusion, "mark-conclusion", false, "結論 (ネタバレ) にあたるセリフを [結論]...[/結論] で囲ませ、音声合成時に区間を <出力名>.markers.json に記録します。")
+	rootCmd.PersistentFlags().StringVar(&opts.Title, "title", "", "番組タイトルとしてプロンプトに渡す文字列。未指定の場合はフロントマターの title、URL入力では抽出した記事タイトルを使います。")
+	rootCmd.PersistentFlags().StringVar(&opts.Audience, "audience", "", "想定する聴衆。視聴者レベル (beginner, intermediate, expert) または自由記述 (例: \"Go初心者\")。プロンプトに渡し、説明の深さや用語の補足を聴衆に合わせさせます。")
+	rootCmd.PersistentFlags().IntVar(&opts.TargetLength, "target-length", 0, "生成スクリプトのセリフ本文の目安の文字数。0の場合は指定しません。")
+	rootCmd.PersistentFlags().StringVar(&opts.CharacterProfile, "character-profile", "", "話者ごとの一人称・語尾・性格を定義したキャラクター設定 (YAML) のパス。プロンプトに渡し、各話者の口調を設定に従わせます。")
+	rootCmd.PersistentFlags().BoolVar(&opts.EngineStyles, "engine-styles", false, "VOICEVOXエンジンから話者ごとに実在するスタイルを取得してプロンプトに列挙し、AIにそれ以外のスタイルタグを使わせないようにします (VOICEVOX_API_URL が必要)。")
+	rootCmd.PersistentFlags().BoolVar(&opts.CrossModeCheck, "cross-mode-check", false, "同じ入力を他のモード (solo, duet, dialogue, faq) でも生成して主要トピックの網羅性を比較し、一部のモードだけで抜けている要点を警告します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.FactCheck, "fact-check", false, "生成スクリプトの各文の数値・固有名詞を入力テキストと照合し、入力に根拠が見当たらない文を行番号付きで警告します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.GenerateMetadata, "generate-metadata", false, "スクリプトの生成後に、動画・Podcast の説明欄用の説明文 (150文字程度) とハッシュタグを AI に生成させ、出力先の拡張子を .meta.json に置き換えたファイルに JSON で出力します。生成に失敗してもスクリプトの生成は続行します。")
+	rootCmd.PersistentFlags().StringVar(&opts.BalanceChart, "balance-chart", "", "話者別の文字数・セグメント数と前半・後半の登場バランスを棒グラフ (SVG) として指定したパスに出力します (例: balance.svg)。")
+	rootCmd.PersistentFlags().StringVar(&opts.FingerprintDB, "fingerprint-db", "", "合成したWAVの音声指紋を記録するマニフェスト (JSON) のパス。既存の合成結果と照合し、完全一致・近似一致の重複を警告します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.QCReport, "qc-report", false, "合成したWAVのDCオフセット・無音率・クリッピング率・音量を検査し、結果をログに出力します。")
+	rootCmd.PersistentFlags().BoolVar(&opts.QCStrict, "qc-strict", false, "品質検査の基準を満たさない場合にエラー終了します (--qc-report と併用)。")
+	rootCmd.PersistentFlags().StringVar(&opts.LoudnessGate, "loudness-gate", "", "結合した音声の統合ラウドネス (EBU R128) とピークを配信先の基準 (youtube: -14 LUFS, podcast: -16 LUFS, ebu-r128: -23 LUFS) と照合します。")
+	rootCmd.PersistentFlags().StringVar(&opts.LoudnessGateAction, "loudness-gate-action", "warn", "--loudness-gate の基準を外れた場合の動作 (warn: 警告のみ, fix: ゲインを補正して書き込む, fail: 書き込み後にエラー終了する)。")
 }
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/scriptversion"
+)
+
+// scriptHistoryOptions は script-history コマンドのフラグです。
+type scriptHistoryOptions struct {
+	Prompt bool
+}
+
+var scriptHistoryOpts scriptHistoryOptions
+
+// scriptHistoryCmd は --script-history-dir に保存したスクリプトのバージョン履歴と差分を表示するコマンドです。
+var scriptHistoryCmd = &cobra.Command{
+	Use:   "script-history [id] [from] [to]",
+	Short: "--script-history-dir に保存したスクリプトのバージョン履歴と差分を表示します。",
+	Long: `--script-history-dir に保存したスクリプトの履歴を表示します。
+id を省略すると題材の一覧を、id のみを指定するとその題材のバージョン一覧 (モデル・プロンプトと直前のバージョンからの変更行数) を表示します。
+プロンプトが直前のバージョンから変わった場合は、プロンプトのハッシュに * を付けます。
+from を指定すると from から to (省略時は最新) への差分を unified diff 形式で表示します。
+バージョンは番号 (1 が最古、-1 が最新) または内容ハッシュの先頭部分で指定します。`,
+	Args: cobra.MaximumNArgs(3),
+	RunE: scriptHistoryCommand,
+}
+
+func init() {
+	scriptHistoryCmd.Flags().BoolVar(&scriptHistoryOpts.Prompt, "prompt", false, "スクリプトではなく、生成に使ったプロンプト全文の差分を表示します。")
+}
+
+// scriptHistoryCommand は、引数に応じて題材の一覧・バージョンの一覧・バージョン間の差分を表示します。
+func scriptHistoryCommand(cmd *cobra.Command, args []string) error {
+	if opts.ScriptHistoryDir == "" {
+		return fmt.Errorf("--script-history-dir でスクリプト履歴のディレクトリを指定してください")
+	}
+	store := scriptversion.New(opts.ScriptHistoryDir)
+	out := cmd.OutOrStdout()
+
+	if len(args) == 0 {
+		return printSubjects(out, store)
+	}
+	id := args[0]
+	versions, err := store.Versions(id)
+	if err != nil {
+		return err
+	}
+	if len(versions) == 0 {
+		return fmt.Errorf("題材 '%s' のスクリプト履歴がありません", id)
+	}
+	if len(args) == 1 {
+		return printVersions(out, store, id, versions)
+	}
+
+	to := "-1"
+	if len(args) == 3 {
+		to = args[2]
+	}
+	fromNo, fromVersion, err := scriptversion.Resolve(versions, args[1])
+	if err != nil {
+		return err
+	}
+	toNo, toVersion, err := scriptversion.Resolve(versions, to)
+	if err != nil {
+		return err
+	}
+
+	read := store.Script
+	if scriptHistoryOpts.Prompt {
+		read = store.Prompt
+	}
+	a, err := read(id, *fromVersion)
+	if err != nil {
+		return err
+	}
+	b, err := read(id, *toVersion)
+	if err != nil {
+		return err
+	}
+	diff := scriptversion.Diff(
+		fmt.Sprintf("v%d (%s, %s)", fromNo, fromVersion.Hash, fromVersion.CreatedAt.Local().Format("2006-01-02 15:04:05")),
+		fmt.Sprintf("v%d (%s, %s)", toNo, toVersion.Hash, toVersion.CreatedAt.Local().Format("2006-01-02 15:04:05")),
+		a, b)
+	if diff == "" {
+		_, err := fmt.Fprintf(out, "v%d と v%d に差分はありません。\n", fromNo, toNo)
+		return err
+	}
+	_, err = io.WriteString(out, diff)
+	return err
+}
+
+// printSubjects は保存されている題材の一覧を新しい順に表示します。
+func printSubjects(out io.Writer, store *scriptversion.Store) error {
+	subjects, err := store.Subjects()
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tVERSIONS\tUPDATED\tSOURCE")
+	for _, s := range subjects {
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\n", s.ID, s.Versions, s.Updated.Local().Format("2006-01-02 15:04:05"), orDash(s.Source))
+	}
+	return tw.Flush()
+}
+
+// printVersions は題材 id のバージョンを古い順に、直前のバージョンからの変更行数とともに表示します。
+func printVersions(out io.Writer, store *scriptversion.Store, id string, versions []scriptversion.Version) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NO\tHASH\tCREATED\tMODE\tMODEL\tPROMPT\tLINES\tCHANGES")
+	var prev string
+	for i, v := range versions {
+		content, err := store.Script(id, v)
+		if err != nil {
+			return err
+		}
+		changes := "-"
+		if i > 0 {
+			added, removed := scriptversion.DiffStat(prev, content)
+			changes = fmt.Sprintf("+%d/-%d", added, removed)
+		}
+		prompt := v.PromptHash
+		if v.PromptFile != "" {
+			prompt += " (" + v.PromptFile + ")"
+		}
+		if i > 0 && v.PromptHash != versions[i-1].PromptHash {
+			prompt += " *"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			i+1, v.Hash, v.CreatedAt.Local().Format("2006-01-02 15:04:05"), orDash(v.Mode), orDash(v.Model),
+			prompt, strings.Count(strings.TrimSuffix(content, "\n"), "\n")+1, changes)
+		prev = content
+	}
+	return tw.Flush()
+}
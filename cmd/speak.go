@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/builder"
+	"prototypus-ai-doc-go/internal/history"
+	"prototypus-ai-doc-go/internal/profile"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// speakOptions は speak コマンドのフラグです。
+type speakOptions struct {
+	Text    string
+	Speaker string
+	Style   string
+}
+
+var speakOpts speakOptions
+
+// speakCmd は AI によるスクリプト生成を行わず、プレーンテキストをそのまま指定話者で読み上げるコマンドです。
+var speakCmd = &cobra.Command{
+	Use:   "speak",
+	Short: "プレーンテキストをAIを介さずに指定した話者で読み上げ、WAVを出力します。",
+	Long: `--text (省略時は --script-file または標準入力) のテキストを、タグの無いスクリプトとして
+VOICEVOX エンジンで合成し、-o (または --voicevox) で指定したパスに出力します。
+テキスト全体を --speaker / --style の話者で合成します。Gemini API キーは不要です。
+--review-format で出力したレビュー用の表を入力した場合は、コメントを無視して表の話者・スタイルで合成します。`,
+	RunE: speakCommand,
+}
+
+func init() {
+	speakCmd.Flags().StringVar(&speakOpts.Text, "text", "", "読み上げるテキスト。省略時は --script-file (または標準入力) から読み込みます。")
+	speakCmd.Flags().StringVar(&speakOpts.Speaker, "speaker", "ずんだもん", "読み上げる話者 (ずんだもん, めたん)。")
+	speakCmd.Flags().StringVar(&speakOpts.Style, "style", "ノーマル", "読み上げるスタイル (ノーマル, あまあま など)。")
+}
+
+// speakCommand は、テキスト全体に話者・スタイルタグを付けたスクリプトを合成して出力します。
+func speakCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if opts.ProfileStages {
+		var prof *profile.Profile
+		ctx, prof = profile.WithProfile(ctx)
+		defer prof.Log(ctx)
+	}
+
+	outputPath := opts.VoicevoxOutput
+	if outputPath == "" {
+		outputPath = opts.OutputFile
+	}
+	if outputPath == "" {
+		return fmt.Errorf("-o または --voicevox で音声の出力先を指定してください")
+	}
+
+	rec := history.Record{Command: "speak", Input: cmp.Or(opts.ScriptFile, "-"), Output: outputPath}
+	if speakOpts.Text != "" {
+		rec.Input = "--text"
+	}
+	return recordRun(ctx, opts.RecordDB, rec, func(ctx context.Context) error {
+		return speak(ctx, outputPath)
+	})
+}
+
+// speak は、--text または入力ソースのテキストを合成して outputPath に出力します。
+func speak(ctx context.Context, outputPath string) error {
+	appCtx, err := builder.BuildVoiceContainer(ctx, &opts)
+	if err != nil {
+		return fmt.Errorf("アプリケーションの初期化に失敗しました: %w", err)
+	}
+	defer func() {
+		if closeErr := appCtx.Close(); closeErr != nil {
+			slog.WarnContext(ctx, "リソースのクローズに失敗しました", "error", closeErr)
+		}
+	}()
+
+	text := speakOpts.Text
+	if text == "" {
+		rc, err := appCtx.RemoteIO.Reader.Open(ctx, opts.ScriptFile)
+		if err != nil {
+			return fmt.Errorf("入力ソースのオープンに失敗しました (%s): %w", opts.ScriptFile, err)
+		}
+		body, readErr := io.ReadAll(rc)
+		if err := errors.Join(readErr, rc.Close()); err != nil {
+			return fmt.Errorf("入力ソース(%s)の読み込みに失敗しました: %w", opts.ScriptFile, err)
+		}
+		text = string(body)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return fmt.Errorf("読み上げるテキストが空です。--text または --script-file を指定してください")
+	}
+
+	// --review-format で出力したレビュー用の表は、コメント列を除いた台本の話者・スタイルのまま合成する
+	if reviewed, ok := script.ParseReview(text); ok {
+		slog.InfoContext(ctx, "レビュー形式の台本を読み込みました。コメントは無視して合成します。")
+		if err := appCtx.Engine.PostToEngine(ctx, reviewed, outputPath); err != nil {
+			return fmt.Errorf("音声合成に失敗しました: %w", err)
+		}
+		slog.InfoContext(ctx, "音声を出力しました。", "path", outputPath)
+		return nil
+	}
+
+	// 先頭に話者・スタイルタグを付けると、以降のタグの無い行はスクリプト解析のフォールバックにより同じ話者のテキストとして結合される
+	speakerTag := "[" + strings.Trim(speakOpts.Speaker, "[]") + "]"
+	styleTag := "[" + strings.Trim(speakOpts.Style, "[]") + "]"
+	scriptContent := speakerTag + styleTag + " " + text
+
+	if err := appCtx.Engine.PostToEngine(ctx, scriptContent, outputPath); err != nil {
+		return fmt.Errorf("音声合成に失敗しました: %w", err)
+	}
+	slog.InfoContext(ctx, "音声を出力しました。", "path", outputPath, "speaker", speakerTag, "style", styleTag)
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"prototypus-ai-doc-go/internal/builder"
+	"prototypus-ai-doc-go/internal/history"
+	"prototypus-ai-doc-go/internal/profile"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// synthesizeCmd は AI によるスクリプト生成を行わず、既存のスクリプトを VOICEVOX で合成するコマンドです。
+// generate で出力したスクリプトを確認・編集してから音声化する場合に使います。
+var synthesizeCmd = &cobra.Command{
+	Use:   "synthesize",
+	Short: "既存のスクリプトをAIを介さずにVOICEVOXで合成し、音声を出力します。",
+	Long: `--script-file (省略時は標準入力) の [話者][スタイル] 形式のスクリプトを、生成を行わずにそのまま
+VOICEVOX エンジンで合成し、--voicevox (または -o) で指定したパスに出力します。
+generate の音声合成と同じ処理を行うため、字幕やスクリプト統計などの合成時のオプションもそのまま使えます。
+--review-format で出力したレビュー用の表を入力した場合は、コメントを無視して表の話者・スタイルで合成します。
+Gemini API キーは不要です。`,
+	RunE: synthesizeCommand,
+}
+
+// synthesizeCommand は、入力ソースのスクリプトを公開処理 (音声合成) に渡して出力します。
+func synthesizeCommand(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if opts.ProfileStages {
+		var prof *profile.Profile
+		ctx, prof = profile.WithProfile(ctx)
+		defer prof.Log(ctx)
+	}
+
+	if cmd.Flags().Changed("voicevox") && cmd.Flags().Changed("output-file") {
+		return fmt.Errorf("--voicevoxオプションと--output-fileオプションは同時に指定できません")
+	}
+	// 公開処理は --voicevox の指定で音声合成を行うため、-o のみの場合は音声の出力先として扱う
+	opts.VoicevoxOutput = cmp.Or(opts.VoicevoxOutput, opts.OutputFile)
+	if opts.VoicevoxOutput == "" {
+		return fmt.Errorf("--voicevox または -o で音声の出力先を指定してください")
+	}
+
+	rec := history.Record{Command: "synthesize", Input: cmp.Or(opts.ScriptFile, "-"), Output: opts.VoicevoxOutput}
+	return recordRun(ctx, opts.RecordDB, rec, synthesize)
+}
+
+// synthesize は、入力ソースのスクリプトを読み込み、PublishRunner で合成して出力します。
+func synthesize(ctx context.Context) error {
+	appCtx, err := builder.BuildPublishContainer(ctx, &opts)
+	if err != nil {
+		return fmt.Errorf("アプリケーションの初期化に失敗しました: %w", err)
+	}
+	defer func() {
+		if closeErr := appCtx.Close(); closeErr != nil {
+			slog.WarnContext(ctx, "リソースのクローズに失敗しました", "error", closeErr)
+		}
+	}()
+
+	rc, err := appCtx.RemoteIO.Reader.Open(ctx, opts.ScriptFile)
+	if err != nil {
+		return fmt.Errorf("入力ソースのオープンに失敗しました (%s): %w", opts.ScriptFile, err)
+	}
+	body, readErr := io.ReadAll(rc)
+	if err := errors.Join(readErr, rc.Close()); err != nil {
+		return fmt.Errorf("入力ソース(%s)の読み込みに失敗しました: %w", opts.ScriptFile, err)
+	}
+	scriptContent := strings.TrimSpace(string(body))
+	if scriptContent == "" {
+		return fmt.Errorf("合成するスクリプトが空です。--script-file または標準入力でスクリプトを指定してください")
+	}
+
+	if reviewed, ok := script.ParseReview(scriptContent); ok {
+		slog.InfoContext(ctx, "レビュー形式の台本を読み込みました。コメントは無視して合成します。")
+		scriptContent = reviewed
+	}
+
+	return appCtx.Publisher.Run(ctx, scriptContent)
+}
@@ -3,10 +3,12 @@ package adapters
 import (
 	"context"
 	"fmt"
+	"math"
 	"time"
 
-	"github.com/shouni/go-gemini-client/gemini"
+	"google.golang.org/genai"
 
+	"prototypus-ai-doc-go/internal/ai"
 	"prototypus-ai-doc-go/internal/config"
 )
 
@@ -19,9 +21,9 @@ const (
 )
 
 // NewAIAdapter は aiClientを初期化します。
-func NewAIAdapter(ctx context.Context, cfg *config.Config) (gemini.Generator, error) {
-	clientConfig := gemini.Config{
-		InitialDelay: defaultInitialDelay,
+func NewAIAdapter(ctx context.Context, cfg *config.Config) (ai.Generator, error) {
+	clientConfig := ai.Config{
+		RetryDelay: defaultInitialDelay,
 	}
 
 	// GeminiAPIKeyが設定されている場合は優先して使用し、
@@ -35,7 +37,18 @@ func NewAIAdapter(ctx context.Context, cfg *config.Config) (gemini.Generator, er
 		return nil, fmt.Errorf("GEMINI_API_KEY or GCP_PROJECT_ID is not set")
 	}
 
-	aiClient, err := gemini.NewClient(ctx, clientConfig)
+	if cfg.FlagChanged("temperature") {
+		if cfg.Temperature < 0 || cfg.Temperature > 2 {
+			return nil, fmt.Errorf("--temperature には 0〜2 の値を指定してください: %g", cfg.Temperature)
+		}
+		clientConfig.Temperature = genai.Ptr(float32(cfg.Temperature))
+	}
+	if cfg.MaxOutputTokens < 0 || cfg.MaxOutputTokens > math.MaxInt32 {
+		return nil, fmt.Errorf("--max-output-tokens には 0 以上の値を指定してください: %d", cfg.MaxOutputTokens)
+	}
+	clientConfig.MaxOutputTokens = int32(cfg.MaxOutputTokens)
+
+	aiClient, err := ai.NewClient(ctx, clientConfig)
 
 	if err != nil {
 		return nil, fmt.Errorf("AIクライアントの初期化に失敗しました: %w", err)
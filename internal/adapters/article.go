@@ -0,0 +1,94 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/shouni/go-web-exact/v2/extract"
+	"github.com/shouni/go-web-exact/v2/ports"
+
+	"prototypus-ai-doc-go/internal/domain"
+)
+
+// extractedTitlePrefix は go-web-exact が抽出テキストの先頭に付けるページタイトル行のプレフィックスです。
+const extractedTitlePrefix = "【記事タイトル】"
+
+// ArticleAdapter は go-web-exact による本文抽出に、ページタイトルの取得を加えた domain.ArticleExtractor の実装です。
+type ArticleAdapter struct {
+	fetcher ports.Fetcher
+}
+
+// NewArticleAdapter は fetcher で HTML を取得する ArticleAdapter を生成します。
+func NewArticleAdapter(fetcher ports.Fetcher) *ArticleAdapter {
+	return &ArticleAdapter{fetcher: fetcher}
+}
+
+// FetchArticle は URL の HTML を1回だけ取得し、本文とタイトル・og:type を分離して返します。
+func (a *ArticleAdapter) FetchArticle(ctx context.Context, url string) (*domain.Article, error) {
+	html, err := a.fetcher.FetchBytes(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	// 取得済みの HTML を渡して本文抽出を行い、同じページを二重に取得しないようにする
+	extractor, err := extract.NewExtractor(fetchedHTML(html))
+	if err != nil {
+		return nil, fmt.Errorf("エクストラクタの初期化に失敗しました: %w", err)
+	}
+	text, hasBody, err := extractor.FetchAndExtractText(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("HTML解析に失敗しました: %w", err)
+	}
+	article := &domain.Article{
+		Title:   pageTitle(doc),
+		Type:    pageType(doc),
+		Text:    stripTitleLine(text),
+		HasBody: hasBody,
+	}
+	if !hasBody {
+		article.Text = ""
+	}
+	return article, nil
+}
+
+// pageTitle は og:title を優先してページのタイトルを返します。どちらも無い場合は空文字を返します。
+func pageTitle(doc *goquery.Document) string {
+	if og, ok := doc.Find(`meta[property="og:title"]`).First().Attr("content"); ok {
+		if og = strings.TrimSpace(og); og != "" {
+			return og
+		}
+	}
+	return strings.TrimSpace(doc.Find("title").First().Text())
+}
+
+// pageType はページの og:type を小文字で返します。無い場合は空文字を返します。
+func pageType(doc *goquery.Document) string {
+	og, _ := doc.Find(`meta[property="og:type"]`).First().Attr("content")
+	return strings.ToLower(strings.TrimSpace(og))
+}
+
+// stripTitleLine は抽出テキストの先頭のページタイトル行を取り除きます。
+func stripTitleLine(text string) string {
+	text = strings.TrimSpace(text)
+	firstLine, rest, _ := strings.Cut(text, "\n")
+	if !strings.HasPrefix(firstLine, extractedTitlePrefix) {
+		return text
+	}
+	return strings.TrimSpace(rest)
+}
+
+// fetchedHTML は取得済みの HTML をそのまま返す ports.Fetcher です。
+type fetchedHTML []byte
+
+// FetchBytes は取得済みの HTML を返します。
+func (f fetchedHTML) FetchBytes(context.Context, string) ([]byte, error) {
+	return f, nil
+}
@@ -0,0 +1,150 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// AuditRecord は監査ログに記録する、エンジンへの1件のリクエストです。
+type AuditRecord struct {
+	Time          time.Time `json:"time"`
+	Method        string    `json:"method"`
+	URL           string    `json:"url"`
+	StyleID       *int      `json:"style_id,omitempty"`
+	TextLength    int       `json:"text_length,omitempty"`
+	Text          string    `json:"text,omitempty"`
+	RequestBytes  int64     `json:"request_bytes"`
+	Status        int       `json:"status,omitempty"`
+	ResponseBytes int64     `json:"response_bytes"`
+	DurationMS    int64     `json:"duration_ms"`
+	Error         string    `json:"error,omitempty"`
+}
+
+// AuditDoer はエンジンへの HTTP リクエストごとに AuditRecord を JSONL で監査ログに追記する voicevox.Doer のデコレータです。
+// 記録はレスポンスボディのクローズ時に行うため、所要時間とレスポンスサイズにはボディの読み込みが含まれます。
+type AuditDoer struct {
+	voicevox.Doer
+	path        string
+	includeText bool
+
+	mu sync.Mutex
+}
+
+// NewAuditDoer は path のローカルファイルに監査ログを追記する AuditDoer を生成します。
+// includeText が false の場合、機密情報を残さないよう合成テキストの本文は記録せず、文字数のみを記録します。
+func NewAuditDoer(d voicevox.Doer, path string, includeText bool) *AuditDoer {
+	return &AuditDoer{
+		Doer:        d,
+		path:        path,
+		includeText: includeText,
+	}
+}
+
+// Do はリクエストを内側の Doer に送信し、レスポンスボディのクローズ時に監査ログを記録します。
+func (d *AuditDoer) Do(req *http.Request) (*http.Response, error) {
+	record := d.newRecord(req)
+	start := time.Now()
+
+	resp, err := d.Doer.Do(req)
+	if err != nil {
+		record.DurationMS = time.Since(start).Milliseconds()
+		record.Error = err.Error()
+		d.append(record)
+		return nil, err
+	}
+
+	record.Status = resp.StatusCode
+	resp.Body = &auditBody{
+		ReadCloser: resp.Body,
+		onClose: func(n int64) {
+			record.ResponseBytes = n
+			record.DurationMS = time.Since(start).Milliseconds()
+			d.append(record)
+		},
+	}
+	return resp, nil
+}
+
+// newRecord はリクエストの URL から StyleID と合成テキストを取り出して AuditRecord を組み立てます。
+// 合成テキストはクエリパラメータに含まれるため、記録する URL からは常に取り除きます。
+func (d *AuditDoer) newRecord(req *http.Request) AuditRecord {
+	u := *req.URL
+	query := u.Query()
+	text := query.Get("text")
+	query.Del("text")
+	u.RawQuery = query.Encode()
+
+	record := AuditRecord{
+		Time:         time.Now(),
+		Method:       req.Method,
+		URL:          u.String(),
+		TextLength:   len([]rune(text)),
+		RequestBytes: max(req.ContentLength, 0),
+	}
+	if id, err := strconv.Atoi(query.Get("speaker")); err == nil {
+		record.StyleID = &id
+	}
+	if d.includeText {
+		record.Text = text
+	}
+	return record
+}
+
+// append は監査ログに1行追記します。記録の失敗は合成を止めないよう、ログ出力のみに留めます。
+func (d *AuditDoer) append(record AuditRecord) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		slog.Warn("監査ログのエンコードに失敗しました。", "error", err)
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := appendLine(d.path, line); err != nil {
+		slog.Warn("監査ログの書き込みに失敗しました。", "path", d.path, "error", err)
+	}
+}
+
+// appendLine はファイルに1行を追記します。ファイルが存在しない場合は作成します。
+func appendLine(path string, line []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("ファイルを開けませんでした: %w", err)
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	if closeErr := f.Close(); writeErr == nil {
+		writeErr = closeErr
+	}
+	return writeErr
+}
+
+// auditBody は読み込んだバイト数を数え、最初のクローズ時に onClose を呼び出すレスポンスボディです。
+type auditBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+// Read は io.Reader を実装します。
+func (b *auditBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// Close は io.Closer を実装します。
+func (b *auditBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.onClose(b.n) })
+	return err
+}
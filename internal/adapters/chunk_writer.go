@@ -0,0 +1,226 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-remote-io/remoteio"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+const (
+	// chunkUploadAttempts はチャンク1つあたりのアップロード試行回数です。
+	chunkUploadAttempts = 3
+	// chunkRetryDelay はチャンクアップロードの再試行までの待機時間です。
+	chunkRetryDelay = 2 * time.Second
+	// manifestSuffix はチャンクマニフェストのファイル名に付与する接尾辞です。
+	manifestSuffix = ".manifest.json"
+)
+
+// ChunkManifest は分割アップロードしたチャンクの一覧と状態を記録するマニフェストです。
+type ChunkManifest struct {
+	Source        string       `json:"source"`
+	SampleRate    uint32       `json:"sample_rate"`
+	Channels      uint16       `json:"channels"`
+	BitsPerSample uint16       `json:"bits_per_sample"`
+	TotalDuration float64      `json:"total_duration_sec"`
+	Chunks        []ChunkEntry `json:"chunks"`
+}
+
+// ChunkEntry はマニフェスト内の1チャンク分の情報です。
+type ChunkEntry struct {
+	Index    int     `json:"index"`
+	Path     string  `json:"path"`
+	StartSec float64 `json:"start_sec"`
+	Duration float64 `json:"duration_sec"`
+	Size     int     `json:"size"`
+	SHA256   string  `json:"sha256"`
+	Uploaded bool    `json:"uploaded"`
+}
+
+// ChunkedWriter は結合済みWAVを一定尺のチャンクに分割し、個別オブジェクトとして逐次アップロードする
+// remoteio.OutputWriter のデコレータです。最後にマニフェストを書き込みます。
+// 既存のマニフェストがあれば、同一内容でアップロード済みのチャンクは再送しません。
+type ChunkedWriter struct {
+	remoteio.OutputWriter
+	reader        remoteio.InputReader
+	chunkDuration time.Duration
+}
+
+// NewChunkedWriter は ChunkedWriter を生成します。reader は既存マニフェストの読み込みに使用します。
+func NewChunkedWriter(w remoteio.OutputWriter, reader remoteio.InputReader, chunkDuration time.Duration) *ChunkedWriter {
+	return &ChunkedWriter{
+		OutputWriter:  w,
+		reader:        reader,
+		chunkDuration: chunkDuration,
+	}
+}
+
+// Write は WAV をチャンクに分割してアップロードします。WAV 以外の書き込みはそのまま委譲します。
+func (w *ChunkedWriter) Write(ctx context.Context, path string, r io.Reader, contentType string) error {
+	if !strings.EqualFold(filepath.Ext(path), ".wav") {
+		return w.OutputWriter.Write(ctx, path, r, contentType)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("チャンク分割のための音声データ読み込みに失敗しました: %w", err)
+	}
+	wav, err := audio.ParseWAV(data)
+	if err != nil {
+		return fmt.Errorf("チャンク分割のためのWAV解析に失敗しました (%s): %w", path, err)
+	}
+
+	framesPerChunk := int(w.chunkDuration.Seconds() * float64(wav.Format.SampleRate))
+	chunks := wav.Split(framesPerChunk)
+	if len(chunks) == 0 {
+		return w.OutputWriter.Write(ctx, path, bytes.NewReader(data), contentType)
+	}
+
+	manifestPath := chunkBasePath(path) + manifestSuffix
+	previous := w.loadUploadedChunks(ctx, manifestPath)
+
+	manifest := ChunkManifest{
+		Source:        path,
+		SampleRate:    wav.Format.SampleRate,
+		Channels:      wav.Format.Channels,
+		BitsPerSample: wav.Format.BitsPerSample,
+		TotalDuration: wav.Duration(),
+	}
+	encodedChunks := make([][]byte, len(chunks))
+	var start float64
+	for i, chunk := range chunks {
+		encoded := chunk.Bytes()
+		encodedChunks[i] = encoded
+		sum := sha256.Sum256(encoded)
+		manifest.Chunks = append(manifest.Chunks, ChunkEntry{
+			Index:    i + 1,
+			Path:     chunkPath(path, i+1),
+			StartSec: start,
+			Duration: chunk.Duration(),
+			Size:     len(encoded),
+			SHA256:   hex.EncodeToString(sum[:]),
+		})
+		start += chunk.Duration()
+	}
+
+	slog.InfoContext(ctx, "音声をチャンクに分割してアップロードします。", "output_path", path, "chunks", len(chunks), "chunk_duration", w.chunkDuration.String())
+	for i := range manifest.Chunks {
+		entry := &manifest.Chunks[i]
+		if previous[entry.Path] == entry.SHA256 {
+			entry.Uploaded = true
+			slog.InfoContext(ctx, "アップロード済みのチャンクをスキップします。", "chunk_path", entry.Path)
+			continue
+		}
+
+		if err := w.uploadChunk(ctx, entry.Path, encodedChunks[i], contentType); err != nil {
+			// 再実行時に未アップロード分のみ再送できるよう、途中までの状態をマニフェストに残す
+			if mErr := w.writeManifest(ctx, manifestPath, &manifest); mErr != nil {
+				err = errors.Join(err, mErr)
+			}
+			return fmt.Errorf("チャンク %d/%d のアップロードに失敗しました: %w", entry.Index, len(manifest.Chunks), err)
+		}
+		entry.Uploaded = true
+		slog.InfoContext(ctx, "チャンクをアップロードしました。", "chunk_path", entry.Path, "index", entry.Index, "total", len(manifest.Chunks))
+	}
+
+	if err := w.writeManifest(ctx, manifestPath, &manifest); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "チャンクマニフェストを書き込みました。", "manifest_path", manifestPath)
+	return nil
+}
+
+// uploadChunk は1チャンクを書き込みます。失敗した場合は一定回数まで再試行します。
+func (w *ChunkedWriter) uploadChunk(ctx context.Context, path string, data []byte, contentType string) error {
+	var err error
+	for attempt := 1; attempt <= chunkUploadAttempts; attempt++ {
+		if err = w.OutputWriter.Write(ctx, path, bytes.NewReader(data), contentType); err == nil {
+			return nil
+		}
+		if attempt == chunkUploadAttempts {
+			break
+		}
+		slog.WarnContext(ctx, "チャンクのアップロードに失敗したため再試行します。", "chunk_path", path, "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(chunkRetryDelay):
+		}
+	}
+	return err
+}
+
+// loadUploadedChunks は既存マニフェストからアップロード済みチャンクのパスとハッシュの対応を読み込みます。
+// マニフェストが存在しない、または読めない場合は空のマップを返します。
+func (w *ChunkedWriter) loadUploadedChunks(ctx context.Context, manifestPath string) map[string]string {
+	uploaded := make(map[string]string)
+	if w.reader == nil {
+		return uploaded
+	}
+
+	rc, err := w.reader.Open(ctx, manifestPath)
+	if err != nil {
+		return uploaded
+	}
+	defer rc.Close()
+
+	var manifest ChunkManifest
+	if err := json.NewDecoder(rc).Decode(&manifest); err != nil {
+		slog.WarnContext(ctx, "既存のチャンクマニフェストを解析できないため、全チャンクをアップロードします。", "manifest_path", manifestPath, "error", err)
+		return uploaded
+	}
+	for _, c := range manifest.Chunks {
+		if c.Uploaded {
+			uploaded[c.Path] = c.SHA256
+		}
+	}
+	return uploaded
+}
+
+// writeManifest はマニフェストをJSONとして書き込みます。
+func (w *ChunkedWriter) writeManifest(ctx context.Context, manifestPath string, manifest *ChunkManifest) error {
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("チャンクマニフェストのエンコードに失敗しました: %w", err)
+	}
+	if err := w.OutputWriter.Write(ctx, manifestPath, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("チャンクマニフェストの書き込みに失敗しました (%s): %w", manifestPath, err)
+	}
+	return nil
+}
+
+// chunkBasePath は出力パスから拡張子を除いた部分を返します。
+func chunkBasePath(path string) string {
+	return strings.TrimSuffix(path, filepath.Ext(path))
+}
+
+// chunkPath は index 番目のチャンクの出力パスを返します (例: audio_part001.wav)。
+func chunkPath(path string, index int) string {
+	return fmt.Sprintf("%s_part%03d%s", chunkBasePath(path), index, filepath.Ext(path))
+}
+
+// BufferingOption は WAV の書き込みで内容全体をメモリ上に読み込むため、--chunk-duration を返します。WAV 以外は内側の Writer に従います。
+func (w *ChunkedWriter) BufferingOption(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		return "--chunk-duration"
+	}
+	return voicevox.WriterBufferingOption(w.OutputWriter, path)
+}
+
+// Unwrap は内側の Writer を返します。セグメントの個別WAVはデコレータを通さずに書き込みます。
+func (w *ChunkedWriter) Unwrap() voicevox.AudioWriter {
+	return w.OutputWriter
+}
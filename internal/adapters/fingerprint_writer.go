@@ -0,0 +1,146 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/shouni/go-remote-io/remoteio"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// nearDuplicateSimilarity は近似一致とみなす音声指紋の類似度の下限です。
+const nearDuplicateSimilarity = 0.9
+
+// FingerprintEntry は音声指紋マニフェストに記録する1件の合成結果です。
+type FingerprintEntry struct {
+	Path        string            `json:"path"`
+	Fingerprint audio.Fingerprint `json:"fingerprint"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+// FingerprintWriter は WAV の書き込みを横取りして音声指紋を生成し、マニフェストの既存の合成結果と照合する
+// remoteio.OutputWriter のデコレータです。重複は警告として報告し、書き込み自体は止めません。
+type FingerprintWriter struct {
+	remoteio.OutputWriter
+	manifestPath string
+}
+
+// NewFingerprintWriter は manifestPath のローカルファイルを音声指紋マニフェストとする FingerprintWriter を生成します。
+func NewFingerprintWriter(w remoteio.OutputWriter, manifestPath string) *FingerprintWriter {
+	return &FingerprintWriter{
+		OutputWriter: w,
+		manifestPath: manifestPath,
+	}
+}
+
+// Write は WAV を内側の Writer に書き込んだ後、音声指紋を照合してマニフェストに記録します。
+func (w *FingerprintWriter) Write(ctx context.Context, path string, r io.Reader, contentType string) error {
+	if !strings.EqualFold(filepath.Ext(path), ".wav") {
+		return w.OutputWriter.Write(ctx, path, r, contentType)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("音声指紋のための音声データ読み込みに失敗しました: %w", err)
+	}
+	if err := w.OutputWriter.Write(ctx, path, bytes.NewReader(data), contentType); err != nil {
+		return err
+	}
+
+	wav, err := audio.ParseWAV(data)
+	if err != nil {
+		slog.WarnContext(ctx, "WAVの解析に失敗したため音声指紋の生成をスキップします。", "path", path, "error", err)
+		return nil
+	}
+	fp := audio.NewFingerprint(wav)
+
+	entries, err := w.load()
+	if err != nil {
+		slog.WarnContext(ctx, "音声指紋マニフェストを読み込めないため照合をスキップします。", "path", w.manifestPath, "error", err)
+		return nil
+	}
+	for _, entry := range entries {
+		if entry.Path == path {
+			continue
+		}
+		similarity := fp.Similarity(entry.Fingerprint)
+		switch {
+		case similarity == 1:
+			slog.WarnContext(ctx, "同じ内容の音声が既に合成されています。", "path", path, "duplicate_of", entry.Path)
+		case similarity >= nearDuplicateSimilarity:
+			slog.WarnContext(ctx, "類似した内容の音声が既に合成されています。", "path", path, "similar_to", entry.Path, "similarity", similarity)
+		}
+	}
+
+	entries = append(removeFingerprintEntry(entries, path), FingerprintEntry{Path: path, Fingerprint: fp, CreatedAt: time.Now()})
+	if err := w.save(entries); err != nil {
+		slog.WarnContext(ctx, "音声指紋マニフェストの保存に失敗しました。", "path", w.manifestPath, "error", err)
+	}
+	return nil
+}
+
+// load は音声指紋マニフェストを読み込みます。存在しない場合は空を返します。
+func (w *FingerprintWriter) load() ([]FingerprintEntry, error) {
+	body, err := os.ReadFile(w.manifestPath)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var entries []FingerprintEntry
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return nil, fmt.Errorf("音声指紋マニフェストの解析に失敗しました: %w", err)
+	}
+	return entries, nil
+}
+
+// save は音声指紋マニフェストを書き込みます。
+func (w *FingerprintWriter) save(entries []FingerprintEntry) error {
+	body, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(w.manifestPath); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(w.manifestPath, body, 0o644)
+}
+
+// removeFingerprintEntry は同じ出力先の古い記録を取り除きます。再合成した場合は新しい指紋で置き換えます。
+func removeFingerprintEntry(entries []FingerprintEntry, path string) []FingerprintEntry {
+	out := entries[:0]
+	for _, e := range entries {
+		if e.Path != path {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// BufferingOption は WAV の書き込みで内容全体をメモリ上に読み込むため、--fingerprint-db を返します。WAV 以外は内側の Writer に従います。
+func (w *FingerprintWriter) BufferingOption(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		return "--fingerprint-db"
+	}
+	return voicevox.WriterBufferingOption(w.OutputWriter, path)
+}
+
+// Unwrap は内側の Writer を返します。セグメントの個別WAVはデコレータを通さずに書き込みます。
+func (w *FingerprintWriter) Unwrap() voicevox.AudioWriter {
+	return w.OutputWriter
+}
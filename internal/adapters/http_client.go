@@ -0,0 +1,118 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/shouni/go-http-kit/httpkit"
+
+	"prototypus-ai-doc-go/internal/config"
+)
+
+// NewHTTPClient は、--http-timeout・--http-max-retries・--http-retry-interval に従って Web ページの取得に使う httpkit.Requester を生成します。
+// リトライの間隔は --http-retry-interval から失敗ごとに倍に延ばし、config.DefaultHTTPMaxRetryInterval (それより長い場合は --http-retry-interval) で頭打ちにします。
+func NewHTTPClient(cfg *config.Config) (httpkit.Requester, error) {
+	if cfg.HTTPMaxRetries < 0 {
+		return nil, fmt.Errorf("--http-max-retries には 0 以上を指定してください: %d", cfg.HTTPMaxRetries)
+	}
+	if cfg.HTTPRetryInterval < 0 {
+		return nil, fmt.Errorf("--http-retry-interval には 0 以上を指定してください: %s", cfg.HTTPRetryInterval)
+	}
+
+	timeout := cfg.HTTPTimeout
+	if timeout == 0 {
+		timeout = config.DefaultHTTPTimeout
+	}
+	interval := cfg.HTTPRetryInterval
+	if interval == 0 {
+		interval = config.DefaultHTTPRetryInterval
+	}
+
+	client := httpkit.New(
+		timeout,
+		httpkit.WithMaxRetries(uint64(cfg.HTTPMaxRetries)),
+		httpkit.WithInitialInterval(interval),
+		httpkit.WithMaxInterval(max(interval, config.DefaultHTTPMaxRetryInterval)),
+		httpkit.WithSkipNetworkValidation(true),
+	)
+	if cfg.HTTPMaxRetries == 0 {
+		// httpkit はリトライ回数の 0 を既定の回数とみなすため、リトライしないクライアントで包む
+		return &noRetryClient{client: client}, nil
+	}
+	return client, nil
+}
+
+// noRetryClient はリトライせずに1回だけリクエストする httpkit.Requester です (--http-max-retries 0)。
+// リクエストの送信は httpkit.Client.Do に、レスポンスの検査は httpkit.HandleResponse に任せ、リトライする場合と同じエラーを返します。
+type noRetryClient struct {
+	client *httpkit.Client
+}
+
+// DoRequest は req を1回だけ送信し、レスポンスボディを返します。
+func (c *noRetryClient) DoRequest(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTPリクエストの送信に失敗しました (%s %s): %w", req.Method, req.URL, err)
+	}
+	return httpkit.HandleResponse(resp)
+}
+
+// FetchBytes は url を GET し、レスポンスボディを返します。
+func (c *noRetryClient) FetchBytes(ctx context.Context, url string) ([]byte, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, url, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	return c.DoRequest(req)
+}
+
+// FetchAndDecodeJSON は url を GET し、レスポンスボディの JSON を v にデコードします。
+func (c *noRetryClient) FetchAndDecodeJSON(ctx context.Context, url string, v any) error {
+	body, err := c.FetchBytes(ctx, url)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("レスポンスのJSONデコードに失敗しました (%s): %w", url, err)
+	}
+	return nil
+}
+
+// PostJSONAndFetchBytes は data を JSON として url に POST し、レスポンスボディを返します。
+func (c *noRetryClient) PostJSONAndFetchBytes(ctx context.Context, url string, data any) ([]byte, error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストのJSONエンコードに失敗しました: %w", err)
+	}
+	return c.PostRawBodyAndFetchBytes(ctx, url, body, "application/json")
+}
+
+// PostRawBodyAndFetchBytes は body を url に POST し、レスポンスボディを返します。
+func (c *noRetryClient) PostRawBodyAndFetchBytes(ctx context.Context, url string, body []byte, contentType string) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := c.newRequest(ctx, http.MethodPost, url, reader, contentType)
+	if err != nil {
+		return nil, err
+	}
+	return c.DoRequest(req)
+}
+
+// newRequest は httpkit.Client と同じ User-Agent を付けたリクエストを作成します。
+func (c *noRetryClient) newRequest(ctx context.Context, method, url string, body io.Reader, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("HTTPリクエストの作成に失敗しました (%s %s): %w", method, url, err)
+	}
+	req.Header.Set("User-Agent", httpkit.UserAgent)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return req, nil
+}
@@ -0,0 +1,29 @@
+package adapters
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// NewProgressPrinter は合成の進捗を「12/30 セグメント完了」の形式で w に表示する voicevox.EngineConfig.Progress を返します。
+// w が端末の場合は同じ行を上書きし、完了時に改行します。端末以外 (ファイルやパイプ) の場合は1件ごとに1行を出力します。
+func NewProgressPrinter(w io.Writer) func(completed, total int) {
+	terminal := false
+	if f, ok := w.(*os.File); ok {
+		if info, err := f.Stat(); err == nil {
+			terminal = info.Mode()&os.ModeCharDevice != 0
+		}
+	}
+
+	return func(completed, total int) {
+		if !terminal {
+			fmt.Fprintf(w, "%d/%d セグメント完了\n", completed, total)
+			return
+		}
+		fmt.Fprintf(w, "\r%d/%d セグメント完了", completed, total)
+		if completed == total {
+			fmt.Fprintln(w)
+		}
+	}
+}
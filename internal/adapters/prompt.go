@@ -1,16 +1,53 @@
 package adapters
 
 import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"strings"
+
 	"github.com/shouni/go-prompt-kit/prompts"
 
 	"prototypus-ai-doc-go/assets"
+	"prototypus-ai-doc-go/internal/config"
 )
 
+// reInputTextAction はテンプレート内で入力本文 ({{.InputText}}) を参照するアクションを検出します。
+var reInputTextAction = regexp.MustCompile(`\{\{-?\s*\.InputText\s*-?\}\}`)
+
 // NewPromptAdapter は動的に読み込んだテンプレートを使用して Builder を構築します。
-func NewPromptAdapter() (*prompts.Builder, error) {
+// --prompt-file が指定された場合は、そのファイルの内容で埋め込みの各モードのテンプレートを上書きします。
+func NewPromptAdapter(cfg *config.Config) (*prompts.Builder, error) {
 	templates, err := assets.LoadPrompts()
 	if err != nil {
 		return nil, err
 	}
+	if cfg.PromptFile != "" {
+		custom, err := loadPromptFile(cfg.PromptFile)
+		if err != nil {
+			return nil, err
+		}
+		for mode := range templates {
+			templates[mode] = custom
+		}
+		slog.Info("独自のプロンプトテンプレートを使用します。", "path", cfg.PromptFile, "mode", cfg.Mode)
+	}
 	return prompts.NewBuilder(templates)
 }
+
+// loadPromptFile は独自のプロンプトテンプレートを読み込みます。入力本文を参照していない場合は警告します。
+func loadPromptFile(path string) (string, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("プロンプトファイルの読み込みに失敗しました (%s): %w", path, err)
+	}
+	content := string(body)
+	if strings.TrimSpace(content) == "" {
+		return "", fmt.Errorf("プロンプトファイルが空です: %s", path)
+	}
+	if !reInputTextAction.MatchString(content) {
+		slog.Warn("プロンプトファイルが {{.InputText}} を参照していないため、入力本文がAIに渡されません。", "path", path)
+	}
+	return content, nil
+}
@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"github.com/shouni/go-remote-io/remoteio"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// QCWriter は WAV の書き込みを横取りして品質検査を行う remoteio.OutputWriter のデコレータです。
+// WAV 以外の書き込みはそのまま内側の Writer に委譲します。
+type QCWriter struct {
+	remoteio.OutputWriter
+	thresholds audio.QCThresholds
+	strict     bool
+}
+
+// NewQCWriter は既定の合否基準で QCWriter を生成します。
+// strict が true の場合、基準を満たさない WAV を書き込んだ後にエラーを返します。
+func NewQCWriter(w remoteio.OutputWriter, strict bool) *QCWriter {
+	return &QCWriter{
+		OutputWriter: w,
+		thresholds:   audio.DefaultQCThresholds(),
+		strict:       strict,
+	}
+}
+
+// Write は WAV を内側の Writer に書き込んだ後、品質検査の結果をログに出力します。
+func (w *QCWriter) Write(ctx context.Context, path string, r io.Reader, contentType string) error {
+	if !strings.EqualFold(filepath.Ext(path), ".wav") {
+		return w.OutputWriter.Write(ctx, path, r, contentType)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("品質検査のための音声データ読み込みに失敗しました: %w", err)
+	}
+	if err := w.OutputWriter.Write(ctx, path, bytes.NewReader(data), contentType); err != nil {
+		return err
+	}
+
+	wav, err := audio.ParseWAV(data)
+	if err != nil {
+		slog.WarnContext(ctx, "WAVの解析に失敗したため品質検査をスキップします。", "path", path, "error", err)
+		return nil
+	}
+
+	report := audio.Inspect(wav, w.thresholds)
+	attrs := []any{
+		"path", path,
+		"duration_sec", report.Duration,
+		"dc_offset", report.DCOffset,
+		"silence_ratio", report.SilenceRatio,
+		"clipping_ratio", report.ClippingRatio,
+		"rms_level_db", report.RMSLevelDB,
+	}
+	if report.Passed() {
+		slog.InfoContext(ctx, "音声の品質検査に合格しました。", attrs...)
+		return nil
+	}
+
+	slog.WarnContext(ctx, "音声の品質検査で問題が見つかりました。", append(attrs, "problems", report.Problems)...)
+	if w.strict {
+		return fmt.Errorf("音声の品質検査に不合格です (%s): %s", path, strings.Join(report.Problems, ", "))
+	}
+	return nil
+}
+
+// BufferingOption は WAV の書き込みで内容全体をメモリ上に読み込むため、--qc-report を返します。WAV 以外は内側の Writer に従います。
+func (w *QCWriter) BufferingOption(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".wav") {
+		return "--qc-report"
+	}
+	return voicevox.WriterBufferingOption(w.OutputWriter, path)
+}
+
+// Unwrap は内側の Writer を返します。セグメントの個別WAVはデコレータを通さずに書き込みます。
+func (w *QCWriter) Unwrap() voicevox.AudioWriter {
+	return w.OutputWriter
+}
@@ -1,25 +1,234 @@
 package adapters
 
 import (
+	"cmp"
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
 
-	"github.com/shouni/go-http-kit/httpkit"
-	"github.com/shouni/go-remote-io/remoteio"
-	"github.com/shouni/go-voicevox/voicevox"
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // NewVoiceAdapter は、voicevox Executorを初期化します。
-func NewVoiceAdapter(ctx context.Context, httpClient httpkit.Requester, writer remoteio.OutputWriter, voicevoxOutput string) (voicevox.EngineExecutor, error) {
-	if voicevoxOutput == "" {
+func NewVoiceAdapter(ctx context.Context, cfg *config.Config, writer voicevox.AudioWriter) (voicevox.EngineExecutor, error) {
+	if cfg.VoicevoxOutput == "" {
 		slog.Info("voicevoxの出力先が未指定のため、エンジンエクゼキュータをスキップします。")
 		return nil, nil
 	}
+	return NewVoiceEngine(cfg, writer)
+}
+
+// NewVoiceEngine は、設定に従って VOICEVOX エンジンに接続する voicevox.Engine を生成します。
+func NewVoiceEngine(cfg *config.Config, writer voicevox.AudioWriter) (*voicevox.Engine, error) {
+	client, err := NewVoiceClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ForeignWords != "" && !slices.Contains(script.ForeignWordModes, cfg.ForeignWords) {
+		return nil, fmt.Errorf("--foreign-word-handling には %s のいずれかを指定してください: '%s'", strings.Join(script.ForeignWordModes, ", "), cfg.ForeignWords)
+	}
+
+	if cfg.OutputSampleRate != 0 && (cfg.OutputSampleRate < voicevox.MinOutputSampleRate || cfg.OutputSampleRate > voicevox.MaxOutputSampleRate) {
+		return nil, fmt.Errorf("--output-sample-rate には %d〜%d を指定してください: %d", voicevox.MinOutputSampleRate, voicevox.MaxOutputSampleRate, cfg.OutputSampleRate)
+	}
+
+	if cfg.SegmentsOnly && cfg.SegmentDir == "" {
+		return nil, fmt.Errorf("--segments-only は --segment-dir と併用してください")
+	}
+
+	if cfg.NormalizeSilence > 0 && cfg.AutoChapterSilence > cfg.NormalizeSilence {
+		return nil, fmt.Errorf("--normalize-silence で無音を %s に詰めると、--auto-chapter-silence (%s) 以上の無音が無くなるため、チャプターを検出できません", cfg.NormalizeSilence, cfg.AutoChapterSilence)
+	}
+
+	if cfg.FailFastRate < 0 || cfg.FailFastRate > 1 {
+		return nil, fmt.Errorf("--fail-fast-rate には 0〜1 の割合を指定してください: %g", cfg.FailFastRate)
+	}
+	if cfg.FailFastRate > 0 && !cfg.AllowPartial {
+		return nil, fmt.Errorf("--fail-fast-rate は --allow-partial と併用してください。--allow-partial を指定しない場合は最初の失敗で中断します")
+	}
 
-	executor, err := voicevox.NewEngineExecutor(ctx, httpClient, writer, true)
+	maxMemory, err := voicevox.ParseByteSize(cfg.MaxMemory)
+	if err != nil {
+		return nil, fmt.Errorf("--max-memory の指定が不正です: %w", err)
+	}
+	maxTempDisk, err := voicevox.ParseByteSize(cfg.MaxTempDisk)
 	if err != nil {
-		return nil, fmt.Errorf("voicevoxエンジンエクゼキュータの初期化に失敗しました: %w", err)
+		return nil, fmt.Errorf("--max-temp-disk の指定が不正です: %w", err)
+	}
+	if maxMemory > 0 {
+		// 上限に近づくと GC の頻度を上げて、合成中のピークメモリを抑える
+		debug.SetMemoryLimit(maxMemory)
+	}
+
+	priority, err := voicevox.ParsePriority(cfg.Priority)
+	if err != nil {
+		return nil, err
+	}
+	automations, err := voicevox.ParseAutomations(cfg.Automate)
+	if err != nil {
+		return nil, err
+	}
+	var gate *voicevox.PriorityGate
+	if dir, err := voicevox.DefaultPriorityDir(cfg.VoicevoxAPIURL); err == nil {
+		gate = voicevox.NewPriorityGate(dir, priority)
+	} else {
+		slog.Warn("ジョブの優先度を調停せずに起動します。", "error", err)
+	}
+
+	var progress func(completed, total int)
+	if cfg.Progress {
+		progress = NewProgressPrinter(os.Stderr)
 	}
-	return executor, nil
+
+	var loudness voicevox.LoudnessGate
+	if cfg.LoudnessGate != "" {
+		preset, err := audio.LookupLoudnessPreset(cfg.LoudnessGate)
+		if err != nil {
+			return nil, err
+		}
+		action := cmp.Or(cfg.LoudnessGateAction, voicevox.LoudnessActionWarn)
+		if !slices.Contains(voicevox.LoudnessActions, action) {
+			return nil, fmt.Errorf("--loudness-gate-action には %s のいずれかを指定してください: '%s'", strings.Join(voicevox.LoudnessActions, ", "), action)
+		}
+		loudness = voicevox.LoudnessGate{Preset: preset, Action: action}
+	}
+
+	var marker voicevox.SegmentMarker
+	if cfg.SegmentMarker {
+		marker = voicevox.SegmentMarker{Frequency: cfg.SegmentMarkerFreq, Duration: cfg.SegmentMarkerLen}
+	}
+
+	return voicevox.NewEngine(client, writer, voicevox.EngineConfig{
+		ScheduleByStyle:        cfg.ScheduleByStyle,
+		MergeSegments:          cfg.MergeSegments,
+		SegmentDir:             cfg.SegmentDir,
+		SegmentsOnly:           cfg.SegmentsOnly,
+		AutoChapterSilence:     cfg.AutoChapterSilence,
+		ABPointsPath:           cfg.ABPoints,
+		SubtitlePath:           cfg.Subtitle,
+		SubtitleSpeakerPrefix:  cfg.SubtitlePrefix,
+		SpeakerStatsPath:       cfg.SpeakerStats,
+		StatsPath:              cfg.StatsOutput,
+		SegmentGap:             cfg.SegmentGap,
+		GapOnSpeakerChangeOnly: cfg.GapOnSpeakerChange,
+		SegmentMarker:          marker,
+		NormalizeSilence:       cfg.NormalizeSilence,
+		AudioBitrate:           cfg.AudioBitrate,
+		OutputSampleRate:       cfg.OutputSampleRate,
+		LoudnessGate:           loudness,
+		MaxMemory:              maxMemory,
+		MaxTempDisk:            maxTempDisk,
+		PreciseSplit:           cfg.PreciseSplit,
+		DurationSanityCheck:    cfg.DurationCheck,
+		SavePartial:            cfg.SavePartial,
+		EmbedScript:            cfg.EmbedScript,
+		EmbedSettings:          embedSettings(cfg),
+		Automations:            automations,
+		RequestsPerSecond:      cfg.EngineRPS,
+		Concurrency:            cfg.EngineConcurrency,
+		Progress:               progress,
+		ForeignWords:           cfg.ForeignWords,
+		AllowPartial:           cfg.AllowPartial,
+		FailFastRate:           cfg.FailFastRate,
+		PriorityGate:           gate,
+		SegmentTimeoutBase:     cfg.SegmentTimeout,
+		SegmentTimeoutPerChar:  cfg.SegmentTimeoutChar,
+	}), nil
+}
+
+// embedSettings は --embed-script で音声に埋め込む、スクリプトの生成・合成時の設定を返します。指定の無い設定は含めません。
+func embedSettings(cfg *config.Config) map[string]string {
+	settings := map[string]string{
+		"mode":                  cfg.Mode,
+		"model":                 cfg.AIModel,
+		"source-type":           cfg.SourceType,
+		"foreign-word-handling": cfg.ForeignWords,
+		"automate":              strings.Join(cfg.Automate, ","),
+		"user-dict":             cfg.UserDict,
+		"speaker-config":        cfg.SpeakerConfig,
+		"audio-bitrate":         cfg.AudioBitrate,
+	}
+	if cfg.Temperature != 0 {
+		settings["temperature"] = strconv.FormatFloat(cfg.Temperature, 'g', -1, 64)
+	}
+	if cfg.SegmentGap > 0 {
+		settings["segment-gap"] = cfg.SegmentGap.String()
+	}
+	maps.DeleteFunc(settings, func(_, v string) bool { return v == "" })
+	return settings
+}
+
+// NewVoiceClient は、設定に従って VOICEVOX エンジンの HTTP API を呼び出す voicevox.Client を生成します。
+func NewVoiceClient(cfg *config.Config) (*voicevox.Client, error) {
+	httpClient, err := newEngineHTTPClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var doer voicevox.Doer = httpClient
+	if cfg.AuditLog != "" {
+		doer = NewAuditDoer(doer, cfg.AuditLog, cfg.AuditLogText)
+	}
+	client := voicevox.NewClient(cfg.VoicevoxAPIURL, doer).WithRequestTimeout(cfg.HTTPTimeout)
+	if cachePath, err := voicevox.DefaultSpeakerCachePath(); err == nil {
+		client.WithSpeakerCache(voicevox.NewSpeakerCache(cachePath, voicevox.DefaultSpeakerCacheTTL, cfg.RefreshSpeakers))
+	} else {
+		slog.Warn("話者一覧のキャッシュを使わずに起動します。", "error", err)
+	}
+
+	if cfg.UserDict != "" {
+		dict, err := voicevox.LoadUserDict(cfg.UserDict)
+		if err != nil {
+			return nil, err
+		}
+		client.WithUserDict(dict)
+		slog.Info("ユーザー辞書を読み込みました。", "words", dict.Len(), "path", cfg.UserDict)
+	}
+	return client, nil
+}
+
+// newEngineHTTPClient はエンジン接続用の HTTP クライアントを生成します。
+// 自己署名証明書の HTTPS エンジンに接続できるよう、カスタムCA証明書の追加と証明書検証のスキップに対応します。
+// 合成のタイムアウトはセグメントの文字数から決まり --http-timeout を超えることがあるため、クライアント全体のタイムアウトは設定しません。
+// 合成以外のリクエストには voicevox.Client.WithRequestTimeout で --http-timeout を適用します。
+func newEngineHTTPClient(cfg *config.Config) (*http.Client, error) {
+	if cfg.EngineCACert == "" && !cfg.EngineInsecure {
+		return &http.Client{}, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.EngineCACert != "" {
+		pem, err := os.ReadFile(cfg.EngineCACert)
+		if err != nil {
+			return nil, fmt.Errorf("エンジンのCA証明書の読み込みに失敗しました (%s): %w", cfg.EngineCACert, err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("エンジンのCA証明書に有効なPEM証明書が含まれていません: %s", cfg.EngineCACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.EngineInsecure {
+		slog.Warn("VOICEVOXエンジンのTLS証明書の検証を無効にしています。信頼できるネットワーク以外では使用しないでください。", "url", cfg.VoicevoxAPIURL)
+		tlsConfig.InsecureSkipVerify = true
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	return &http.Client{Transport: transport}, nil
 }
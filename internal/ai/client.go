@@ -0,0 +1,207 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"google.golang.org/genai"
+
+	"prototypus-ai-doc-go/internal/history"
+)
+
+const (
+	// DefaultTemperature は生成時の既定の温度です。
+	DefaultTemperature float32 = 0.7
+	// DefaultTopP は生成時の既定の Top-P です。
+	DefaultTopP float32 = 0.95
+
+	// defaultMaxAttempts は再試行可能なエラーに対する呼び出し回数の上限です。
+	defaultMaxAttempts = 2
+	// defaultRetryDelay は再試行までの既定の待機時間です。
+	defaultRetryDelay = 30 * time.Second
+)
+
+// ErrEmptyPrompt はプロンプトが空の場合のエラーです。
+var ErrEmptyPrompt = errors.New("プロンプトを空にすることはできません")
+
+// Config は Client の初期化設定です。
+// Gemini API を使う場合は APIKey を、Vertex AI を使う場合は ProjectID と LocationID を指定します。
+type Config struct {
+	APIKey     string
+	ProjectID  string
+	LocationID string
+	// RetryDelay は再試行可能なエラー時の待機時間です。0 の場合は defaultRetryDelay を使います。
+	RetryDelay time.Duration
+	// Temperature は生成時の温度です。nil の場合は DefaultTemperature を使います。
+	Temperature *float32
+	// MaxOutputTokens は1回の生成の最大出力トークン数です。0 の場合はモデルの既定値を使います。
+	// 指定した場合は上限で途中切れになっても継続生成しません。
+	MaxOutputTokens int32
+}
+
+// Response は生成結果です。
+type Response struct {
+	Text string
+	// FinishReason はモデルが生成を終了した理由です (例: STOP, MAX_TOKENS)。
+	FinishReason genai.FinishReason
+	RawResponse  *genai.GenerateContentResponse
+}
+
+// Generator はスクリプト生成に使う AI モデルの操作を定義します。
+type Generator interface {
+	// GenerateContent はテキストプロンプトからテキストを生成します。
+	GenerateContent(ctx context.Context, modelName string, prompt string) (*Response, error)
+	// GenerateStructured は schema に従った JSON を生成します。
+	GenerateStructured(ctx context.Context, modelName string, prompt string, schema *genai.Schema) (*Response, error)
+}
+
+// Client は genai SDK をラップした Generator の実装です。
+type Client struct {
+	client          *genai.Client
+	retryDelay      time.Duration
+	temperature     float32
+	maxOutputTokens int32
+}
+
+// NewClient は設定に従って Gemini API または Vertex AI に接続する Client を生成します。
+func NewClient(ctx context.Context, cfg Config) (*Client, error) {
+	clientConfig := &genai.ClientConfig{}
+	switch {
+	case cfg.APIKey != "":
+		clientConfig.APIKey = cfg.APIKey
+		clientConfig.Backend = genai.BackendGeminiAPI
+	case cfg.ProjectID != "" && cfg.LocationID != "":
+		clientConfig.Project = cfg.ProjectID
+		clientConfig.Location = cfg.LocationID
+		clientConfig.Backend = genai.BackendVertexAI
+	default:
+		return nil, fmt.Errorf("APIKey または ProjectID/LocationID のいずれかが必須です")
+	}
+
+	client, err := genai.NewClient(ctx, clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("Geminiクライアントの作成に失敗しました: %w", err)
+	}
+
+	retryDelay := cfg.RetryDelay
+	if retryDelay == 0 {
+		retryDelay = defaultRetryDelay
+	}
+	temperature := DefaultTemperature
+	if cfg.Temperature != nil {
+		temperature = *cfg.Temperature
+	}
+	return &Client{client: client, retryDelay: retryDelay, temperature: temperature, maxOutputTokens: cfg.MaxOutputTokens}, nil
+}
+
+// GenerateContent は Generator を実装します。
+// 出力が最大トークン数で途中切れになった場合は、続きを継続生成して結合します (continueGeneration)。
+// ただし Config.MaxOutputTokens を指定した場合は継続生成しません。
+func (c *Client) GenerateContent(ctx context.Context, modelName string, prompt string) (*Response, error) {
+	if prompt == "" {
+		return nil, ErrEmptyPrompt
+	}
+	resp, err := c.generate(ctx, modelName, genai.Text(prompt), c.baseConfig())
+	if err != nil {
+		return nil, err
+	}
+	if c.maxOutputTokens > 0 {
+		// 最大出力トークン数を明示した場合は、上限で打ち切る意図を尊重して継続生成しない
+		if resp.FinishReason == genai.FinishReasonMaxTokens {
+			slog.WarnContext(ctx, "出力が指定した最大出力トークン数で途中切れになりました。", "model", modelName, "max_output_tokens", c.maxOutputTokens)
+		}
+		return resp, nil
+	}
+	return c.continueGeneration(ctx, modelName, prompt, resp)
+}
+
+// GenerateStructured は Generator を実装します。レスポンスは application/json で schema に従って生成されます。
+func (c *Client) GenerateStructured(ctx context.Context, modelName string, prompt string, schema *genai.Schema) (*Response, error) {
+	config := c.baseConfig()
+	config.ResponseMIMEType = "application/json"
+	config.ResponseSchema = schema
+	if prompt == "" {
+		return nil, ErrEmptyPrompt
+	}
+	return c.generate(ctx, modelName, genai.Text(prompt), config)
+}
+
+// baseConfig は全ての生成リクエストに共通する設定を返します。
+func (c *Client) baseConfig() *genai.GenerateContentConfig {
+	return &genai.GenerateContentConfig{
+		Temperature:     genai.Ptr(c.temperature),
+		TopP:            genai.Ptr(DefaultTopP),
+		CandidateCount:  1,
+		MaxOutputTokens: c.maxOutputTokens,
+	}
+}
+
+// generate は API を呼び出し、再試行可能なエラーは一定回数まで再試行します。
+func (c *Client) generate(ctx context.Context, modelName string, contents []*genai.Content, config *genai.GenerateContentConfig) (*Response, error) {
+	var lastErr error
+	for attempt := 1; attempt <= defaultMaxAttempts; attempt++ {
+		resp, err := c.generateOnce(ctx, modelName, contents, config)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		if !isRetryable(err) || attempt == defaultMaxAttempts {
+			break
+		}
+
+		slog.WarnContext(ctx, "Gemini API の呼び出しに失敗したため再試行します。", "model", modelName, "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(lastErr, ctx.Err())
+		case <-time.After(c.retryDelay):
+		}
+	}
+	return nil, fmt.Errorf("Gemini API 呼び出し（モデル: %s）に失敗しました: %w", modelName, lastErr)
+}
+
+// generateOnce は API を1回だけ呼び出し、レスポンスからテキストを取り出します。
+func (c *Client) generateOnce(ctx context.Context, modelName string, contents []*genai.Content, config *genai.GenerateContentConfig) (*Response, error) {
+	resp, err := c.client.Models.GenerateContent(ctx, modelName, contents, config)
+	if err != nil {
+		return nil, err
+	}
+	if usage := resp.UsageMetadata; usage != nil {
+		history.AddTokens(ctx, int(usage.PromptTokenCount), int(usage.CandidatesTokenCount))
+	}
+	if len(resp.Candidates) == 0 || resp.Candidates[0] == nil {
+		if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+			return nil, fmt.Errorf("プロンプトがブロックされました: %s", resp.PromptFeedback.BlockReason)
+		}
+		return nil, fmt.Errorf("モデルから候補が返されませんでした")
+	}
+
+	finishReason := resp.Candidates[0].FinishReason
+	text := resp.Text()
+	if text == "" {
+		return nil, fmt.Errorf("モデルが空のレスポンスを返しました (finish_reason: %s)", finishReason)
+	}
+	return &Response{
+		Text:         text,
+		FinishReason: finishReason,
+		RawResponse:  resp,
+	}, nil
+}
+
+// isRetryable は API エラーが再試行に値するか (レート制限またはサーバーエラー) を判定します。
+func isRetryable(err error) bool {
+	var apiErr genai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Code == http.StatusTooManyRequests || apiErr.Code >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// IsInvalidArgument はモデルがリクエストの設定 (レスポンススキーマなど) に対応していない場合のエラーかを判定します。
+func IsInvalidArgument(err error) bool {
+	var apiErr genai.APIError
+	return errors.As(err, &apiErr) && apiErr.Code == http.StatusBadRequest
+}
@@ -0,0 +1,64 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// maxContinuations は出力が途中切れになった場合に継続生成する回数の上限です。
+const maxContinuations = 3
+
+// continuationPrompt は途中切れの出力の続きを書かせる指示です。
+// 最後の行は途中で切れている可能性があるため破棄し、その行をタグから書き直させて話者タグの連続性を保ちます。
+const continuationPrompt = `出力が途中で切れました。直前までの出力の続きを書いてください。
+* 直前の出力の最後の行は途中で切れているため破棄しました。その行から、[話者タグ][スタイルタグ] を含む完全な形式で書き直してください。
+* 既に出力した行を繰り返さず、同じフォーマット制約を守ってください。
+* 前置きや説明は書かず、スクリプトの続きのみを出力してください。`
+
+// continueGeneration は resp が最大トークン数で途中切れになっている間、直前までの出力をコンテキストに渡して続きを生成し、結合します。
+// 継続回数は maxContinuations までで、上限に達した場合はそこまでの出力を返します。
+func (c *Client) continueGeneration(ctx context.Context, modelName string, prompt string, resp *Response) (*Response, error) {
+	text := resp.Text
+	for i := 0; resp.FinishReason == genai.FinishReasonMaxTokens; i++ {
+		if i == maxContinuations {
+			slog.WarnContext(ctx, "継続生成の回数が上限に達したため、途中までの出力を返します。", "model", modelName, "continuations", i)
+			break
+		}
+
+		kept := dropIncompleteLine(text)
+		slog.InfoContext(ctx, "出力が最大トークン数で途中切れになったため、続きを生成します。", "model", modelName, "continuation", i+1, "length", len(kept))
+
+		contents := []*genai.Content{
+			genai.NewContentFromText(prompt, genai.RoleUser),
+			genai.NewContentFromText(kept, genai.RoleModel),
+			genai.NewContentFromText(continuationPrompt, genai.RoleUser),
+		}
+		next, err := c.generate(ctx, modelName, contents, c.baseConfig())
+		if err != nil {
+			return nil, fmt.Errorf("継続生成 (%d 回目) に失敗しました: %w", i+1, err)
+		}
+
+		text = kept + strings.TrimLeft(next.Text, "\n")
+		resp = next
+	}
+
+	resp.Text = text
+	return resp, nil
+}
+
+// dropIncompleteLine は改行で終わっていない最後の行 (途中で切れた行) を取り除き、改行で終わるテキストを返します。
+func dropIncompleteLine(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	i := strings.LastIndex(text, "\n")
+	if i < 0 {
+		// 1行しか無い場合は破棄すると文脈が失われるため残す
+		return text + "\n"
+	}
+	return text[:i+1]
+}
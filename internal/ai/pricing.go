@@ -0,0 +1,63 @@
+package ai
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Pricing は AI モデルの 100 万トークンあたりの単価 (米ドル) です。
+type Pricing struct {
+	Input  float64 `yaml:"input"`
+	Output float64 `yaml:"output"`
+}
+
+// DefaultPricing はモデルごとの単価の組み込みテーブルです (Gemini API の有料枠の標準料金、プロンプト 20 万トークン以下)。
+// 料金は改定されるため、最新の単価は --pricing-file で上書きしてください。
+var DefaultPricing = map[string]Pricing{
+	"gemini-2.5-pro":        {Input: 1.25, Output: 10.00},
+	"gemini-2.5-flash":      {Input: 0.30, Output: 2.50},
+	"gemini-2.5-flash-lite": {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+	"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+}
+
+// LoadPricing は「モデル名: {input: 単価, output: 単価}」形式の YAML を読み込み、組み込みのテーブルに上書きした単価表を返します。
+func LoadPricing(r io.Reader) (map[string]Pricing, error) {
+	var entries map[string]Pricing
+	if err := yaml.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("単価表の解析に失敗しました: %w", err)
+	}
+	table := maps.Clone(DefaultPricing)
+	for model, p := range entries {
+		if p.Input < 0 || p.Output < 0 {
+			return nil, fmt.Errorf("単価表の %s の単価が負の値です", model)
+		}
+		table[strings.TrimSpace(model)] = p
+	}
+	return table, nil
+}
+
+// Cost は入出力のトークン数から費用 (米ドル) を算出します。
+func (p Pricing) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*p.Input + float64(outputTokens)*p.Output) / 1_000_000
+}
+
+// EstimateTokens はテキストのトークン数を概算します。
+// 日本語などの非 ASCII 文字は1文字1トークン、ASCII 文字は4文字1トークンとみなす目安で、実際のトークナイザーとは一致しません。
+func EstimateTokens(text string) int {
+	ascii := 0
+	other := 0
+	for _, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	return other + (ascii+3)/4
+}
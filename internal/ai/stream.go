@@ -0,0 +1,114 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+
+	"prototypus-ai-doc-go/internal/history"
+)
+
+// blockedFinishReasons は安全性フィルタなどにより生成が打ち切られたことを表す終了理由です。
+var blockedFinishReasons = []genai.FinishReason{
+	genai.FinishReasonSafety,
+	genai.FinishReasonRecitation,
+	genai.FinishReasonBlocklist,
+	genai.FinishReasonProhibitedContent,
+	genai.FinishReasonSPII,
+}
+
+// StreamGenerator はレスポンスをストリーミングで受け取れる Generator です。
+type StreamGenerator interface {
+	Generator
+	// GenerateScriptStream はテキストプロンプトからテキストを生成し、受信したチャンクを順に w に書き込みます。
+	GenerateScriptStream(ctx context.Context, modelName string, prompt string, w io.Writer) (*Response, error)
+}
+
+// GenerateScriptStream は StreamGenerator を実装します。
+// 受信したチャンクを逐次 w に書き込み、完了後に全文を返します。途中で安全性ブロックにより打ち切られた場合はエラーを返します。
+// 最初のチャンクを受信する前の再試行可能なエラーは再試行し、出力が最大トークン数で途中切れになった場合は GenerateContent と同様に継続生成します。
+func (c *Client) GenerateScriptStream(ctx context.Context, modelName string, prompt string, w io.Writer) (*Response, error) {
+	if prompt == "" {
+		return nil, ErrEmptyPrompt
+	}
+
+	var resp *Response
+	var lastErr error
+	for attempt := 1; attempt <= defaultMaxAttempts; attempt++ {
+		var received bool
+		resp, received, lastErr = c.streamOnce(ctx, modelName, genai.Text(prompt), w)
+		if lastErr == nil {
+			break
+		}
+		if received || !isRetryable(lastErr) || attempt == defaultMaxAttempts {
+			return nil, fmt.Errorf("Gemini API のストリーミング呼び出し（モデル: %s）に失敗しました: %w", modelName, lastErr)
+		}
+
+		slog.WarnContext(ctx, "Gemini API のストリーミング呼び出しに失敗したため再試行します。", "model", modelName, "attempt", attempt, "error", lastErr)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(lastErr, ctx.Err())
+		case <-time.After(c.retryDelay):
+		}
+	}
+
+	if c.maxOutputTokens > 0 {
+		if resp.FinishReason == genai.FinishReasonMaxTokens {
+			slog.WarnContext(ctx, "出力が指定した最大出力トークン数で途中切れになりました。", "model", modelName, "max_output_tokens", c.maxOutputTokens)
+		}
+		return resp, nil
+	}
+	return c.continueGeneration(ctx, modelName, prompt, resp)
+}
+
+// streamOnce はストリーミング API を1回だけ呼び出し、チャンクを w に書き込みながら全文を組み立てます。
+// 2番目の戻り値は1つ以上のチャンクを受信したかを返します。
+func (c *Client) streamOnce(ctx context.Context, modelName string, contents []*genai.Content, w io.Writer) (*Response, bool, error) {
+	var received bool
+	var sb strings.Builder
+	var last *genai.GenerateContentResponse
+	var finishReason genai.FinishReason
+	for chunk, err := range c.client.Models.GenerateContentStream(ctx, modelName, contents, c.baseConfig()) {
+		if err != nil {
+			return nil, received, err
+		}
+		received = true
+		last = chunk
+		if chunk.PromptFeedback != nil && chunk.PromptFeedback.BlockReason != "" {
+			return nil, received, fmt.Errorf("プロンプトがブロックされました: %s", chunk.PromptFeedback.BlockReason)
+		}
+		if len(chunk.Candidates) == 0 || chunk.Candidates[0] == nil {
+			continue
+		}
+		if reason := chunk.Candidates[0].FinishReason; reason != "" {
+			finishReason = reason
+			if slices.Contains(blockedFinishReasons, reason) {
+				return nil, received, fmt.Errorf("生成が途中でブロックされました (finish_reason: %s)", reason)
+			}
+		}
+		text := chunk.Text()
+		sb.WriteString(text)
+		if _, err := io.WriteString(w, text); err != nil {
+			slog.WarnContext(ctx, "ストリーミング出力の表示に失敗しました。", "error", err)
+		}
+	}
+	if received {
+		io.WriteString(w, "\n")
+	}
+
+	// 使用量は最後のチャンクに全体の合計が入る
+	if last != nil && last.UsageMetadata != nil {
+		history.AddTokens(ctx, int(last.UsageMetadata.PromptTokenCount), int(last.UsageMetadata.CandidatesTokenCount))
+	}
+	if sb.Len() == 0 {
+		return nil, received, fmt.Errorf("モデルが空のレスポンスを返しました (finish_reason: %s)", finishReason)
+	}
+	return &Response{Text: sb.String(), FinishReason: finishReason, RawResponse: last}, received, nil
+}
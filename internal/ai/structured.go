@@ -0,0 +1,118 @@
+package ai
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// ScriptSegment は構造化出力で受け取るスクリプトの1発話です。
+type ScriptSegment struct {
+	Speaker string `json:"speaker"`
+	Style   string `json:"style"`
+	Text    string `json:"text"`
+}
+
+// ScriptSchema は話者・スタイル・テキストからなるセグメント配列のレスポンススキーマを返します。
+// 話者とスタイルは列挙値で制約し、タグの表記揺れが生じないようにします。
+func ScriptSchema(speakers, styles []string) *genai.Schema {
+	return &genai.Schema{
+		Type:        genai.TypeArray,
+		Description: "ナレーションスクリプトの発話を、読み上げる順に並べた配列",
+		Items: &genai.Schema{
+			Type: genai.TypeObject,
+			Properties: map[string]*genai.Schema{
+				"speaker": {
+					Type:        genai.TypeString,
+					Description: "発話する話者名",
+					Enum:        speakers,
+				},
+				"style": {
+					Type:        genai.TypeString,
+					Description: "発話のスタイル名",
+					Enum:        styles,
+				},
+				"text": {
+					Type:        genai.TypeString,
+					Description: "読み上げる本文。話者名やスタイル名のタグは含めない",
+				},
+			},
+			Required:         []string{"speaker", "style", "text"},
+			PropertyOrdering: []string{"speaker", "style", "text"},
+		},
+	}
+}
+
+// DecodeSegments は構造化出力の JSON をセグメント配列に変換します。本文が空のセグメントは除外します。
+func DecodeSegments(text string) ([]ScriptSegment, error) {
+	var segments []ScriptSegment
+	if err := json.Unmarshal([]byte(text), &segments); err != nil {
+		return nil, fmt.Errorf("構造化出力の解析に失敗しました: %w", err)
+	}
+
+	out := segments[:0]
+	for _, seg := range segments {
+		seg.Text = strings.TrimSpace(seg.Text)
+		if seg.Text == "" {
+			continue
+		}
+		out = append(out, seg)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("構造化出力にセグメントが含まれていません")
+	}
+	return out, nil
+}
+
+// Metadata は構造化出力で受け取る、動画・Podcast の説明欄に載せる説明文とハッシュタグです。
+type Metadata struct {
+	Description string   `json:"description"`
+	Hashtags    []string `json:"hashtags"`
+}
+
+// MetadataSchema は説明文とハッシュタグの配列からなるメタ情報のレスポンススキーマを返します。
+func MetadataSchema() *genai.Schema {
+	return &genai.Schema{
+		Type: genai.TypeObject,
+		Properties: map[string]*genai.Schema{
+			"description": {
+				Type:        genai.TypeString,
+				Description: "動画・Podcast の説明欄に載せる、内容を要約した説明文",
+			},
+			"hashtags": {
+				Type:        genai.TypeArray,
+				Description: "内容に関連するハッシュタグ。先頭に # を付ける",
+				Items:       &genai.Schema{Type: genai.TypeString},
+			},
+		},
+		Required:         []string{"description", "hashtags"},
+		PropertyOrdering: []string{"description", "hashtags"},
+	}
+}
+
+// DecodeMetadata は構造化出力の JSON をメタ情報に変換します。
+// ハッシュタグは空白を除いて先頭に # を付け、重複と空のものを除外します。説明文が空の場合はエラーを返します。
+func DecodeMetadata(text string) (*Metadata, error) {
+	var m Metadata
+	if err := json.Unmarshal([]byte(text), &m); err != nil {
+		return nil, fmt.Errorf("構造化出力の解析に失敗しました: %w", err)
+	}
+	m.Description = strings.TrimSpace(m.Description)
+	if m.Description == "" {
+		return nil, fmt.Errorf("構造化出力に説明文が含まれていません")
+	}
+
+	hashtags := make([]string, 0, len(m.Hashtags))
+	for _, tag := range m.Hashtags {
+		tag = strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(tag), "#＃")), "")
+		if tag == "" || slices.Contains(hashtags, "#"+tag) {
+			continue
+		}
+		hashtags = append(hashtags, "#"+tag)
+	}
+	m.Hashtags = hashtags
+	return &m, nil
+}
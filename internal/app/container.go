@@ -8,6 +8,7 @@ import (
 
 	"prototypus-ai-doc-go/internal/config"
 	"prototypus-ai-doc-go/internal/domain"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // Container はアプリケーションの依存関係（DIコンテナ）を保持します。
@@ -18,7 +19,10 @@ type Container struct {
 	// External Adapters
 	HTTPClient httpkit.Requester
 	// Business Logic
-	Pipeline domain.Pipeline
+	Pipeline  domain.Pipeline
+	Prompter  domain.PromptRunner
+	Publisher domain.PublishRunner
+	Engine    *voicevox.Engine
 }
 
 // RemoteIO は外部ストレージ操作に関するコンポーネントをまとめます。
@@ -0,0 +1,62 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// ErrChunkNotFound は指定したチャンクが WAV に含まれていない場合に返されます。
+var ErrChunkNotFound = errors.New("チャンクが見つかりません")
+
+// AppendChunk は RIFF/WAVE 形式のバイト列の末尾に、ID が id のチャンクを追加したバイト列を返します。
+// 元のバイト列は変更しません。RIFF ヘッダーのサイズは追加後の長さに更新します。
+func AppendChunk(b []byte, id string, body []byte) ([]byte, error) {
+	if len(id) != 4 {
+		return nil, fmt.Errorf("チャンクIDは4バイトで指定してください: '%s'", id)
+	}
+	if len(b) < riffHeaderSize || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: RIFF/WAVEヘッダーが見つかりません", ErrInvalidWAV)
+	}
+
+	// 既存のチャンクが奇数長で終わっている場合は、追加するチャンクを2バイト境界に揃える
+	pad := len(b) % 2
+	out := make([]byte, len(b)+pad, len(b)+pad+chunkHeaderSize+len(body)+1)
+	copy(out, b)
+	out = append(out, id...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(body)))
+	out = append(out, body...)
+	if len(body)%2 == 1 {
+		out = append(out, 0)
+	}
+	binary.LittleEndian.PutUint32(out[4:8], uint32(len(out)-chunkHeaderSize))
+	return out, nil
+}
+
+// FindChunk は RIFF/WAVE 形式のバイト列から、ID が id の最初のチャンクの本体を返します。
+// 見つからない場合は ErrChunkNotFound を返します。
+func FindChunk(b []byte, id string) ([]byte, error) {
+	if len(b) < riffHeaderSize || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: RIFF/WAVEヘッダーが見つかりません", ErrInvalidWAV)
+	}
+
+	offset := riffHeaderSize
+	for offset+chunkHeaderSize <= len(b) {
+		chunkID := string(b[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(b[offset+4 : offset+8]))
+		body := offset + chunkHeaderSize
+		end := body + size
+		if end > len(b) || end < body {
+			// ParseWAV と同様に、data チャンクのみ末尾まで許容する
+			if chunkID != "data" {
+				return nil, fmt.Errorf("%w: チャンク '%s' のサイズが不正です", ErrInvalidWAV, chunkID)
+			}
+			end = len(b)
+		}
+		if chunkID == id {
+			return b[body:end], nil
+		}
+		offset = end + (end-body)%2
+	}
+	return nil, fmt.Errorf("%w: '%s'", ErrChunkNotFound, id)
+}
@@ -0,0 +1,43 @@
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// SetSample は i 番目のサンプルに -1.0〜1.0 に正規化した値を書き込みます。範囲外の値はクリップします。
+func (w *WAV) SetSample(i int, v float64) {
+	v = max(-1, min(1, v))
+	n := w.Format.bytesPerSample()
+	p := w.Data[i*n : i*n+n]
+	switch w.Format.BitsPerSample {
+	case 8:
+		// 8bit PCM は符号なし
+		p[0] = byte(min(math.Round(v*128+128), math.MaxUint8))
+	case 16:
+		binary.LittleEndian.PutUint16(p, uint16(int16(math.Round(v*math.MaxInt16))))
+	case 24:
+		s := int32(math.Round(v * ((1 << 23) - 1)))
+		p[0], p[1], p[2] = byte(s), byte(s>>8), byte(s>>16)
+	default:
+		binary.LittleEndian.PutUint32(p, uint32(int32(math.Round(v*math.MaxInt32))))
+	}
+}
+
+// ApplyEnvelope は PCM の各フレームに音量エンベロープを適用します。
+// gain は先頭を 0、末尾を 1 とした位置を受け取り、そのフレームに掛ける倍率を返します。
+func (w *WAV) ApplyEnvelope(gain func(pos float64) float64) {
+	frames := w.NumFrames()
+	channels := int(w.Format.Channels)
+	if frames == 0 || channels == 0 {
+		return
+	}
+	last := float64(max(frames-1, 1))
+	for f := 0; f < frames; f++ {
+		g := gain(float64(f) / last)
+		for c := 0; c < channels; c++ {
+			i := f*channels + c
+			w.SetSample(i, w.Sample(i)*g)
+		}
+	}
+}
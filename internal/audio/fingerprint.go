@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"math/bits"
+)
+
+const (
+	// fingerprintFrame は音声指紋の特徴量を算出する分析窓の長さ（秒）です。
+	fingerprintFrame = 0.1
+	// fingerprintBitsPerFrame は1分析窓あたりの指紋ビット数です (エネルギー変化とゼロ交差率変化)。
+	fingerprintBitsPerFrame = 2
+	// fingerprintMaxShift は近似一致の照合で許容する分析窓のずれ（窓数）です。先頭の無音長の差を吸収します。
+	fingerprintMaxShift = 10
+)
+
+// Fingerprint は WAV の音声指紋です。
+// SHA256 は PCM の完全一致判定に、Bits は分析窓ごとのエネルギーとゼロ交差率の増減を並べた近似一致判定に使います。
+type Fingerprint struct {
+	SHA256   string  `json:"sha256"`
+	Bits     string  `json:"bits"`
+	Frames   int     `json:"frames"`
+	Duration float64 `json:"duration_sec"`
+}
+
+// NewFingerprint は WAV から音声指紋を生成します。多チャンネルの音声はモノラルに平均化して扱います。
+func NewFingerprint(w *WAV) Fingerprint {
+	sum := sha256.Sum256(w.Data)
+	fp := Fingerprint{SHA256: hex.EncodeToString(sum[:]), Duration: w.Duration()}
+
+	channels := int(w.Format.Channels)
+	frameLen := int(float64(w.Format.SampleRate) * fingerprintFrame)
+	if channels == 0 || frameLen <= 0 {
+		return fp
+	}
+
+	var energies, crossings []float64
+	frames := w.NumFrames()
+	for start := 0; start+frameLen <= frames; start += frameLen {
+		var energy float64
+		var zc int
+		prev := 0.0
+		for f := start; f < start+frameLen; f++ {
+			var v float64
+			for c := 0; c < channels; c++ {
+				v += w.Sample(f*channels + c)
+			}
+			v /= float64(channels)
+			energy += v * v
+			if f > start && (v >= 0) != (prev >= 0) {
+				zc++
+			}
+			prev = v
+		}
+		energies = append(energies, math.Log1p(energy))
+		crossings = append(crossings, float64(zc))
+	}
+
+	packed := make([]byte, (len(energies)*fingerprintBitsPerFrame+7)/8)
+	setBit := func(i int) { packed[i/8] |= 1 << (7 - i%8) }
+	for i := 1; i < len(energies); i++ {
+		if energies[i] > energies[i-1] {
+			setBit(i * fingerprintBitsPerFrame)
+		}
+		if crossings[i] > crossings[i-1] {
+			setBit(i*fingerprintBitsPerFrame + 1)
+		}
+	}
+	fp.Bits = hex.EncodeToString(packed)
+	fp.Frames = len(energies)
+	return fp
+}
+
+// Similarity は2つの音声指紋の類似度を 0〜1 で返します。完全一致の場合は 1 です。
+// 分析窓のずれを fingerprintMaxShift まで許容して最も一致するビット率を求め、尺の比で重み付けします。
+func (f Fingerprint) Similarity(o Fingerprint) float64 {
+	if f.SHA256 != "" && f.SHA256 == o.SHA256 {
+		return 1
+	}
+	a, errA := hex.DecodeString(f.Bits)
+	b, errB := hex.DecodeString(o.Bits)
+	if errA != nil || errB != nil || f.Frames < 2 || o.Frames < 2 {
+		return 0
+	}
+
+	best := 0.0
+	for shift := -fingerprintMaxShift; shift <= fingerprintMaxShift; shift++ {
+		if r := matchRatio(a, f.Frames, b, o.Frames, shift); r > best {
+			best = r
+		}
+	}
+	lengthRatio := float64(min(f.Frames, o.Frames)) / float64(max(f.Frames, o.Frames))
+	return best * lengthRatio
+}
+
+// matchRatio は b を shift 窓ずらして a と重ねたときに一致するビットの割合を返します。
+func matchRatio(a []byte, framesA int, b []byte, framesB int, shift int) float64 {
+	bit := func(p []byte, i int) uint8 { return (p[i/8] >> (7 - i%8)) & 1 }
+
+	var same, total int
+	for fa := 1; fa < framesA; fa++ {
+		fb := fa + shift
+		if fb < 1 || fb >= framesB {
+			continue
+		}
+		var diff uint8
+		for k := 0; k < fingerprintBitsPerFrame; k++ {
+			diff |= (bit(a, fa*fingerprintBitsPerFrame+k) ^ bit(b, fb*fingerprintBitsPerFrame+k)) << k
+		}
+		same += fingerprintBitsPerFrame - bits.OnesCount8(diff)
+		total += fingerprintBitsPerFrame
+	}
+	if total == 0 {
+		return 0
+	}
+	return float64(same) / float64(total)
+}
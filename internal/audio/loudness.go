@@ -0,0 +1,213 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+const (
+	// loudnessBlock はラウドネスのゲーティングに用いるブロックの長さ (秒) です (ITU-R BS.1770)。
+	loudnessBlock = 0.4
+	// loudnessSubBlocks はブロックを分割するサブブロックの数です。ブロックは 75% ずつ重ねて走査します。
+	loudnessSubBlocks = 4
+	// absoluteGateLUFS は絶対ゲートの閾値です。これより小さいブロックは無音とみなします。
+	absoluteGateLUFS = -70
+	// relativeGateLU は相対ゲートの閾値 (絶対ゲート通過後の平均からの差) です。
+	relativeGateLU = -10
+)
+
+// LoudnessPreset は配信先ごとのラウドネス基準です (--loudness-gate)。
+type LoudnessPreset struct {
+	Name string
+	// TargetLUFS は目標の統合ラウドネス (LUFS) です。
+	TargetLUFS float64
+	// ToleranceLU は目標からの許容誤差 (LU) です。
+	ToleranceLU float64
+	// MaxPeakDB はサンプルピークの上限 (dBFS) です。
+	MaxPeakDB float64
+}
+
+// loudnessPresets は組み込みのラウドネス基準です。
+var loudnessPresets = []LoudnessPreset{
+	{Name: "youtube", TargetLUFS: -14, ToleranceLU: 1, MaxPeakDB: -1},
+	{Name: "podcast", TargetLUFS: -16, ToleranceLU: 1, MaxPeakDB: -1},
+	{Name: "ebu-r128", TargetLUFS: -23, ToleranceLU: 1, MaxPeakDB: -1},
+}
+
+// LoudnessPresetNames は --loudness-gate に指定できるプリセット名の一覧です。
+var LoudnessPresetNames = func() []string {
+	names := make([]string, len(loudnessPresets))
+	for i, p := range loudnessPresets {
+		names[i] = p.Name
+	}
+	return names
+}()
+
+// LookupLoudnessPreset は名前に対応するラウドネス基準を返します。
+func LookupLoudnessPreset(name string) (LoudnessPreset, error) {
+	i := slices.IndexFunc(loudnessPresets, func(p LoudnessPreset) bool { return p.Name == strings.ToLower(name) })
+	if i < 0 {
+		return LoudnessPreset{}, fmt.Errorf("ラウドネス基準には %s のいずれかを指定してください: '%s'", strings.Join(LoudnessPresetNames, ", "), name)
+	}
+	return loudnessPresets[i], nil
+}
+
+// LoudnessReport は音声のラウドネスの測定結果です。
+type LoudnessReport struct {
+	// IntegratedLUFS は EBU R128 (ITU-R BS.1770) の統合ラウドネスです。
+	IntegratedLUFS float64 `json:"integrated_lufs"`
+	// PeakDB はサンプルピーク (dBFS) です。
+	PeakDB float64 `json:"peak_db"`
+}
+
+// Check は測定結果を基準と照合し、基準を外れた項目を返します。
+func (r LoudnessReport) Check(p LoudnessPreset) []string {
+	var problems []string
+	if diff := r.IntegratedLUFS - p.TargetLUFS; math.Abs(diff) > p.ToleranceLU {
+		problems = append(problems, fmt.Sprintf("統合ラウドネスが基準外です (%.1f LUFS, 基準 %.1f±%.1f LUFS)", r.IntegratedLUFS, p.TargetLUFS, p.ToleranceLU))
+	}
+	if r.PeakDB > p.MaxPeakDB {
+		problems = append(problems, fmt.Sprintf("ピークが上限を超えています (%.1f dBFS > %.1f dBFS)", r.PeakDB, p.MaxPeakDB))
+	}
+	return problems
+}
+
+// MeasureLoudness は PCM の統合ラウドネスとサンプルピークを測定します。
+// ラウドネスは K 特性フィルタを掛けた 400ms のブロックを、絶対ゲート (-70 LUFS) と相対ゲート (-10 LU) で選別して算出します。
+// 400ms に満たない音声や無音の統合ラウドネスは下限値 (-200) です。
+func MeasureLoudness(w *WAV) LoudnessReport {
+	report := LoudnessReport{IntegratedLUFS: amplitudeToDB(0)}
+	var peak float64
+	for i := range w.NumSamples() {
+		peak = max(peak, math.Abs(w.Sample(i)))
+	}
+	report.PeakDB = amplitudeToDB(peak)
+
+	channels := int(w.Format.Channels)
+	subBlockFrames := int(float64(w.Format.SampleRate) * loudnessBlock / loudnessSubBlocks)
+	if channels == 0 || subBlockFrames == 0 {
+		return report
+	}
+
+	// サブブロックごとに、K 特性フィルタ後の二乗和を全チャンネル分合計します。
+	subBlocks := make([]float64, w.NumFrames()/subBlockFrames)
+	for c := range channels {
+		filter := newKWeighting(float64(w.Format.SampleRate))
+		for f := range len(subBlocks) * subBlockFrames {
+			v := filter.process(w.Sample(f*channels + c))
+			subBlocks[f/subBlockFrames] += v * v
+		}
+	}
+
+	var blocks []float64
+	for i := 0; i+loudnessSubBlocks <= len(subBlocks); i++ {
+		var sum float64
+		for _, s := range subBlocks[i : i+loudnessSubBlocks] {
+			sum += s
+		}
+		blocks = append(blocks, sum/float64(subBlockFrames*loudnessSubBlocks))
+	}
+
+	gated := func(threshold float64) (float64, bool) {
+		var sum float64
+		var n int
+		for _, b := range blocks {
+			if blockLoudness(b) > threshold {
+				sum += b
+				n++
+			}
+		}
+		if n == 0 {
+			return 0, false
+		}
+		return sum / float64(n), true
+	}
+	mean, ok := gated(absoluteGateLUFS)
+	if !ok {
+		return report
+	}
+	if mean, ok = gated(blockLoudness(mean) + relativeGateLU); ok {
+		report.IntegratedLUFS = blockLoudness(mean)
+	}
+	return report
+}
+
+// NormalizeLoudness は統合ラウドネスが基準の目標値に近づくよう、PCM 全体に一律のゲインを掛けます。
+// ピークが上限を超えないようゲインを抑えるため、ピークの大きい音声は目標値まで上がらない場合があります。適用したゲイン (dB) を返します。
+func NormalizeLoudness(w *WAV, p LoudnessPreset) float64 {
+	r := MeasureLoudness(w)
+	if r.IntegratedLUFS <= absoluteGateLUFS {
+		return 0
+	}
+	gainDB := min(p.TargetLUFS-r.IntegratedLUFS, p.MaxPeakDB-r.PeakDB)
+	gain := dbToAmplitude(gainDB)
+	for i := range w.NumSamples() {
+		w.SetSample(i, w.Sample(i)*gain)
+	}
+	return gainDB
+}
+
+// blockLoudness はブロックの平均二乗値をラウドネス (LUFS) に変換します。
+func blockLoudness(meanSquare float64) float64 {
+	if meanSquare <= 0 {
+		return amplitudeToDB(0)
+	}
+	return -0.691 + 10*math.Log10(meanSquare)
+}
+
+// biquad は双2次 IIR フィルタです。
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// process は1サンプルをフィルタに通します。
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x1, f.x2 = x, f.x1
+	f.y1, f.y2 = y, f.y1
+	return y
+}
+
+// kWeighting は ITU-R BS.1770 の K 特性フィルタ (高域シェルフと高域通過の2段) です。
+type kWeighting struct {
+	shelf, highPass biquad
+}
+
+// newKWeighting はサンプルレート fs の K 特性フィルタを生成します。係数は規格の 48kHz の特性を任意のサンプルレートに換算したものです。
+func newKWeighting(fs float64) *kWeighting {
+	const (
+		shelfFreq = 1681.974450955533
+		shelfGain = 3.999843853973347
+		shelfQ    = 0.7071752369554196
+		passFreq  = 38.13547087602444
+		passQ     = 0.5003270373238773
+	)
+	k := math.Tan(math.Pi * shelfFreq / fs)
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + k/shelfQ + k*k
+	shelf := biquad{
+		b0: (vh + vb*k/shelfQ + k*k) / a0,
+		b1: 2 * (k*k - vh) / a0,
+		b2: (vh - vb*k/shelfQ + k*k) / a0,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/shelfQ + k*k) / a0,
+	}
+
+	k = math.Tan(math.Pi * passFreq / fs)
+	a0 = 1 + k/passQ + k*k
+	highPass := biquad{
+		b0: 1, b1: -2, b2: 1,
+		a1: 2 * (k*k - 1) / a0,
+		a2: (1 - k/passQ + k*k) / a0,
+	}
+	return &kWeighting{shelf: shelf, highPass: highPass}
+}
+
+// process は1サンプルを K 特性フィルタに通します。
+func (k *kWeighting) process(x float64) float64 {
+	return k.highPass.process(k.shelf.process(x))
+}
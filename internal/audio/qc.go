@@ -0,0 +1,130 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+const (
+	// qcWindow は無音判定に用いる分析窓の長さ（秒）です。
+	qcWindow = 0.02
+	// clipLevel はクリッピングとみなす正規化振幅です。
+	clipLevel = 0.999
+)
+
+// QCThresholds は品質検査の合否基準です。
+type QCThresholds struct {
+	// MaxDCOffset は許容するDCオフセット（正規化振幅の平均値の絶対値）の上限です。
+	MaxDCOffset float64
+	// MaxSilenceRatio は全体に占める無音区間の割合の上限です。
+	MaxSilenceRatio float64
+	// SilenceLevelDB は分析窓を無音とみなすRMSレベル (dBFS) です。
+	SilenceLevelDB float64
+	// MaxClippingRatio はクリッピングしたサンプルの割合の上限です。
+	MaxClippingRatio float64
+	// MinRMSLevelDB は全体のRMSレベル (dBFS) の下限です。これを下回ると音量不足と判定します。
+	MinRMSLevelDB float64
+}
+
+// DefaultQCThresholds は配信前チェックとして妥当な既定の合否基準を返します。
+func DefaultQCThresholds() QCThresholds {
+	return QCThresholds{
+		MaxDCOffset:      0.01,
+		MaxSilenceRatio:  0.5,
+		SilenceLevelDB:   -50,
+		MaxClippingRatio: 0.001,
+		MinRMSLevelDB:    -35,
+	}
+}
+
+// QCReport は WAV の品質検査結果です。
+type QCReport struct {
+	Duration      float64  `json:"duration_sec"`
+	DCOffset      float64  `json:"dc_offset"`
+	SilenceRatio  float64  `json:"silence_ratio"`
+	ClippingRatio float64  `json:"clipping_ratio"`
+	RMSLevelDB    float64  `json:"rms_level_db"`
+	Problems      []string `json:"problems,omitempty"`
+}
+
+// Passed は全ての検査項目が基準を満たしているかを返します。
+func (r *QCReport) Passed() bool {
+	return len(r.Problems) == 0
+}
+
+// Inspect は PCM を走査して DCオフセット・無音率・クリッピング率・RMSレベルを算出し、基準と照合します。
+func Inspect(w *WAV, th QCThresholds) *QCReport {
+	report := &QCReport{Duration: w.Duration()}
+
+	total := w.NumSamples()
+	if total == 0 {
+		report.Problems = append(report.Problems, "音声データが空です")
+		return report
+	}
+
+	windowSize := int(float64(w.Format.SampleRate)*qcWindow) * int(w.Format.Channels)
+	if windowSize <= 0 {
+		windowSize = total
+	}
+	silenceLevel := dbToAmplitude(th.SilenceLevelDB)
+
+	var (
+		sum, sumSq    float64
+		clipped       int
+		windows       int
+		silentWindows int
+		windowSumSq   float64
+		windowSamples int
+	)
+	for i := 0; i < total; i++ {
+		v := w.Sample(i)
+		sum += v
+		sumSq += v * v
+		if math.Abs(v) >= clipLevel {
+			clipped++
+		}
+
+		windowSumSq += v * v
+		windowSamples++
+		if windowSamples == windowSize || i == total-1 {
+			windows++
+			if math.Sqrt(windowSumSq/float64(windowSamples)) < silenceLevel {
+				silentWindows++
+			}
+			windowSumSq, windowSamples = 0, 0
+		}
+	}
+
+	report.DCOffset = sum / float64(total)
+	report.ClippingRatio = float64(clipped) / float64(total)
+	report.SilenceRatio = float64(silentWindows) / float64(windows)
+	report.RMSLevelDB = amplitudeToDB(math.Sqrt(sumSq / float64(total)))
+
+	if math.Abs(report.DCOffset) > th.MaxDCOffset {
+		report.Problems = append(report.Problems, fmt.Sprintf("DCオフセットが大きすぎます (%.4f > %.4f)", math.Abs(report.DCOffset), th.MaxDCOffset))
+	}
+	if report.SilenceRatio > th.MaxSilenceRatio {
+		report.Problems = append(report.Problems, fmt.Sprintf("無音率が高すぎます (%.1f%% > %.1f%%)", report.SilenceRatio*100, th.MaxSilenceRatio*100))
+	}
+	if report.ClippingRatio > th.MaxClippingRatio {
+		report.Problems = append(report.Problems, fmt.Sprintf("クリッピング率が高すぎます (%.3f%% > %.3f%%)", report.ClippingRatio*100, th.MaxClippingRatio*100))
+	}
+	if report.RMSLevelDB < th.MinRMSLevelDB {
+		report.Problems = append(report.Problems, fmt.Sprintf("音量が不足しています (%.1f dBFS < %.1f dBFS)", report.RMSLevelDB, th.MinRMSLevelDB))
+	}
+
+	return report
+}
+
+// dbToAmplitude は dBFS を正規化振幅に変換します。
+func dbToAmplitude(db float64) float64 {
+	return math.Pow(10, db/20)
+}
+
+// amplitudeToDB は正規化振幅を dBFS に変換します。無音は -Inf ではなく下限値に丸めます。
+func amplitudeToDB(amp float64) float64 {
+	if amp <= 1e-10 {
+		return -200
+	}
+	return 20 * math.Log10(amp)
+}
@@ -0,0 +1,94 @@
+package audio
+
+import (
+	"fmt"
+	"math"
+)
+
+// resampleLobes は Resample の Lanczos 窓の片側のローブ数です。大きいほど高品質ですが計算量が増えます。
+const resampleLobes = 8
+
+// Resample は PCM を rate Hz に変換した新しい WAV を返します。ビット深度とチャンネル数は変わりません。
+// 補間には Lanczos 窓付き sinc 関数を使い、ダウンサンプリングの場合は折り返し雑音を防ぐため変換先のナイキスト周波数で帯域制限します。
+// rate が元のサンプルレートと同じ場合は w をそのまま返します。
+func Resample(w *WAV, rate uint32) (*WAV, error) {
+	if rate == 0 {
+		return nil, fmt.Errorf("変換先のサンプルレートは正の値で指定してください")
+	}
+	src := w.Format.SampleRate
+	if src == 0 {
+		return nil, fmt.Errorf("%w: サンプルレートが0です", ErrInvalidWAV)
+	}
+	if rate == src {
+		return w, nil
+	}
+
+	channels := int(w.Format.Channels)
+	inFrames := w.NumFrames()
+	outFrames := int(math.Round(float64(inFrames) * float64(rate) / float64(src)))
+
+	f := w.Format
+	f.SampleRate = rate
+	f.BlockAlign = f.Channels * (f.BitsPerSample / 8)
+	f.ByteRate = rate * uint32(f.BlockAlign)
+	out := &WAV{Format: f, Data: make([]byte, outFrames*int(f.BlockAlign))}
+
+	// ダウンサンプリングの場合はカットオフを下げ、窓の幅を広げる
+	cutoff := min(1, float64(rate)/float64(src))
+	span := int(math.Ceil(float64(resampleLobes) / cutoff))
+	// 出力フレームの入力上の位置の小数部は rate/gcd 通りしかないため、小数部ごとの係数を事前に計算する
+	g := gcd(src, rate)
+	kernel := make([][]float64, rate/g)
+	for p := range kernel {
+		frac := float64(p) / float64(len(kernel))
+		taps := make([]float64, 2*span)
+		for j := range taps {
+			taps[j] = lanczos((frac-float64(j-span+1))*cutoff, resampleLobes)
+		}
+		kernel[p] = taps
+	}
+
+	for i := 0; i < outFrames; i++ {
+		pos := uint64(i) * uint64(src)
+		base := int(pos / uint64(rate))
+		taps := kernel[pos%uint64(rate)/uint64(g)]
+		for c := 0; c < channels; c++ {
+			var sum, weights float64
+			for j, weight := range taps {
+				k := base + j - span + 1
+				if k < 0 || k >= inFrames {
+					continue
+				}
+				sum += w.Sample(k*channels+c) * weight
+				weights += weight
+			}
+			if weights != 0 {
+				// 端での窓の欠けや窓関数のリップルによる音量の変化を補正する
+				sum /= weights
+			}
+			out.SetSample(i*channels+c, sum)
+		}
+	}
+	return out, nil
+}
+
+// gcd は a と b の最大公約数を返します。
+func gcd(a, b uint32) uint32 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// lanczos は Lanczos 窓付き sinc 関数の値を返します。
+func lanczos(x float64, lobes int) float64 {
+	a := float64(lobes)
+	switch {
+	case x == 0:
+		return 1
+	case math.Abs(x) >= a:
+		return 0
+	}
+	px := math.Pi * x
+	return a * math.Sin(px) * math.Sin(px/a) / (px * px)
+}
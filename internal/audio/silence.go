@@ -0,0 +1,142 @@
+package audio
+
+import "math"
+
+// DefaultSilenceLevelDB は無音区間の検出で無音とみなす既定のRMSレベル (dBFS) です。
+const DefaultSilenceLevelDB = -50.0
+
+// Span は音声上の区間です。Start と End は先頭からの秒数です。
+type Span struct {
+	Start float64 `json:"start_sec"`
+	End   float64 `json:"end_sec"`
+}
+
+// Duration は区間の長さ（秒）を返します。
+func (s Span) Duration() float64 {
+	return s.End - s.Start
+}
+
+// DetectSilences は qcWindow ごとのRMSレベルが levelDB を下回る窓が minDuration 秒以上続く区間を返します。
+// 先頭と末尾の無音も区間として返します。
+func DetectSilences(w *WAV, minDuration, levelDB float64) []Span {
+	frames := w.NumFrames()
+	channels := int(w.Format.Channels)
+	if frames == 0 || channels == 0 || w.Format.SampleRate == 0 {
+		return nil
+	}
+
+	windowFrames := int(float64(w.Format.SampleRate) * qcWindow)
+	if windowFrames <= 0 {
+		windowFrames = 1
+	}
+	level := dbToAmplitude(levelDB)
+	secondsPerFrame := 1 / float64(w.Format.SampleRate)
+
+	var (
+		spans        []Span
+		silenceStart = -1
+	)
+	closeSpan := func(endFrame int) {
+		if silenceStart < 0 {
+			return
+		}
+		span := Span{Start: float64(silenceStart) * secondsPerFrame, End: float64(endFrame) * secondsPerFrame}
+		if span.Duration() >= minDuration {
+			spans = append(spans, span)
+		}
+		silenceStart = -1
+	}
+
+	for start := 0; start < frames; start += windowFrames {
+		end := min(start+windowFrames, frames)
+		var sumSq float64
+		for i := start * channels; i < end*channels; i++ {
+			v := w.Sample(i)
+			sumSq += v * v
+		}
+		rms := math.Sqrt(sumSq / float64((end-start)*channels))
+
+		if rms < level {
+			if silenceStart < 0 {
+				silenceStart = start
+			}
+			continue
+		}
+		closeSpan(start)
+	}
+	closeSpan(frames)
+
+	return spans
+}
+
+// LeadingSilence は先頭から qcWindow ごとのRMSレベルが初めて levelDB 以上になるまでの秒数 (発話開始位置) を返します。
+// 全体が無音の場合は尺全体を返します。
+func LeadingSilence(w *WAV, levelDB float64) float64 {
+	frames := w.NumFrames()
+	channels := int(w.Format.Channels)
+	if frames == 0 || channels == 0 || w.Format.SampleRate == 0 {
+		return 0
+	}
+
+	windowFrames := max(int(float64(w.Format.SampleRate)*qcWindow), 1)
+	level := dbToAmplitude(levelDB)
+	for start := 0; start < frames; start += windowFrames {
+		end := min(start+windowFrames, frames)
+		var sumSq float64
+		for i := start * channels; i < end*channels; i++ {
+			v := w.Sample(i)
+			sumSq += v * v
+		}
+		if math.Sqrt(sumSq/float64((end-start)*channels)) >= level {
+			return float64(start) / float64(w.Format.SampleRate)
+		}
+	}
+	return w.Duration()
+}
+
+// ShortenSilences は maxDuration 秒より長い無音区間を maxDuration 秒に詰めた音声を返します。
+// 無音の前後をそれぞれ maxDuration の半分ずつ残して中間を削除するため、maxDuration 以下の自然な間はそのまま保ちます。
+// 削除した区間を元の音声の時刻で返します。詰める無音が無い場合は w をそのまま返します。
+func ShortenSilences(w *WAV, maxDuration, levelDB float64) (*WAV, []Span) {
+	rate := float64(w.Format.SampleRate)
+	blockAlign := int(w.Format.BlockAlign)
+	if rate == 0 || blockAlign == 0 || maxDuration < 0 {
+		return w, nil
+	}
+
+	keepFrames := int(maxDuration * rate)
+	var (
+		removed []Span
+		data    []byte
+		copied  int
+	)
+	for _, s := range DetectSilences(w, maxDuration, levelDB) {
+		startFrame, endFrame := int(math.Round(s.Start*rate)), int(math.Round(s.End*rate))
+		if endFrame-startFrame <= keepFrames {
+			continue
+		}
+		cutStart := startFrame + keepFrames/2
+		cutEnd := endFrame - (keepFrames - keepFrames/2)
+		data = append(data, w.Data[copied*blockAlign:cutStart*blockAlign]...)
+		copied = cutEnd
+		removed = append(removed, Span{Start: float64(cutStart) / rate, End: float64(cutEnd) / rate})
+	}
+	if len(removed) == 0 {
+		return w, nil
+	}
+	data = append(data, w.Data[copied*blockAlign:]...)
+	return &WAV{Format: w.Format, Data: data}, removed
+}
+
+// ShiftTime は元の音声の時刻 t を、removed の区間を削除した後の音声の時刻に変換します。
+// removed は ShortenSilences が返す、時刻順で重ならない区間です。削除した区間内の時刻は削除した位置に寄せます。
+func ShiftTime(t float64, removed []Span) float64 {
+	shifted := t
+	for _, r := range removed {
+		if r.Start >= t {
+			break
+		}
+		shifted -= min(t, r.End) - r.Start
+	}
+	return shifted
+}
@@ -0,0 +1,30 @@
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// toneFadeSeconds はトーンの立ち上がり・立ち下がりに掛けるフェードの長さです。矩形の開始・終了によるクリックノイズを防ぎます。
+const toneFadeSeconds = 0.002
+
+// TonePCM は f のフォーマットで、周波数 freq (Hz)・長さ d・振幅 amplitude (0〜1) の正弦波トーンの PCM を生成します。
+// 全チャンネルに同じ波形を書き込みます。
+func TonePCM(f Format, freq float64, d time.Duration, amplitude float64) []byte {
+	if d <= 0 || f.SampleRate == 0 || f.BlockAlign == 0 {
+		return nil
+	}
+	frames := int(d.Seconds() * float64(f.SampleRate))
+	w := &WAV{Format: f, Data: make([]byte, frames*int(f.BlockAlign))}
+	channels := int(f.Channels)
+	rate := float64(f.SampleRate)
+	fade := max(toneFadeSeconds*rate, 1)
+	for i := 0; i < frames; i++ {
+		gain := min(float64(i)/fade, float64(frames-1-i)/fade, 1)
+		v := amplitude * gain * math.Sin(2*math.Pi*freq*float64(i)/rate)
+		for c := 0; c < channels; c++ {
+			w.SetSample(i*channels+c, v)
+		}
+	}
+	return w.Data
+}
@@ -0,0 +1,122 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Codec は WAV から変換して出力できる圧縮形式です。
+type Codec struct {
+	// Name は形式の表示名です。
+	Name string
+	// ContentType は変換後のデータの MIME タイプです。
+	ContentType string
+	// Ext は出力ファイルの拡張子です。
+	Ext string
+	// DefaultBitrate はビットレートの指定が無い場合に使う値です (例: "128k")。
+	DefaultBitrate string
+	// encoderArgs は ffmpeg に渡すエンコーダ指定です。
+	encoderArgs []string
+}
+
+// codecs は出力ファイルの拡張子と圧縮形式の対応です。
+var codecs = map[string]Codec{
+	".mp3":  {Name: "MP3", ContentType: "audio/mpeg", Ext: ".mp3", DefaultBitrate: "128k", encoderArgs: []string{"-c:a", "libmp3lame"}},
+	".opus": {Name: "Opus", ContentType: "audio/ogg", Ext: ".opus", DefaultBitrate: "48k", encoderArgs: []string{"-c:a", "libopus", "-application", "voip"}},
+	".m4a":  {Name: "AAC", ContentType: "audio/mp4", Ext: ".m4a", DefaultBitrate: "96k", encoderArgs: []string{"-c:a", "aac", "-movflags", "+faststart"}},
+}
+
+// CodecForPath は出力ファイルの拡張子に対応する圧縮形式を返します。WAV や未知の拡張子の場合は false を返します。
+func CodecForPath(path string) (Codec, bool) {
+	c, ok := codecs[strings.ToLower(filepath.Ext(path))]
+	return c, ok
+}
+
+// Transcode は WAV を ffmpeg で codec の形式にエンコードします。
+// サンプルレート・チャンネル数・ビット深度は WAV の fmt チャンクから取得して ffmpeg に引き渡します。
+// bitrate が空の場合は codec の既定のビットレートを使います。
+func Transcode(ctx context.Context, w *WAV, codec Codec, bitrate string) ([]byte, error) {
+	ffmpeg, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return nil, fmt.Errorf("%s へのエンコードには ffmpeg が必要です: %w", codec.Name, err)
+	}
+	pcmFormat, err := rawPCMFormat(w.Format)
+	if err != nil {
+		return nil, err
+	}
+	if bitrate == "" {
+		bitrate = codec.DefaultBitrate
+	}
+
+	// MP4 コンテナなどは標準出力へ書き出せないため、一時ファイルを経由する
+	out, err := os.CreateTemp("", "prototypus-*"+codec.Ext)
+	if err != nil {
+		return nil, fmt.Errorf("エンコード結果の一時ファイル作成に失敗しました: %w", err)
+	}
+	outPath := out.Name()
+	out.Close()
+	defer os.Remove(outPath)
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error", "-y",
+		"-f", pcmFormat,
+		"-ar", strconv.Itoa(int(w.Format.SampleRate)),
+		"-ac", strconv.Itoa(int(w.Format.Channels)),
+		"-i", "pipe:0",
+	}
+	args = append(args, codec.encoderArgs...)
+	args = append(args, "-b:a", bitrate, outPath)
+
+	cmd := exec.CommandContext(ctx, ffmpeg, args...)
+	cmd.Stdin = bytes.NewReader(w.Data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s へのエンコードに失敗しました: %w: %s", codec.Name, err, strings.TrimSpace(stderr.String()))
+	}
+
+	encoded, err := os.ReadFile(outPath)
+	if err != nil {
+		return nil, fmt.Errorf("エンコード結果の読み込みに失敗しました: %w", err)
+	}
+	return encoded, nil
+}
+
+// ParseBitrate は ffmpeg 形式のビットレート (例: "64k", "1.5M", "96000") をビット毎秒に変換します。
+func ParseBitrate(s string) (int, error) {
+	num := strings.TrimSpace(s)
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(num, "k"), strings.HasSuffix(num, "K"):
+		multiplier, num = 1e3, num[:len(num)-1]
+	case strings.HasSuffix(num, "M"):
+		multiplier, num = 1e6, num[:len(num)-1]
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("ビットレートの形式が不正です (例: 64k): '%s'", s)
+	}
+	return int(v * multiplier), nil
+}
+
+// rawPCMFormat は fmt チャンクのビット深度に対応する ffmpeg の raw PCM 形式名を返します。
+func rawPCMFormat(f Format) (string, error) {
+	switch f.BitsPerSample {
+	case 8:
+		return "u8", nil
+	case 16:
+		return "s16le", nil
+	case 24:
+		return "s24le", nil
+	case 32:
+		return "s32le", nil
+	default:
+		return "", fmt.Errorf("未対応のビット深度です: %d", f.BitsPerSample)
+	}
+}
@@ -0,0 +1,205 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+const (
+	// riffHeaderSize は "RIFF" + サイズ + "WAVE" のバイト数です。
+	riffHeaderSize = 12
+	// chunkHeaderSize はチャンクID + チャンクサイズのバイト数です。
+	chunkHeaderSize = 8
+	// fmtChunkMinSize は PCM の fmt チャンクに最低限必要なバイト数です。
+	fmtChunkMinSize = 16
+	// wavHeaderSize は Encode が出力する標準的な44バイトヘッダーのサイズです。
+	wavHeaderSize = riffHeaderSize + chunkHeaderSize + fmtChunkMinSize + chunkHeaderSize
+
+	// formatPCM はリニアPCMを示す AudioFormat 値です。
+	formatPCM = 1
+)
+
+// ErrInvalidWAV は入力がWAVとして解釈できない場合に返されます。
+var ErrInvalidWAV = errors.New("不正なWAVデータです")
+
+// Format は WAV の fmt チャンクが保持するフォーマット情報です。
+type Format struct {
+	AudioFormat   uint16
+	Channels      uint16
+	SampleRate    uint32
+	ByteRate      uint32
+	BlockAlign    uint16
+	BitsPerSample uint16
+}
+
+// WAV は解析済みのWAVデータ（フォーマット情報とPCM本体）を保持します。
+type WAV struct {
+	Format Format
+	Data   []byte
+}
+
+// ParseWAV は RIFF/WAVE 形式のバイト列から fmt チャンクと data チャンクを取り出します。
+func ParseWAV(b []byte) (*WAV, error) {
+	if len(b) < riffHeaderSize || string(b[0:4]) != "RIFF" || string(b[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("%w: RIFF/WAVEヘッダーが見つかりません", ErrInvalidWAV)
+	}
+
+	var (
+		w       WAV
+		hasFmt  bool
+		hasData bool
+	)
+	offset := riffHeaderSize
+	for offset+chunkHeaderSize <= len(b) {
+		id := string(b[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(b[offset+4 : offset+8]))
+		body := offset + chunkHeaderSize
+		end := body + size
+		if end > len(b) || end < body {
+			// VOICEVOX 以外のツールがサイズを不正確に書くことがあるため、data チャンクのみ末尾まで許容する
+			if id != "data" {
+				return nil, fmt.Errorf("%w: チャンク '%s' のサイズが不正です", ErrInvalidWAV, id)
+			}
+			end = len(b)
+		}
+
+		switch id {
+		case "fmt ":
+			if size < fmtChunkMinSize {
+				return nil, fmt.Errorf("%w: fmtチャンクが短すぎます (%dバイト)", ErrInvalidWAV, size)
+			}
+			w.Format = parseFormat(b[body : body+fmtChunkMinSize])
+			hasFmt = true
+		case "data":
+			w.Data = b[body:end]
+			hasData = true
+		}
+
+		// チャンクは2バイト境界に揃えられる
+		offset = end + (end-body)%2
+	}
+
+	if !hasFmt {
+		return nil, fmt.Errorf("%w: fmtチャンクがありません", ErrInvalidWAV)
+	}
+	if !hasData {
+		return nil, fmt.Errorf("%w: dataチャンクがありません", ErrInvalidWAV)
+	}
+	if w.Format.AudioFormat != formatPCM {
+		return nil, fmt.Errorf("%w: リニアPCM以外のフォーマットには対応していません (AudioFormat=%d)", ErrInvalidWAV, w.Format.AudioFormat)
+	}
+	if w.Format.Channels == 0 || w.Format.BlockAlign == 0 {
+		return nil, fmt.Errorf("%w: チャンネル数またはブロックアラインが0です", ErrInvalidWAV)
+	}
+	switch w.Format.BitsPerSample {
+	case 8, 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("%w: 未対応のビット深度です (%d bit)", ErrInvalidWAV, w.Format.BitsPerSample)
+	}
+
+	return &w, nil
+}
+
+// parseFormat は fmt チャンク本体の先頭16バイトを Format に変換します。
+func parseFormat(b []byte) Format {
+	return Format{
+		AudioFormat:   binary.LittleEndian.Uint16(b[0:2]),
+		Channels:      binary.LittleEndian.Uint16(b[2:4]),
+		SampleRate:    binary.LittleEndian.Uint32(b[4:8]),
+		ByteRate:      binary.LittleEndian.Uint32(b[8:12]),
+		BlockAlign:    binary.LittleEndian.Uint16(b[12:14]),
+		BitsPerSample: binary.LittleEndian.Uint16(b[14:16]),
+	}
+}
+
+// Encode は Format と PCM データから RIFF/WAVE 形式のバイト列を組み立てます。
+func Encode(f Format, pcm []byte) []byte {
+	buf := make([]byte, 0, wavHeaderSize+len(pcm))
+	buf = append(buf, EncodeHeader(f, len(pcm))...)
+	return append(buf, pcm...)
+}
+
+// EncodeHeader は dataSize バイトの PCM データが続く、RIFF/WAVE 形式の44バイトのヘッダーを組み立てます。
+// PCM を連結せずに順に書き込む場合に使います。
+func EncodeHeader(f Format, dataSize int) []byte {
+	buf := make([]byte, wavHeaderSize)
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(wavHeaderSize-chunkHeaderSize+dataSize))
+	copy(buf[8:12], "WAVE")
+
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], fmtChunkMinSize)
+	binary.LittleEndian.PutUint16(buf[20:22], f.AudioFormat)
+	binary.LittleEndian.PutUint16(buf[22:24], f.Channels)
+	binary.LittleEndian.PutUint32(buf[24:28], f.SampleRate)
+	binary.LittleEndian.PutUint32(buf[28:32], f.ByteRate)
+	binary.LittleEndian.PutUint16(buf[32:34], f.BlockAlign)
+	binary.LittleEndian.PutUint16(buf[34:36], f.BitsPerSample)
+
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(dataSize))
+	return buf
+}
+
+// Bytes は WAV を RIFF/WAVE 形式のバイト列に変換します。
+func (w *WAV) Bytes() []byte {
+	return Encode(w.Format, w.Data)
+}
+
+// bytesPerSample は1サンプル（1チャンネル分）のバイト数を返します。
+func (f Format) bytesPerSample() int {
+	return int(f.BitsPerSample) / 8
+}
+
+// NumSamples はチャンネルを区別しない総サンプル数を返します。
+func (w *WAV) NumSamples() int {
+	return len(w.Data) / w.Format.bytesPerSample()
+}
+
+// NumFrames はフレーム数（全チャンネル分のサンプルの組）を返します。
+func (w *WAV) NumFrames() int {
+	return len(w.Data) / int(w.Format.BlockAlign)
+}
+
+// Duration は PCM データの再生時間（秒）を返します。
+func (w *WAV) Duration() float64 {
+	if w.Format.SampleRate == 0 {
+		return 0
+	}
+	return float64(w.NumFrames()) / float64(w.Format.SampleRate)
+}
+
+// Sample は i 番目のサンプルを -1.0〜1.0 に正規化した値で返します。
+func (w *WAV) Sample(i int) float64 {
+	n := w.Format.bytesPerSample()
+	p := w.Data[i*n : i*n+n]
+	switch w.Format.BitsPerSample {
+	case 8:
+		// 8bit PCM は符号なし
+		return (float64(p[0]) - 128) / 128
+	case 16:
+		return float64(int16(binary.LittleEndian.Uint16(p))) / math.MaxInt16
+	case 24:
+		v := int32(p[0]) | int32(p[1])<<8 | int32(int8(p[2]))<<16
+		return float64(v) / (1 << 23)
+	default:
+		return float64(int32(binary.LittleEndian.Uint32(p))) / math.MaxInt32
+	}
+}
+
+// Split は PCM をフレーム境界で framesPerChunk フレームごとに分割します。
+// 最後のチャンクは framesPerChunk より短くなる場合があります。
+func (w *WAV) Split(framesPerChunk int) []*WAV {
+	if framesPerChunk <= 0 {
+		return []*WAV{w}
+	}
+	chunkBytes := framesPerChunk * int(w.Format.BlockAlign)
+	var chunks []*WAV
+	for start := 0; start < len(w.Data); start += chunkBytes {
+		end := min(start+chunkBytes, len(w.Data))
+		chunks = append(chunks, &WAV{Format: w.Format, Data: w.Data[start:end]})
+	}
+	return chunks
+}
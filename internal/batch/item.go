@@ -0,0 +1,146 @@
+package batch
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+)
+
+// trackingParams はURL正規化時に除去する計測用クエリパラメータです。
+var trackingParams = []string{"fbclid", "gclid", "yclid", "mc_cid", "mc_eid", "ref"}
+
+// Item はバッチで処理する1件の入力です。
+type Item struct {
+	// Source はリストに記載された入力元 (URL またはファイルパス) です。
+	Source string
+	// Key は重複判定に使うキーです。URLは正規化後のURL、ファイルは内容のハッシュから算出します。
+	Key string
+	// IsURL は Source がURLかどうかを表します。
+	IsURL bool
+	// Priority はリストの行頭の "[high]" のような指定から取り出した優先度の名前です。指定が無い場合は空文字です。
+	Priority string
+}
+
+// Name は出力ファイル名に使う、キーから算出した短い識別子を返します。
+func (it Item) Name() string {
+	sum := sha256.Sum256([]byte(it.Key))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// ReadList は1行1件の入力リストを読み込みます。空行と '#' で始まる行は無視します。
+func ReadList(r io.Reader) ([]string, error) {
+	var sources []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		sources = append(sources, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("入力リストの読み込みに失敗しました: %w", err)
+	}
+	return sources, nil
+}
+
+// NewItem は入力元から重複判定キーを算出して Item を生成します。
+// URLの場合は正規化したURLを、ファイルの場合は内容のSHA-256をキーとします。
+// 行頭の "[high] " のような角括弧の指定は優先度として取り出します。
+func NewItem(line string) (Item, error) {
+	priority, source := splitPriority(line)
+	if isURL(source) {
+		normalized, err := NormalizeURL(source)
+		if err != nil {
+			return Item{}, err
+		}
+		return Item{Source: source, Key: "url:" + normalized, IsURL: true, Priority: priority}, nil
+	}
+
+	content, err := os.ReadFile(source)
+	if err != nil {
+		return Item{}, fmt.Errorf("入力ファイルの読み込みに失敗しました (%s): %w", source, err)
+	}
+	sum := sha256.Sum256(content)
+	return Item{Source: source, Key: "sha256:" + hex.EncodeToString(sum[:]), Priority: priority}, nil
+}
+
+// splitPriority は行頭の "[優先度]" の指定を取り出し、残りの入力元とともに返します。指定が無い場合は空文字を返します。
+func splitPriority(line string) (priority, source string) {
+	rest, ok := strings.CutPrefix(line, "[")
+	if !ok {
+		return "", line
+	}
+	priority, source, ok = strings.Cut(rest, "]")
+	if !ok {
+		return "", line
+	}
+	return strings.ToLower(strings.TrimSpace(priority)), strings.TrimSpace(source)
+}
+
+// NormalizeURL は同じ記事を指すURLが同一の文字列になるよう正規化します。
+// スキーム・ホストの小文字化、既定ポートとフラグメントの除去、計測用パラメータの除去、
+// クエリのソート、末尾スラッシュの除去を行います。
+func NormalizeURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("URLの解析に失敗しました (%s): %w", raw, err)
+	}
+	if u.Host == "" {
+		return "", fmt.Errorf("URLにホストが含まれていません: %s", raw)
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Hostname())
+	if port := u.Port(); port != "" && !(u.Scheme == "http" && port == "80") && !(u.Scheme == "https" && port == "443") {
+		host += ":" + port
+	}
+	u.Host = host
+	u.Fragment = ""
+	u.RawFragment = ""
+	u.User = nil
+
+	query := u.Query()
+	for key := range query {
+		if strings.HasPrefix(strings.ToLower(key), "utm_") || slices.Contains(trackingParams, strings.ToLower(key)) {
+			query.Del(key)
+		}
+	}
+	// url.Values.Encode はキー順にソートして出力する
+	u.RawQuery = query.Encode()
+
+	if u.Path != "/" {
+		u.Path = strings.TrimSuffix(u.Path, "/")
+	}
+	u.RawPath = ""
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u.String(), nil
+}
+
+// Dedupe は同じキーを持つ入力を除外し、最初に現れたものだけを残します。除外した入力は duplicates に返します。
+func Dedupe(items []Item) (unique []Item, duplicates []Item) {
+	seen := make(map[string]bool, len(items))
+	for _, it := range items {
+		if seen[it.Key] {
+			duplicates = append(duplicates, it)
+			continue
+		}
+		seen[it.Key] = true
+		unique = append(unique, it)
+	}
+	return unique, duplicates
+}
+
+// isURL は入力元が http(s) のURLかを判定します。
+func isURL(source string) bool {
+	lower := strings.ToLower(source)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
@@ -0,0 +1,80 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ManifestEntry は処理済みの入力1件の記録です。
+type ManifestEntry struct {
+	Key         string    `json:"key"`
+	Source      string    `json:"source"`
+	Output      string    `json:"output"`
+	ProcessedAt time.Time `json:"processed_at"`
+}
+
+// Manifest はバッチで処理済みの入力を記録する出力マニフェストです。増分実行時の照合に使います。
+type Manifest struct {
+	Entries map[string]ManifestEntry `json:"entries"`
+}
+
+// LoadManifest はマニフェストを読み込みます。ファイルが存在しない場合は空のマニフェストを返します。
+func LoadManifest(path string) (*Manifest, error) {
+	m := &Manifest{Entries: make(map[string]ManifestEntry)}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return m, nil
+		}
+		return nil, fmt.Errorf("マニフェストの読み込みに失敗しました (%s): %w", path, err)
+	}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, fmt.Errorf("マニフェストの解析に失敗しました (%s): %w", path, err)
+	}
+	if m.Entries == nil {
+		m.Entries = make(map[string]ManifestEntry)
+	}
+	return m, nil
+}
+
+// Processed は key の入力が処理済みかを返します。
+func (m *Manifest) Processed(key string) (ManifestEntry, bool) {
+	entry, ok := m.Entries[key]
+	return entry, ok
+}
+
+// Record は入力を処理済みとして記録します。
+func (m *Manifest) Record(it Item, output string) {
+	m.Entries[it.Key] = ManifestEntry{
+		Key:         it.Key,
+		Source:      it.Source,
+		Output:      output,
+		ProcessedAt: time.Now(),
+	}
+}
+
+// Save はマニフェストを書き込みます。途中で中断しても壊れたファイルが残らないよう、一時ファイル経由で置き換えます。
+func (m *Manifest) Save(path string) error {
+	body, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("マニフェストのエンコードに失敗しました: %w", err)
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("マニフェストのディレクトリ作成に失敗しました (%s): %w", dir, err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, body, 0o644); err != nil {
+		return fmt.Errorf("マニフェストの書き込みに失敗しました (%s): %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("マニフェストの置き換えに失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
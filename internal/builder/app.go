@@ -2,12 +2,12 @@ package builder
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
 
-	"github.com/shouni/go-http-kit/httpkit"
-
+	"prototypus-ai-doc-go/internal/adapters"
 	"prototypus-ai-doc-go/internal/app"
 	"prototypus-ai-doc-go/internal/config"
 )
@@ -25,23 +25,17 @@ func BuildContainer(ctx context.Context, cfg *config.Config) (*app.Container, er
 		}
 	}()
 
-	rio, err := buildRemoteIO(ctx)
+	rio, err := buildRemoteIO(ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize IO components: %w", err)
 	}
 	resources = append(resources, rio)
 
-	timeout := cfg.HTTPTimeout
-	if timeout == 0 {
-		timeout = config.DefaultHTTPTimeout
+	httpClient, err := adapters.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize HTTP client: %w", err)
 	}
 
-	httpClient := httpkit.New(
-		timeout,
-		httpkit.WithMaxRetries(1),
-		httpkit.WithSkipNetworkValidation(true),
-	)
-
 	appCtx := &app.Container{
 		Config:     cfg,
 		RemoteIO:   rio,
@@ -56,3 +50,68 @@ func BuildContainer(ctx context.Context, cfg *config.Config) (*app.Container, er
 
 	return appCtx, nil
 }
+
+// BuildVoiceContainer は AI クライアントを初期化せず、音声合成に必要な依存関係のみを組み立てた app.Container を返します。
+func BuildVoiceContainer(ctx context.Context, cfg *config.Config) (*app.Container, error) {
+	rio, err := buildRemoteIO(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize IO components: %w", err)
+	}
+
+	engine, err := adapters.NewVoiceEngine(cfg, rio.Writer)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to initialize voice engine: %w", err), rio.Close())
+	}
+
+	return &app.Container{
+		Config:   cfg,
+		RemoteIO: rio,
+		Engine:   engine,
+	}, nil
+}
+
+// BuildPublishContainer は AI クライアントを初期化せず、既存のスクリプトを公開 (音声合成) する PublishRunner を組み立てた app.Container を返します。
+func BuildPublishContainer(ctx context.Context, cfg *config.Config) (*app.Container, error) {
+	rio, err := buildRemoteIO(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize IO components: %w", err)
+	}
+
+	appCtx := &app.Container{
+		Config:   cfg,
+		RemoteIO: rio,
+	}
+	publisher, err := buildPublishRunner(ctx, appCtx)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to build publisher: %w", err), rio.Close())
+	}
+	appCtx.Publisher = publisher
+
+	return appCtx, nil
+}
+
+// BuildPromptContainer は AI クライアントと音声合成を初期化せず、プロンプトの組み立てに必要な依存関係のみを組み立てた app.Container を返します (--print-prompt)。
+// API キーが未設定でも使えます。
+func BuildPromptContainer(ctx context.Context, cfg *config.Config) (*app.Container, error) {
+	rio, err := buildRemoteIO(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize IO components: %w", err)
+	}
+	httpClient, err := adapters.NewHTTPClient(cfg)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to initialize HTTP client: %w", err), rio.Close())
+	}
+
+	appCtx := &app.Container{
+		Config:     cfg,
+		RemoteIO:   rio,
+		HTTPClient: httpClient,
+	}
+	prompter, err := newGenerateRunner(appCtx, nil)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("failed to build prompt runner: %w", err), rio.Close())
+	}
+	appCtx.Prompter = prompter
+
+	return appCtx, nil
+}
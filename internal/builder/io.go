@@ -5,13 +5,17 @@ import (
 	"fmt"
 	"log/slog"
 
+	"github.com/shouni/go-remote-io/remoteio"
 	"github.com/shouni/go-remote-io/remoteio/gcs"
 
+	"prototypus-ai-doc-go/internal/adapters"
 	"prototypus-ai-doc-go/internal/app"
+	"prototypus-ai-doc-go/internal/config"
 )
 
 // buildRemoteIO は、GCS ベースの I/O コンポーネントを初期化します。
-func buildRemoteIO(ctx context.Context) (*app.RemoteIO, error) {
+// 出力先が s3:// の場合は、s3:// への書き込みを S3 互換ストレージに振り分ける Writer を組み合わせます。
+func buildRemoteIO(ctx context.Context, cfg *config.Config) (*app.RemoteIO, error) {
 	factory, err := gcs.New(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCS factory: %w", err)
@@ -33,6 +37,13 @@ func buildRemoteIO(ctx context.Context) (*app.RemoteIO, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output writer: %w", err)
 	}
+	if remoteio.IsS3URI(cfg.VoicevoxOutput) || remoteio.IsS3URI(cfg.OutputFile) {
+		var s3Writer *adapters.S3Writer
+		if s3Writer, err = adapters.NewS3Writer(w, cfg.S3Endpoint); err != nil {
+			return nil, fmt.Errorf("failed to create S3 output writer: %w", err)
+		}
+		w = s3Writer
+	}
 
 	return &app.RemoteIO{
 		Factory: factory,
@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 
-	"github.com/shouni/go-web-exact/v2/extract"
-
 	"prototypus-ai-doc-go/internal/adapters"
+	"prototypus-ai-doc-go/internal/ai"
 	"prototypus-ai-doc-go/internal/app"
 	"prototypus-ai-doc-go/internal/domain"
 	"prototypus-ai-doc-go/internal/pipeline"
+	"prototypus-ai-doc-go/internal/retryqueue"
 	"prototypus-ai-doc-go/internal/runner"
+	"prototypus-ai-doc-go/internal/scriptversion"
 )
 
 // buildPipeline は、提供されたランナーを使用して新しいパイプラインを初期化して返します。
@@ -31,33 +32,70 @@ func buildPipeline(ctx context.Context, appCtx *app.Container) (domain.Pipeline,
 
 // buildGenerateRunner は、GenerateRunner のインスタンスを返します。
 func buildGenerateRunner(ctx context.Context, appCtx *app.Container) (domain.GenerateRunner, error) {
-	extractor, err := extract.NewExtractor(appCtx.HTTPClient)
+	aiClient, err := adapters.NewAIAdapter(ctx, appCtx.Config)
+	if err != nil {
+		return nil, err
+	}
+	generateRunner, err := newGenerateRunner(appCtx, aiClient)
 	if err != nil {
-		return nil, fmt.Errorf("エクストラクタの初期化に失敗しました: %w", err)
+		return nil, err
 	}
+	return generateRunner, nil
+}
+
+// newGenerateRunner は aiClient でスクリプトを生成する GenerateRunner を組み立てます。
+// aiClient が nil の場合は、プロンプトの組み立て (GenerateRunner.Prompt) にのみ使えます。
+func newGenerateRunner(appCtx *app.Container, aiClient ai.Generator) (*runner.GenerateRunner, error) {
+	extractor := adapters.NewArticleAdapter(appCtx.HTTPClient)
 
-	promptBuilder, err := adapters.NewPromptAdapter()
+	promptBuilder, err := adapters.NewPromptAdapter(appCtx.Config)
 	if err != nil {
 		return nil, fmt.Errorf("プロンプトビルダーの作成に失敗しました: %w", err)
 	}
 
-	aiClient, err := adapters.NewAIAdapter(ctx, appCtx.Config)
-	if err != nil {
-		return nil, err
+	var retryQueue *retryqueue.Queue
+	if appCtx.Config.RetryQueueDir != "" {
+		retryQueue = retryqueue.New(appCtx.Config.RetryQueueDir)
 	}
 
-	return runner.NewGenerateRunner(
+	generateRunner := runner.NewGenerateRunner(
 		appCtx.Config,
 		extractor,
 		promptBuilder,
 		aiClient,
 		appCtx.RemoteIO.Reader,
-	), nil
+		retryQueue,
+	)
+	if appCtx.Config.ScriptHistoryDir != "" {
+		generateRunner.WithScriptHistory(scriptversion.New(appCtx.Config.ScriptHistoryDir))
+	}
+	if appCtx.Config.GenerateMetadata {
+		generateRunner.WithMetadataWriter(appCtx.RemoteIO.Writer)
+	}
+	if appCtx.Config.EngineStyles {
+		voiceClient, err := adapters.NewVoiceClient(appCtx.Config)
+		if err != nil {
+			return nil, err
+		}
+		generateRunner.WithSpeakerLoader(voiceClient)
+	}
+	return generateRunner, nil
 }
 
 // buildPublishRunner は、PublisherRunner のインスタンスを返します。
 func buildPublishRunner(ctx context.Context, appCtx *app.Container) (domain.PublishRunner, error) {
-	voicevoxExecutor, err := adapters.NewVoiceAdapter(ctx, appCtx.HTTPClient, appCtx.RemoteIO.Writer, appCtx.Config.VoicevoxOutput)
+	audioWriter := appCtx.RemoteIO.Writer
+	if appCtx.Config.ChunkDuration > 0 {
+		audioWriter = adapters.NewChunkedWriter(audioWriter, appCtx.RemoteIO.Reader, appCtx.Config.ChunkDuration)
+	}
+	if appCtx.Config.QCReport {
+		audioWriter = adapters.NewQCWriter(audioWriter, appCtx.Config.QCStrict)
+	}
+	if appCtx.Config.FingerprintDB != "" {
+		audioWriter = adapters.NewFingerprintWriter(audioWriter, appCtx.Config.FingerprintDB)
+	}
+
+	voicevoxExecutor, err := adapters.NewVoiceAdapter(ctx, appCtx.Config, audioWriter)
 	if err != nil {
 		return nil, err
 	}
@@ -8,26 +8,153 @@ import (
 )
 
 // DefaultHTTPTimeout はHTTPリクエストのデフォルトタイムアウトを定義します。
+// DefaultHTTPMaxRetries と DefaultHTTPRetryInterval は Web ページの取得に失敗した場合のリトライ回数と最初のリトライまでの間隔の既定値です。
+// DefaultHTTPMaxRetryInterval は失敗ごとに倍に延ばすリトライの間隔の上限です。
 // DefaultModel はデフォルトの Google Gemini モデル名（例: "gemini-2.5-flash"）を指定します。
 // MinInputContentLength は入力されたコンテンツの最小バイト。
+// DefaultMaxInputChars は入力されたコンテンツの最大文字数 (ルーン数) の既定値です。
+// DefaultVoicevoxAPIURL は VOICEVOX_API_URL が未設定の場合に接続するエンジンのURLです。
 const (
-	DefaultHTTPTimeout    = 60 * time.Second
-	DefaultModel          = "gemini-2.5-flash"
-	MinInputContentLength = 10
+	DefaultHTTPTimeout          = 60 * time.Second
+	DefaultHTTPMaxRetries       = 1
+	DefaultHTTPRetryInterval    = 5 * time.Second
+	DefaultHTTPMaxRetryInterval = 30 * time.Second
+	DefaultModel                = "gemini-2.5-flash"
+	MinInputContentLength       = 10
+	DefaultMaxInputChars        = 200000
+	DefaultVoicevoxAPIURL       = "http://localhost:50021"
 )
 
+// SourceType* は --source-type で指定できる入力ソースの種類です。
+const (
+	SourceTypeNews   = "news"
+	SourceTypePaper  = "paper"
+	SourceTypeBlog   = "blog"
+	SourceTypeManual = "manual"
+)
+
+// SourceTypes は指定可能なソースタイプの一覧です。
+var SourceTypes = []string{SourceTypeNews, SourceTypePaper, SourceTypeBlog, SourceTypeManual}
+
+// AudienceLevel* は --audience で指定できる視聴者レベルです。これ以外の値は聴衆の自由記述として扱います。
+const (
+	AudienceLevelBeginner     = "beginner"
+	AudienceLevelIntermediate = "intermediate"
+	AudienceLevelExpert       = "expert"
+)
+
+// AudienceLevels は指定可能な視聴者レベルの一覧です。
+var AudienceLevels = []string{AudienceLevelBeginner, AudienceLevelIntermediate, AudienceLevelExpert}
+
 // Config はコマンドラインフラグを保持する構造体です。
 type Config struct {
-	OutputFile     string
-	Mode           string
-	VoicevoxOutput string
-	ScriptURL      string
-	ScriptFile     string
-	AIModel        string
-	HTTPTimeout    time.Duration
-
-	ProjectID    string
-	GeminiAPIKey string
+	OutputFile         string
+	Mode               string
+	VoicevoxOutput     string
+	S3Endpoint         string
+	ScriptURLs         []string
+	ScriptFile         string
+	AIModel            string
+	Temperature        float64
+	MaxOutputTokens    int
+	MaxInputChars      int
+	AutoSummarize      bool
+	EstimateCost       bool
+	PricingFile        string
+	Stream             bool
+	HTTPTimeout        time.Duration
+	HTTPMaxRetries     int
+	HTTPRetryInterval  time.Duration
+	QCReport           bool
+	QCStrict           bool
+	LoudnessGate       string
+	LoudnessGateAction string
+	SourceType         string
+	ChunkDuration      time.Duration
+	ScriptFormat       string
+	ScheduleByStyle    bool
+	MergeSegments      bool
+	SegmentDir         string
+	SegmentsOnly       bool
+	RetryQueueDir      string
+	ScriptHistoryDir   string
+	ScriptID           string
+	StructuredOutput   bool
+	StrictScript       bool
+	StrayBrackets      string
+	FormatRetries      int
+	AutoChapterSilence time.Duration
+	Bookends           bool
+	FingerprintDB      string
+	EngineCACert       string
+	EngineInsecure     bool
+	BalanceChart       string
+	Reflow             bool
+	ABPoints           string
+	Subtitle           string
+	SubtitlePrefix     bool
+	SpeakerStats       string
+	StatsOutput        string
+	Glossary           string
+	PromptFile         string
+	AuditLog           string
+	AuditLogText       bool
+	EngineRPS          float64
+	EngineConcurrency  int
+	Progress           bool
+	Priority           string
+	SegmentTimeout     time.Duration
+	SegmentTimeoutChar time.Duration
+	ReviewFormat       bool
+	ReadableOutput     bool
+	SegmentGap         time.Duration
+	GapOnSpeakerChange bool
+	SegmentMarker      bool
+	SegmentMarkerFreq  float64
+	SegmentMarkerLen   time.Duration
+	NormalizeSilence   time.Duration
+	DurationCheck      bool
+	Automate           []string
+	AllowPartial       bool
+	FailFastRate       float64
+	SavePartial        bool
+	EmbedScript        bool
+	PreciseSplit       bool
+	ForeignWords       string
+	UserDict           string
+	DryRun             bool
+	PrintPrompt        bool
+	MarkConclusion     bool
+	Title              string
+	Audience           string
+	TargetLength       int
+	CharacterProfile   string
+	EngineStyles       bool
+	ForbiddenTopics    string
+	AudioBitrate       string
+	OutputSampleRate   int
+	MaxMemory          string
+	MaxTempDisk        string
+	SpeakerConfig      string
+	SpeakerConfigMerge bool
+	RefreshSpeakers    bool
+	CrossModeCheck     bool
+	FactCheck          bool
+	GenerateMetadata   bool
+	ProfileStages      bool
+	RecordDB           string
+
+	ProjectID      string
+	GeminiAPIKey   string
+	VoicevoxAPIURL string
+
+	// ChangedFlags はコマンドラインで明示的に指定されたフラグ名の集合です。入力ファイルのフロントマターより優先されます。
+	ChangedFlags map[string]bool
+}
+
+// FlagChanged はフラグ name がコマンドラインで明示的に指定されたかを返します。
+func (c *Config) FlagChanged(name string) bool {
+	return c.ChangedFlags[name]
 }
 
 // Normalize は設定値の文字列フィールドから前後の空白を一括で削除します。
@@ -37,9 +164,55 @@ func (c *Config) Normalize() {
 	}
 	c.OutputFile = strings.TrimSpace(c.OutputFile)
 	c.VoicevoxOutput = strings.TrimSpace(c.VoicevoxOutput)
-	c.ScriptURL = strings.TrimSpace(c.ScriptURL)
+	c.S3Endpoint = strings.TrimSpace(c.S3Endpoint)
+	c.RecordDB = strings.TrimSpace(c.RecordDB)
+	c.PricingFile = strings.TrimSpace(c.PricingFile)
+	var urls []string
+	for _, u := range c.ScriptURLs {
+		if u = strings.TrimSpace(u); u != "" {
+			urls = append(urls, u)
+		}
+	}
+	c.ScriptURLs = urls
+	var automations []string
+	for _, a := range c.Automate {
+		if a = strings.TrimSpace(a); a != "" {
+			automations = append(automations, a)
+		}
+	}
+	c.Automate = automations
 	c.ScriptFile = strings.TrimSpace(c.ScriptFile)
 	c.AIModel = strings.TrimSpace(c.AIModel)
+	c.SourceType = strings.ToLower(strings.TrimSpace(c.SourceType))
+	c.ScriptFormat = strings.ToLower(strings.TrimSpace(c.ScriptFormat))
+	c.RetryQueueDir = strings.TrimSpace(c.RetryQueueDir)
+	c.SegmentDir = strings.TrimSpace(c.SegmentDir)
+	c.ScriptHistoryDir = strings.TrimSpace(c.ScriptHistoryDir)
+	c.ScriptID = strings.TrimSpace(c.ScriptID)
+	c.FingerprintDB = strings.TrimSpace(c.FingerprintDB)
+	c.EngineCACert = strings.TrimSpace(c.EngineCACert)
+	c.BalanceChart = strings.TrimSpace(c.BalanceChart)
+	c.ABPoints = strings.TrimSpace(c.ABPoints)
+	c.Subtitle = strings.TrimSpace(c.Subtitle)
+	c.SpeakerStats = strings.TrimSpace(c.SpeakerStats)
+	c.StatsOutput = strings.TrimSpace(c.StatsOutput)
+	c.ForeignWords = strings.ToLower(strings.TrimSpace(c.ForeignWords))
+	c.StrayBrackets = strings.ToLower(strings.TrimSpace(c.StrayBrackets))
+	c.UserDict = strings.TrimSpace(c.UserDict)
+	c.Glossary = strings.TrimSpace(c.Glossary)
+	c.PromptFile = strings.TrimSpace(c.PromptFile)
+	c.Title = strings.TrimSpace(c.Title)
+	c.Audience = strings.TrimSpace(c.Audience)
+	c.CharacterProfile = strings.TrimSpace(c.CharacterProfile)
+	c.ForbiddenTopics = strings.TrimSpace(c.ForbiddenTopics)
+	c.AuditLog = strings.TrimSpace(c.AuditLog)
+	c.AudioBitrate = strings.TrimSpace(c.AudioBitrate)
+	c.MaxMemory = strings.TrimSpace(c.MaxMemory)
+	c.MaxTempDisk = strings.TrimSpace(c.MaxTempDisk)
+	c.Priority = strings.ToLower(strings.TrimSpace(c.Priority))
+	c.LoudnessGate = strings.ToLower(strings.TrimSpace(c.LoudnessGate))
+	c.LoudnessGateAction = strings.ToLower(strings.TrimSpace(c.LoudnessGateAction))
+	c.SpeakerConfig = strings.TrimSpace(c.SpeakerConfig)
 }
 
 // FillDefaults は、現在の設定で空のフィールドを envCfg の値で補完します。
@@ -50,12 +223,16 @@ func (c *Config) FillDefaults(envCfg *Config) {
 	if c.GeminiAPIKey == "" {
 		c.GeminiAPIKey = envCfg.GeminiAPIKey
 	}
+	if c.VoicevoxAPIURL == "" {
+		c.VoicevoxAPIURL = envCfg.VoicevoxAPIURL
+	}
 }
 
 // LoadConfig は環境変数から設定を読み込みます。
 func LoadConfig() *Config {
 	return &Config{
-		ProjectID:    envutil.GetEnv("GCP_PROJECT_ID", ""),
-		GeminiAPIKey: envutil.GetEnv("GEMINI_API_KEY", ""),
+		ProjectID:      envutil.GetEnv("GCP_PROJECT_ID", ""),
+		GeminiAPIKey:   envutil.GetEnv("GEMINI_API_KEY", ""),
+		VoicevoxAPIURL: envutil.GetEnv("VOICEVOX_API_URL", DefaultVoicevoxAPIURL),
 	}
 }
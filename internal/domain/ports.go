@@ -15,6 +15,11 @@ type GenerateRunner interface {
 	Run(ctx context.Context) (string, error)
 }
 
+// PromptRunner は、AIを呼び出さずにスクリプト生成に使うプロンプト全文を組み立てる責務を持つインターフェースです。
+type PromptRunner interface {
+	Prompt(ctx context.Context) (string, error)
+}
+
 // PublishRunner は、生成されたスクリプトの公開処理を実行する責務を持つインターフェースです。
 type PublishRunner interface {
 	Run(ctx context.Context, scriptContent string) error
@@ -24,3 +29,20 @@ type PublishRunner interface {
 type PromptBuilder interface {
 	Build(mode string, data any) (string, error)
 }
+
+// Article は Web ページから抽出した記事です。
+type Article struct {
+	// Title はページのタイトル (og:title、無ければ <title>) です。取得できない場合は空文字です。
+	Title string
+	// Type はページの og:type (例: "article", "blog", "website") を小文字にしたものです。取得できない場合は空文字です。
+	Type string
+	// Text はタイトルを除いた本文のテキストです。
+	Text string
+	// HasBody は本文が見つかったかどうかです。false の場合、Text は空です。
+	HasBody bool
+}
+
+// ArticleExtractor は、URL から記事のタイトルと本文を取得する責務を定義します。
+type ArticleExtractor interface {
+	FetchArticle(ctx context.Context, url string) (*Article, error)
+}
@@ -0,0 +1,209 @@
+package history
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 実行結果の状態です。
+const (
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Record は1回のパイプライン実行 (生成・合成) の履歴です (--record-db)。
+type Record struct {
+	StartedAt time.Time `json:"started_at"`
+	// Command は実行したサブコマンドです (例: "generate", "speak")。
+	Command string `json:"command"`
+	// Input は入力元の URL またはファイルパスです。複数の URL は空白区切りで記録します。
+	Input  string `json:"input"`
+	Mode   string `json:"mode,omitempty"`
+	Model  string `json:"model,omitempty"`
+	Output string `json:"output,omitempty"`
+	// AudioSec は合成した音声の尺 (秒) です。音声を合成しなかった場合は 0 です。
+	AudioSec float64 `json:"audio_sec,omitempty"`
+	// PromptTokens と OutputTokens は AI モデルの入出力トークン数の合計 (コストの目安) です。
+	PromptTokens int `json:"prompt_tokens,omitempty"`
+	OutputTokens int `json:"output_tokens,omitempty"`
+	// CostUSD はトークン数と単価表 (--pricing-file) から算出した費用の見積もり (米ドル) です。単価表にモデルが無い場合は 0 です。
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// ElapsedSec は実行の所要時間 (秒) です。
+	ElapsedSec float64 `json:"elapsed_sec"`
+	Status     string  `json:"status"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// contextKey は Run を context に格納するためのキーです。
+type contextKey struct{}
+
+// Run は実行中のパイプラインの各ステージから、履歴に記録する計測値を集めます。
+type Run struct {
+	mu           sync.Mutex
+	promptTokens int
+	outputTokens int
+	audioSec     float64
+	// inputPrice と outputPrice は 100 万トークンあたりの単価 (米ドル) です。priced が false の場合は費用を記録しません。
+	inputPrice, outputPrice float64
+	priced                  bool
+}
+
+// WithRun は新しい Run を格納した context を返します。
+func WithRun(ctx context.Context) (context.Context, *Run) {
+	r := &Run{}
+	return context.WithValue(ctx, contextKey{}, r), r
+}
+
+// FromContext は context に格納された Run を返します。
+func FromContext(ctx context.Context) (*Run, bool) {
+	r, ok := ctx.Value(contextKey{}).(*Run)
+	return r, ok
+}
+
+// Tokens はこれまでに加算した AI モデルの入出力トークン数を返します。
+func (r *Run) Tokens() (prompt, output int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.promptTokens, r.outputTokens
+}
+
+// SetPrice は費用の見積もりに使う、AI モデルの 100 万トークンあたりの入出力の単価 (米ドル) を設定します。
+func (r *Run) SetPrice(input, output float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inputPrice, r.outputPrice, r.priced = input, output, true
+}
+
+// AddTokens は AI モデルの入出力トークン数を加算します。context に Run が無い場合は何もしません。
+func AddTokens(ctx context.Context, prompt, output int) {
+	if r, ok := ctx.Value(contextKey{}).(*Run); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.promptTokens += prompt
+		r.outputTokens += output
+	}
+}
+
+// AddAudio は合成した音声の尺 (秒) を加算します。context に Run が無い場合は何もしません。
+func AddAudio(ctx context.Context, seconds float64) {
+	if r, ok := ctx.Value(contextKey{}).(*Run); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.audioSec += seconds
+	}
+}
+
+// Fill は集めた計測値と実行結果を rec に反映します。
+func (r *Run) Fill(rec *Record, started time.Time, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec.StartedAt = started
+	rec.ElapsedSec = time.Since(started).Seconds()
+	rec.PromptTokens = r.promptTokens
+	rec.OutputTokens = r.outputTokens
+	if r.priced {
+		rec.CostUSD = (float64(r.promptTokens)*r.inputPrice + float64(r.outputTokens)*r.outputPrice) / 1_000_000
+	}
+	rec.AudioSec = r.audioSec
+	rec.Status = StatusSucceeded
+	if err != nil {
+		rec.Status = StatusFailed
+		rec.Error = err.Error()
+	}
+}
+
+// Append は履歴を path の JSON Lines 形式のデータベースに1行追記します。ファイルが無い場合は作成します。
+func Append(path string, rec Record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("実行履歴のエンコードに失敗しました: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("実行履歴のディレクトリ作成に失敗しました (%s): %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("実行履歴のオープンに失敗しました (%s): %w", path, err)
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	if err := errors.Join(writeErr, f.Close()); err != nil {
+		return fmt.Errorf("実行履歴の書き込みに失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
+
+// Load は path のデータベースから全ての履歴を記録順に読み込みます。ファイルが無い場合は空の一覧を返します。
+// 書き込み途中で中断されたなどで解析できない行は読み飛ばします。
+func Load(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("実行履歴のオープンに失敗しました (%s): %w", path, err)
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var rec Record
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("実行履歴の読み込みに失敗しました (%s): %w", path, err)
+	}
+	return records, nil
+}
+
+// Query は履歴の検索条件です。ゼロ値の条件は絞り込みに使いません。
+type Query struct {
+	// Text は入力・出力パス・エラーに含まれる文字列です。英字の大文字・小文字は区別しません。
+	Text   string
+	Mode   string
+	Model  string
+	Status string
+	// Since より前に開始した実行は除外します。
+	Since time.Time
+	// Limit は新しい順に返す件数の上限です。
+	Limit int
+}
+
+// Search は条件に一致する履歴を新しい順に返します。
+func Search(records []Record, q Query) []Record {
+	text := strings.ToLower(q.Text)
+	var result []Record
+	for i := len(records) - 1; i >= 0; i-- {
+		rec := records[i]
+		switch {
+		case q.Mode != "" && rec.Mode != q.Mode,
+			q.Model != "" && rec.Model != q.Model,
+			q.Status != "" && rec.Status != q.Status,
+			!q.Since.IsZero() && rec.StartedAt.Before(q.Since),
+			text != "" && !strings.Contains(strings.ToLower(rec.Input+"\n"+rec.Output+"\n"+rec.Error), text):
+			continue
+		}
+		result = append(result, rec)
+		if q.Limit > 0 && len(result) == q.Limit {
+			break
+		}
+	}
+	return result
+}
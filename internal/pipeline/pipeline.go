@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"prototypus-ai-doc-go/internal/domain"
+	"prototypus-ai-doc-go/internal/profile"
 )
 
 // Pipeline はパイプラインの実行に必要な外部依存関係を保持するサービス構造体です。
@@ -46,7 +47,9 @@ func (p *Pipeline) Execute(
 func (p *Pipeline) generate(
 	ctx context.Context,
 ) (string, error) {
+	stop := profile.Start(ctx, "generate")
 	generatedScript, err := p.generator.Run(ctx)
+	stop()
 	if err != nil {
 		return "", fmt.Errorf("スクリプトテキスト作成に失敗しました: %w", err)
 	}
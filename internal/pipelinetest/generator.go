@@ -0,0 +1,75 @@
+package pipelinetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"google.golang.org/genai"
+
+	"prototypus-ai-doc-go/internal/ai"
+	"prototypus-ai-doc-go/internal/history"
+)
+
+// FakeGenerator は実際の AI モデルを呼び出さずに、あらかじめ用意した応答を返す ai.Generator です。
+type FakeGenerator struct {
+	// Responses は呼び出しごとに順に返す応答です。使い切った後は最後の応答を返し続けます。
+	Responses []string
+	// Respond が設定されている場合、Responses の代わりにプロンプトから応答を生成します。
+	Respond func(prompt string) (string, error)
+	// Err が設定されている場合、すべての呼び出しでこのエラーを返します。
+	Err error
+
+	mu      sync.Mutex
+	prompts []string
+	models  []string
+}
+
+// GenerateContent は ai.Generator を実装します。
+// 実際のクライアントと同様に、入出力のトークン数を history に加算します (文字数からの概算です)。
+func (g *FakeGenerator) GenerateContent(ctx context.Context, modelName string, prompt string) (*ai.Response, error) {
+	text, err := g.next(modelName, prompt)
+	if err != nil {
+		return nil, err
+	}
+	history.AddTokens(ctx, ai.EstimateTokens(prompt), ai.EstimateTokens(text))
+	return &ai.Response{Text: text, FinishReason: genai.FinishReasonStop}, nil
+}
+
+// GenerateStructured は ai.Generator を実装します。構造化出力には対応せず、常にエラーを返して通常の生成へフォールバックさせます。
+func (g *FakeGenerator) GenerateStructured(context.Context, string, string, *genai.Schema) (*ai.Response, error) {
+	return nil, errors.New("FakeGenerator は構造化出力に対応していません")
+}
+
+// Prompts はこれまでに受け取ったプロンプトを呼び出し順に返します。
+func (g *FakeGenerator) Prompts() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.prompts...)
+}
+
+// Models はこれまでの呼び出しで指定されたモデル名を呼び出し順に返します。
+func (g *FakeGenerator) Models() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.models...)
+}
+
+// next はモデル名とプロンプトを記録し、次の応答を返します。
+func (g *FakeGenerator) next(modelName, prompt string) (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	calls := len(g.prompts)
+	g.prompts = append(g.prompts, prompt)
+	g.models = append(g.models, modelName)
+
+	switch {
+	case g.Err != nil:
+		return "", g.Err
+	case g.Respond != nil:
+		return g.Respond(prompt)
+	case len(g.Responses) == 0:
+		return "", errors.New("FakeGenerator に応答が設定されていません")
+	}
+	return g.Responses[min(calls, len(g.Responses)-1)], nil
+}
@@ -0,0 +1,94 @@
+package pipelinetest
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+
+	"prototypus-ai-doc-go/internal/adapters"
+	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/domain"
+	"prototypus-ai-doc-go/internal/pipeline"
+	"prototypus-ai-doc-go/internal/runner"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+const (
+	// DefaultInputPath は Harness が入力ファイルとして読み込むパスの既定値です。
+	DefaultInputPath = "input.md"
+	// DefaultOutputPath は Harness が合成した音声を書き込むパスの既定値です。
+	DefaultOutputPath = "output.wav"
+)
+
+// FakeArticles は URL をキーに記事を返す domain.ArticleExtractor です。
+type FakeArticles map[string]*domain.Article
+
+// FetchArticle は domain.ArticleExtractor を実装します。
+func (a FakeArticles) FetchArticle(_ context.Context, url string) (*domain.Article, error) {
+	article, ok := a[url]
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", url, fs.ErrNotExist)
+	}
+	return article, nil
+}
+
+// Harness は実際の AI モデル・VOICEVOX エンジン・ストレージを使わずに、生成から音声合成までのパイプラインを実行します。
+// プロンプトは埋め込みのテンプレート (または Config.PromptFile) から実際に組み立てるため、モードごとの差異も再現できます。
+// 合成後のスループットはユーザーのキャッシュディレクトリに記録されるため、テストでは XDG_CACHE_HOME などを一時ディレクトリに向けてください。
+type Harness struct {
+	Config      *config.Config
+	Generator   *FakeGenerator
+	Synthesizer *FakeSynthesizer
+	Storage     *MemoryStorage
+	Articles    FakeArticles
+	// Engine は合成エンジンの動作オプションです。
+	Engine voicevox.EngineConfig
+}
+
+// NewHarness は mode で生成し、DefaultInputPath の入力から DefaultOutputPath に音声を出力する Harness を生成します。
+// Execute の前に Input で入力本文を、Generator に AI の応答を設定してください。
+func NewHarness(mode string) *Harness {
+	return &Harness{
+		Config: &config.Config{
+			Mode:           mode,
+			AIModel:        "fake-model",
+			ScriptFile:     DefaultInputPath,
+			VoicevoxOutput: DefaultOutputPath,
+			ChangedFlags:   map[string]bool{},
+		},
+		Generator:   &FakeGenerator{},
+		Synthesizer: &FakeSynthesizer{},
+		Storage:     NewMemoryStorage(),
+		Articles:    FakeArticles{},
+	}
+}
+
+// Input は入力本文を Config.ScriptFile に配置します。
+func (h *Harness) Input(text string) {
+	h.Storage.Put(h.Config.ScriptFile, []byte(text))
+}
+
+// Execute は設定に従ってパイプラインを組み立てて実行します。
+func (h *Harness) Execute(ctx context.Context) error {
+	promptBuilder, err := adapters.NewPromptAdapter(h.Config)
+	if err != nil {
+		return fmt.Errorf("プロンプトビルダーの作成に失敗しました: %w", err)
+	}
+	generateRunner := runner.NewGenerateRunner(h.Config, h.Articles, promptBuilder, h.Generator, h.Storage, nil)
+	if h.Config.EngineStyles {
+		generateRunner.WithSpeakerLoader(h.Synthesizer)
+	}
+
+	var executor voicevox.EngineExecutor
+	if h.Config.VoicevoxOutput != "" {
+		executor = voicevox.NewEngine(h.Synthesizer, h.Storage, h.Engine)
+	}
+	publishRunner := runner.NewPublisherRunner(h.Config, executor, h.Storage)
+
+	return pipeline.NewPipeline(generateRunner, publishRunner).Execute(ctx)
+}
+
+// OutputWAV はパイプラインが DefaultOutputPath (Config.VoicevoxOutput) に書き込んだ音声を返します。
+func (h *Harness) OutputWAV() ([]byte, bool) {
+	return h.Storage.File(h.Config.VoicevoxOutput)
+}
@@ -0,0 +1,213 @@
+package pipelinetest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"prototypus-ai-doc-go/internal/ai"
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/history"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+const testInput = "# Go の並行処理\n\ngoroutine と channel を使うと、複数の処理を簡潔に並行実行できます。"
+
+func TestHarnessModes(t *testing.T) {
+	tests := []struct {
+		mode   string
+		script string
+	}{
+		{"solo", "[ずんだもん][ノーマル] goroutine の解説なのだ。\n[ずんだもん][あまあま] channel で値を渡すのだ。"},
+		{"duet", "[ずんだもん][ノーマル] 今日は並行処理の話なのだ。\n[めたん][ノーマル] goroutine から始めますわ。"},
+		{"dialogue", "[めたん][ノーマル] channel とは何ですの？\n[ずんだもん][ノーマル] 値を受け渡す通り道なのだ。\n[めたん][ツンツン] 最初からそう言いなさい。"},
+		{"faq", "[めたん][ノーマル] goroutine は重いですか？\n[ずんだもん][ノーマル] とても軽量なのだ。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			h := newTestHarness(t, tt.mode)
+			h.Generator.Responses = []string{tt.script}
+
+			if err := h.Execute(context.Background()); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			prompts := h.Generator.Prompts()
+			if len(prompts) != 1 {
+				t.Fatalf("generator called %d times, want 1", len(prompts))
+			}
+			if !strings.Contains(prompts[0], "goroutine と channel") {
+				t.Errorf("prompt does not contain the input text")
+			}
+
+			segments := script.Parse(tt.script)
+			if calls := h.Synthesizer.Calls(); len(calls) != len(segments) {
+				t.Fatalf("synthesizer called %d times, want %d", len(calls), len(segments))
+			}
+			got, ok := h.OutputWAV()
+			if !ok {
+				t.Fatalf("output %s was not written; files: %v", DefaultOutputPath, h.Storage.Paths())
+			}
+			if ct := h.Storage.ContentType(DefaultOutputPath); ct != "audio/wav" {
+				t.Errorf("content type = %q, want audio/wav", ct)
+			}
+			assertPCM(t, got, expectedPCM(t, h.Synthesizer, segments))
+		})
+	}
+}
+
+func TestHarnessFailingEngine(t *testing.T) {
+	const generated = "[ずんだもん][ノーマル] 最初の発話なのだ。\n[めたん][ノーマル] ここで合成に失敗しますわ。\n[ずんだもん][ノーマル] 最後の発話なのだ。"
+	// 4xx は再試行されないため、再試行の待ち時間なしに失敗が確定する
+	errEngine := &voicevox.APIError{Method: http.MethodPost, Path: "/synthesis", StatusCode: http.StatusUnprocessableEntity, Body: "invalid text"}
+	failing := func(text string, _ int) error {
+		if strings.Contains(text, "失敗") {
+			return errEngine
+		}
+		return nil
+	}
+
+	t.Run("出力を書き込まない", func(t *testing.T) {
+		h := newTestHarness(t, "duet")
+		h.Generator.Responses = []string{generated}
+		h.Synthesizer.Fail = failing
+
+		err := h.Execute(context.Background())
+		if !errors.Is(err, errEngine) {
+			t.Fatalf("Execute() error = %v, want %v", err, errEngine)
+		}
+		if paths := h.Storage.Paths(); len(paths) != 1 || paths[0] != DefaultInputPath {
+			t.Errorf("storage = %v, want only the input %s", paths, DefaultInputPath)
+		}
+	})
+
+	t.Run("失敗したセグメントを省略して出力", func(t *testing.T) {
+		h := newTestHarness(t, "duet")
+		h.Generator.Responses = []string{generated}
+		h.Synthesizer.Fail = failing
+		h.Engine.AllowPartial = true
+
+		if err := h.Execute(context.Background()); err != nil {
+			t.Fatalf("Execute() error = %v", err)
+		}
+		got, ok := h.OutputWAV()
+		if !ok {
+			t.Fatalf("output %s was not written; files: %v", DefaultOutputPath, h.Storage.Paths())
+		}
+		segments := script.Parse(generated)
+		assertPCM(t, got, expectedPCM(t, h.Synthesizer, []script.Segment{segments[0], segments[2]}))
+	})
+}
+
+func TestHarnessSynthesizerSeam(t *testing.T) {
+	h := newTestHarness(t, "solo")
+	h.Generator.Responses = []string{"[ずんだもん][ノーマル][速1.5] 話速を上げるのだ。"}
+
+	if err := h.Execute(context.Background()); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	calls := h.Synthesizer.Calls()
+	if len(calls) != 1 {
+		t.Fatalf("synthesizer called %d times, want 1", len(calls))
+	}
+	if calls[0].Text != "話速を上げるのだ。" {
+		t.Errorf("text = %q, want %q", calls[0].Text, "話速を上げるのだ。")
+	}
+	if speed := calls[0].Params.SpeedScale; speed == nil || *speed != 1.5 {
+		t.Errorf("speed scale = %v, want 1.5", speed)
+	}
+}
+
+func TestHarnessFrontMatterIsNotInput(t *testing.T) {
+	h := newTestHarness(t, "solo")
+	// フロントマターだけで最小の長さを超えても、本文が短ければ受け付けない
+	h.Input("---\ntitle: フロントマターのタイトル\nmodel: gemini-2.5-flash\n---\n短い")
+	h.Generator.Responses = []string{"[ずんだもん][ノーマル] 呼ばれないはずなのだ。"}
+
+	err := h.Execute(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "短すぎます") {
+		t.Fatalf("Execute() error = %v, want the input-too-short error", err)
+	}
+	if prompts := h.Generator.Prompts(); len(prompts) != 0 {
+		t.Errorf("generator called %d times, want 0", len(prompts))
+	}
+}
+
+func TestHarnessFrontMatterModelPrice(t *testing.T) {
+	h := newTestHarness(t, "solo")
+	h.Input("---\nmodel: gemini-2.5-flash\n---\n" + testInput)
+	h.Generator.Responses = []string{"[ずんだもん][ノーマル] goroutine の解説なのだ。"}
+	h.Config.EstimateCost = true
+
+	ctx, run := history.WithRun(context.Background())
+	if err := h.Execute(ctx); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if models := h.Generator.Models(); len(models) != 1 || models[0] != "gemini-2.5-flash" {
+		t.Fatalf("generator models = %v, want [gemini-2.5-flash]", models)
+	}
+
+	var rec history.Record
+	run.Fill(&rec, time.Now(), nil)
+	// 既定の fake-model は単価表に無いため、フロントマターのモデルの単価でのみ費用が記録される
+	want := ai.DefaultPricing["gemini-2.5-flash"].Cost(rec.PromptTokens, rec.OutputTokens)
+	if rec.PromptTokens == 0 || rec.CostUSD != want {
+		t.Errorf("cost = %v USD for %d/%d tokens, want %v", rec.CostUSD, rec.PromptTokens, rec.OutputTokens, want)
+	}
+}
+
+// newTestHarness は合成のスループットの記録先を一時ディレクトリに向け、入力を配置した Harness を返します。
+func newTestHarness(t *testing.T, mode string) *Harness {
+	t.Helper()
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	h := NewHarness(mode)
+	h.Input(testInput)
+	return h
+}
+
+// expectedPCM は segments をスクリプト順に合成した場合の PCM を、FakeSynthesizer が実際に受け取ったスタイルIDで作り直して返します。
+func expectedPCM(t *testing.T, s *FakeSynthesizer, segments []script.Segment) []byte {
+	t.Helper()
+	styles := map[string]int{}
+	for _, c := range s.Calls() {
+		styles[c.Text] = c.StyleID
+	}
+	var pcm []byte
+	for _, seg := range segments {
+		styleID, ok := styles[seg.Text]
+		if !ok {
+			t.Fatalf("segment %q was not synthesized", seg.Text)
+		}
+		b, err := (&FakeSynthesizer{}).Synthesize(context.Background(), seg.Text, styleID, voicevox.SynthesisParams{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		w, err := audio.ParseWAV(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pcm = append(pcm, w.Data...)
+	}
+	return pcm
+}
+
+// assertPCM は出力の WAV の PCM が want と一致することを確かめます。
+func assertPCM(t *testing.T, wav, want []byte) {
+	t.Helper()
+	w, err := audio.ParseWAV(wav)
+	if err != nil {
+		t.Fatalf("output is not a valid WAV: %v", err)
+	}
+	if w.Format != fakeFormat {
+		t.Errorf("format = %+v, want %+v", w.Format, fakeFormat)
+	}
+	if !bytes.Equal(w.Data, want) {
+		t.Errorf("PCM = %d bytes, want %d bytes in script order", len(w.Data), len(want))
+	}
+}
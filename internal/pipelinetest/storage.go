@@ -0,0 +1,96 @@
+package pipelinetest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// MemoryStorage はファイルをメモリ上に保持する remoteio.InputReader / remoteio.OutputWriter です。
+// 入力ファイルを事前に Put しておき、パイプラインが書き込んだ出力を File で取り出せます。
+type MemoryStorage struct {
+	mu    sync.Mutex
+	files map[string][]byte
+	types map[string]string
+}
+
+// NewMemoryStorage は空の MemoryStorage を生成します。
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{files: map[string][]byte{}, types: map[string]string{}}
+}
+
+// Put は path にファイルを配置します。
+func (s *MemoryStorage) Put(path string, content []byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = bytes.Clone(content)
+}
+
+// File は path のファイルの内容を返します。存在しない場合は false を返します。
+func (s *MemoryStorage) File(path string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	b, ok := s.files[path]
+	return bytes.Clone(b), ok
+}
+
+// ContentType は Write で path に書き込まれた際の Content-Type を返します。
+func (s *MemoryStorage) ContentType(path string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.types[path]
+}
+
+// Paths は保持しているファイルのパスを昇順で返します。
+func (s *MemoryStorage) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.files))
+	for p := range s.files {
+		paths = append(paths, p)
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// Open は remoteio.InputReader を実装します。
+func (s *MemoryStorage) Open(_ context.Context, path string) (io.ReadCloser, error) {
+	b, ok := s.File(path)
+	if !ok {
+		return nil, fmt.Errorf("%s: %w", path, fs.ErrNotExist)
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+// List は remoteio.InputReader を実装します。path 直下のファイルのみを対象にします。
+func (s *MemoryStorage) List(_ context.Context, path string, callback func(filePath string) error) error {
+	prefix := strings.TrimSuffix(path, "/") + "/"
+	for _, p := range s.Paths() {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok || strings.Contains(rest, "/") {
+			continue
+		}
+		if err := callback(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write は remoteio.OutputWriter と voicevox.AudioWriter を実装します。
+func (s *MemoryStorage) Write(_ context.Context, path string, r io.Reader, contentType string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("%s への書き込みに失敗しました: %w", path, err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[path] = b
+	s.types[path] = contentType
+	return nil
+}
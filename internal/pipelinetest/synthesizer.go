@@ -0,0 +1,84 @@
+package pipelinetest
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// fakeFormat は FakeSynthesizer が返す WAV のフォーマットです (VOICEVOX と同じ 24kHz/16bit/モノラル)。
+var fakeFormat = audio.Format{
+	AudioFormat:   1,
+	Channels:      1,
+	SampleRate:    24000,
+	ByteRate:      48000,
+	BlockAlign:    2,
+	BitsPerSample: 16,
+}
+
+// fakeDurationPerChar は FakeSynthesizer が1文字あたりに生成する音声の長さです。話速1.0の VOICEVOX におおよそ合わせています。
+const fakeDurationPerChar = 130 * time.Millisecond
+
+// SynthesisCall は FakeSynthesizer が受け取った1回の合成リクエストです。
+type SynthesisCall struct {
+	Text    string
+	StyleID int
+	Params  voicevox.SynthesisParams
+}
+
+// FakeSynthesizer は実際の VOICEVOX エンジンに接続せずに、テキスト長に応じた長さのトーンを返す voicevox.Synthesizer です。
+// 話者・スタイルは voicevox.SupportedSpeakers と既知のスタイルの組み合わせに連番のスタイルIDを割り当てます。
+type FakeSynthesizer struct {
+	// Fail が設定されている場合、合成ごとに呼び出し、エラーを返したセグメントの合成を失敗させます。
+	Fail func(text string, styleID int) error
+	// LoadErr が設定されている場合、話者一覧の取得でこのエラーを返します。
+	LoadErr error
+
+	mu    sync.Mutex
+	calls []SynthesisCall
+}
+
+// LoadSpeakers は voicevox.Synthesizer を実装します。
+func (s *FakeSynthesizer) LoadSpeakers(context.Context) (*voicevox.SpeakerData, error) {
+	if s.LoadErr != nil {
+		return nil, s.LoadErr
+	}
+	data := &voicevox.SpeakerData{StyleIDs: map[string]int{}, DefaultStyleIDs: map[string]int{}}
+	id := 0
+	for _, sp := range voicevox.SupportedSpeakers {
+		data.DefaultStyleIDs[sp.ToolTag] = id
+		for _, style := range voicevox.SupportedStyleNames() {
+			data.StyleIDs[sp.ToolTag+"["+style+"]"] = id
+			id++
+		}
+	}
+	return data, nil
+}
+
+// Synthesize は voicevox.Synthesizer を実装します。
+func (s *FakeSynthesizer) Synthesize(_ context.Context, text string, styleID int, params voicevox.SynthesisParams) ([]byte, error) {
+	s.mu.Lock()
+	s.calls = append(s.calls, SynthesisCall{Text: text, StyleID: styleID, Params: params})
+	s.mu.Unlock()
+
+	if s.Fail != nil {
+		if err := s.Fail(text, styleID); err != nil {
+			return nil, err
+		}
+	}
+	d := time.Duration(utf8.RuneCountInString(text)) * fakeDurationPerChar
+	// スタイルごとに周波数を変え、出力からどのスタイルで合成されたかを判別できるようにする
+	freq := 220 + 20*float64(styleID)
+	return audio.Encode(fakeFormat, audio.TonePCM(fakeFormat, freq, d, 0.3)), nil
+}
+
+// Calls はこれまでに受け取った合成リクエストを返します。並列合成のため、順序はスクリプトの順序と一致しない場合があります。
+func (s *FakeSynthesizer) Calls() []SynthesisCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SynthesisCall(nil), s.calls...)
+}
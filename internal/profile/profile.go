@@ -0,0 +1,63 @@
+package profile
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// contextKey は Profile を context に格納するためのキーです。
+type contextKey struct{}
+
+// Profile はパイプラインの各ステージ (生成・パース・ID解決・合成・結合・書き込みなど) の所要時間を集計します (--profile-stages)。
+// 同じ名前のステージが複数回実行された場合は所要時間を合算します。
+type Profile struct {
+	mu     sync.Mutex
+	order  []string
+	stages map[string]time.Duration
+}
+
+// WithProfile は新しい Profile を格納した context を返します。
+func WithProfile(ctx context.Context) (context.Context, *Profile) {
+	p := &Profile{stages: make(map[string]time.Duration)}
+	return context.WithValue(ctx, contextKey{}, p), p
+}
+
+// Start はステージの計測を開始し、計測を終了する関数を返します。
+// context に Profile が無い場合は何もしない関数を返すため、呼び出し側は計測の有効・無効を意識する必要はありません。
+func Start(ctx context.Context, stage string) func() {
+	p, ok := ctx.Value(contextKey{}).(*Profile)
+	if !ok {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { p.add(stage, time.Since(start)) }
+}
+
+// add はステージの所要時間を加算します。
+func (p *Profile) add(stage string, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.stages[stage]; !ok {
+		p.order = append(p.order, stage)
+	}
+	p.stages[stage] += d
+}
+
+// Log はステージ別の所要時間の内訳を、実行した順に構造化ログとして出力します。
+func (p *Profile) Log(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.order) == 0 {
+		return
+	}
+	var total time.Duration
+	args := make([]any, 0, len(p.order)*2+2)
+	for _, stage := range p.order {
+		args = append(args, stage, p.stages[stage].Round(time.Millisecond))
+		total += p.stages[stage]
+	}
+	args = append(args, "total", total.Round(time.Millisecond))
+	slog.InfoContext(ctx, "ステージ別の所要時間", args...)
+}
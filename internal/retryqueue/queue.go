@@ -0,0 +1,162 @@
+package retryqueue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	entrySuffix = ".json"
+	inputSuffix = ".txt"
+)
+
+// Options は再試行時に復元する生成オプションです。
+type Options struct {
+	Mode             string `json:"mode"`
+	AIModel          string `json:"model"`
+	OutputFile       string `json:"output_file,omitempty"`
+	VoicevoxOutput   string `json:"voicevox_output,omitempty"`
+	SourceType       string `json:"source_type,omitempty"`
+	ScriptFormat     string `json:"script_format,omitempty"`
+	StructuredOutput bool   `json:"structured_output,omitempty"`
+	Bookends         bool   `json:"bookends,omitempty"`
+	MarkConclusion   bool   `json:"mark_conclusion,omitempty"`
+}
+
+// Entry は再試行キューに保存された1件の失敗記録です。入力本文は別ファイルに保存されます。
+type Entry struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Options   Options   `json:"options"`
+}
+
+// Queue はディレクトリ上のファイルで失敗した入力を管理する再試行キューです。
+type Queue struct {
+	dir string
+}
+
+// New は dir をキューの保存先とする Queue を生成します。
+func New(dir string) *Queue {
+	return &Queue{dir: dir}
+}
+
+// EntryID はモードと入力本文からエントリのIDを算出します。同じ入力の再失敗は同じエントリに集約されます。
+func EntryID(mode string, input []byte) string {
+	h := sha256.New()
+	h.Write([]byte(mode))
+	h.Write([]byte{0})
+	h.Write(input)
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// InputPath はエントリの入力本文を保存しているファイルのパスを返します。
+func (q *Queue) InputPath(id string) string {
+	return filepath.Join(q.dir, id+inputSuffix)
+}
+
+// entryPath はエントリのメタ情報を保存しているファイルのパスを返します。
+func (q *Queue) entryPath(id string) string {
+	return filepath.Join(q.dir, id+entrySuffix)
+}
+
+// Save は失敗した入力をキューに保存します。既に同じIDのエントリがある場合は失敗回数を加算して更新します。
+func (q *Queue) Save(entry Entry, input []byte, cause error) (*Entry, error) {
+	if err := os.MkdirAll(q.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("再試行キューのディレクトリ作成に失敗しました (%s): %w", q.dir, err)
+	}
+
+	now := time.Now()
+	entry.Attempts = 1
+	entry.CreatedAt = now
+	if prev, err := q.load(entry.ID); err == nil {
+		entry.Attempts = prev.Attempts + 1
+		entry.CreatedAt = prev.CreatedAt
+		entry.Source = prev.Source
+	}
+	entry.UpdatedAt = now
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+
+	if err := os.WriteFile(q.InputPath(entry.ID), input, 0o644); err != nil {
+		return nil, fmt.Errorf("再試行キューへの入力保存に失敗しました: %w", err)
+	}
+	body, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("再試行キューのエントリのエンコードに失敗しました: %w", err)
+	}
+	if err := os.WriteFile(q.entryPath(entry.ID), body, 0o644); err != nil {
+		return nil, fmt.Errorf("再試行キューへのエントリ保存に失敗しました: %w", err)
+	}
+	return &entry, nil
+}
+
+// List はキューに保存されているエントリを古い順に返します。キューが存在しない場合は空を返します。
+func (q *Queue) List() ([]Entry, error) {
+	files, err := os.ReadDir(q.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("再試行キューの読み込みに失敗しました (%s): %w", q.dir, err)
+	}
+
+	var entries []Entry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), entrySuffix) {
+			continue
+		}
+		entry, err := q.load(strings.TrimSuffix(f.Name(), entrySuffix))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return entries, nil
+}
+
+// Remove はエントリと入力本文をキューから削除します。
+func (q *Queue) Remove(id string) error {
+	return errors.Join(
+		removeIfExists(q.entryPath(id)),
+		removeIfExists(q.InputPath(id)),
+	)
+}
+
+// load は ID に対応するエントリを読み込みます。
+func (q *Queue) load(id string) (*Entry, error) {
+	body, err := os.ReadFile(q.entryPath(id))
+	if err != nil {
+		return nil, err
+	}
+	var entry Entry
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return nil, fmt.Errorf("再試行キューのエントリ解析に失敗しました (%s): %w", id, err)
+	}
+	return &entry, nil
+}
+
+// removeIfExists はファイルを削除します。存在しない場合はエラーにしません。
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("ファイルの削除に失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package runner
+
+import (
+	"strings"
+
+	"prototypus-ai-doc-go/internal/config"
+)
+
+// audienceProfile は視聴者レベルごとにプロンプトへ渡す聴衆の表示名と、説明の詳しさの指示です。
+type audienceProfile struct {
+	label string
+	guide string
+}
+
+// audienceProfiles は config.AudienceLevel* に対応するプロファイルの定義です。
+var audienceProfiles = map[string]audienceProfile{
+	config.AudienceLevelBeginner: {
+		label: "初心者 (この分野を学び始めたばかりの人)",
+		guide: "専門用語や略語が初めて出てきたときは、身近な例えを交えた短い補足を必ず添えてください。前提となる背景から順を追って説明し、一度に多くの概念を詰め込まないでください。",
+	},
+	config.AudienceLevelIntermediate: {
+		label: "中級者 (基本的な用語や概念は知っている人)",
+		guide: "基本的な用語の説明は省き、仕組みや使い分け、実践での注意点に重点を置いてください。発展的な用語にのみ短い補足を添えてください。",
+	},
+	config.AudienceLevelExpert: {
+		label: "専門家 (この分野の実務経験が豊富な人)",
+		guide: "基礎的な前提や用語の説明は省き、設計上のトレードオフ、内部の仕組み、既存手法との違いや限界など、踏み込んだ論点に時間を割いてください。",
+	},
+}
+
+// resolveAudience は --audience の値が視聴者レベル (beginner, intermediate, expert) の場合に、プロンプトに渡す聴衆の表示名とレベル、説明の詳しさの指示を返します。
+// それ以外の自由記述の場合は、値をそのまま表示名として返します。
+func resolveAudience(audience string) (label, level, guide string) {
+	key := strings.ToLower(audience)
+	if profile, ok := audienceProfiles[key]; ok {
+		return profile.label, key, profile.guide
+	}
+	return audience, "", ""
+}
@@ -0,0 +1,66 @@
+package runner
+
+import (
+	"strings"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// bookend は番組の冒頭と締めに確定で挿入する定型セグメントです。
+type bookend struct {
+	opening []script.Segment
+	closing []script.Segment
+}
+
+// modeBookends はモードごとの定型の挨拶です。話者・スタイルタグ付きで合成対象になります。
+var modeBookends = map[string]bookend{
+	"solo": {
+		opening: []script.Segment{
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "どうも、ずんだもんなのだ。今日も技術の話を一緒に見ていくのだ。"},
+		},
+		closing: []script.Segment{
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "今日はここまでなのだ。また次回なのだ。"},
+		},
+	},
+	"duet": {
+		opening: []script.Segment{
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "どうも、ずんだもんなのだ。"},
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "四国めたんよ。今日も一緒に技術の話を見ていきましょう。"},
+		},
+		closing: []script.Segment{
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "今日の解説はここまでよ。"},
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "また次回なのだ。"},
+		},
+	},
+	"dialogue": {
+		opening: []script.Segment{
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "どうも、ずんだもんなのだ。"},
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "四国めたんよ。今日もよろしくね。"},
+		},
+		closing: []script.Segment{
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "今日はここまでね。"},
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "また次回なのだ。"},
+		},
+	},
+	"faq": {
+		opening: []script.Segment{
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "四国めたんよ。今日は気になる疑問をずんだもんに質問していくわ。"},
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "ずんだもんなのだ。何でも答えるのだ。"},
+		},
+		closing: []script.Segment{
+			{SpeakerTag: "[めたん]", StyleTag: "[ノーマル]", Text: "今日の質問はここまでよ。"},
+			{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "また次回なのだ。"},
+		},
+	},
+}
+
+// applyBookends は生成された本編の前後に、モードの定型の冒頭・締めセグメントを挿入します。
+// モードに定型が定義されていない場合は本編をそのまま返します。
+func applyBookends(mode, body string) string {
+	b, ok := modeBookends[mode]
+	if !ok {
+		return body
+	}
+	body = strings.TrimRight(body, "\n") + "\n"
+	return script.Render(b.opening) + body + script.Render(b.closing)
+}
@@ -0,0 +1,24 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// fixStrayBrackets は生成スクリプトの本文に残った定義済みタグ以外の括弧表現 ([補足] など) を行番号付きで警告し、
+// --stray-brackets に従って括弧を外すか除去します。
+func fixStrayBrackets(ctx context.Context, mode, generated string) string {
+	fixed, found := script.FixStrayBrackets(generated, mode)
+	if len(found) == 0 {
+		return generated
+	}
+	for _, b := range found {
+		slog.WarnContext(ctx, "本文に定義済みタグ以外の括弧表現があります。", "line", b.Line, "text", b.Text, "action", mode)
+	}
+	if mode == script.StrayBracketWarn {
+		slog.WarnContext(ctx, "括弧表現は合成時に未知のタグとして除去されるか、そのまま読み上げられる可能性があります。--stray-brackets unwrap または strip で処理できます。", "count", len(found))
+	}
+	return fixed
+}
@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CharacterProfile はプロンプトに渡す1人の話者のキャラクター設定です。
+type CharacterProfile struct {
+	// Speaker は括弧を除いた話者タグ名です (例: "ずんだもん")。
+	Speaker     string `yaml:"-"`
+	FirstPerson string `yaml:"first_person"`
+	Ending      string `yaml:"ending"`
+	Personality string `yaml:"personality"`
+}
+
+// loadCharacterProfiles は --character-profile で指定されたキャラクター設定を読み込みます。指定が無い場合は nil を返します。
+func (gr *GenerateRunner) loadCharacterProfiles(ctx context.Context) ([]CharacterProfile, error) {
+	path := gr.options.CharacterProfile
+	if path == "" {
+		return nil, nil
+	}
+
+	rc, err := gr.reader.Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("キャラクター設定のオープンに失敗しました (%s): %w", path, err)
+	}
+	profiles, loadErr := parseCharacterProfiles(ctx, rc)
+	if err := errors.Join(loadErr, rc.Close()); err != nil {
+		return nil, fmt.Errorf("キャラクター設定の読み込みに失敗しました (%s): %w", path, err)
+	}
+	return profiles, nil
+}
+
+// parseCharacterProfiles は "話者名: {first_person, ending, personality}" 形式の YAML を読み込み、
+// 既知の話者の並び順でキャラクター設定を返します。未知の話者の設定は警告して無視します。
+//
+//	ずんだもん:
+//	  first_person: ボク
+//	  ending: 〜なのだ
+//	  personality: 好奇心旺盛で少しおっちょこちょい
+func parseCharacterProfiles(ctx context.Context, r io.Reader) ([]CharacterProfile, error) {
+	var entries map[string]CharacterProfile
+	if err := yaml.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("キャラクター設定の解析に失敗しました: %w", err)
+	}
+
+	bySpeaker := make(map[string]CharacterProfile, len(entries))
+	for name, p := range entries {
+		name = strings.Trim(strings.TrimSpace(name), "[]")
+		p.Speaker = name
+		p.FirstPerson = strings.TrimSpace(p.FirstPerson)
+		p.Ending = strings.TrimSpace(p.Ending)
+		p.Personality = strings.TrimSpace(p.Personality)
+		if p.FirstPerson == "" && p.Ending == "" && p.Personality == "" {
+			continue
+		}
+		bySpeaker[name] = p
+	}
+
+	var profiles []CharacterProfile
+	for _, speaker := range supportedSpeakerNames() {
+		if p, ok := bySpeaker[speaker]; ok {
+			profiles = append(profiles, p)
+			delete(bySpeaker, speaker)
+		}
+	}
+	for name := range bySpeaker {
+		slog.WarnContext(ctx, "キャラクター設定の話者が既知の話者にありません。この設定は無視します。", "speaker", name, "known", supportedSpeakerNames())
+	}
+	if len(profiles) == 0 {
+		return nil, errors.New("有効なキャラクター設定がありません")
+	}
+	return profiles, nil
+}
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/ai"
+	"prototypus-ai-doc-go/internal/history"
+)
+
+// defaultEstimatedOutputChars は --target-length の指定が無い場合に見積もりに使う、生成スクリプトの文字数の目安です。
+const defaultEstimatedOutputChars = 3000
+
+// costEstimate は生成前に見積もった AI 呼び出しのトークン数と費用です。
+type costEstimate struct {
+	pricing      ai.Pricing
+	priced       bool
+	inputTokens  int
+	outputTokens int
+	// run は実測のトークン数を集計する Run で、baseline はその見積もり時点の値です。
+	run                            *history.Run
+	baselinePrompt, baselineOutput int
+}
+
+// loadPricing は --pricing-file で指定された単価表を読み込みます。指定が無い場合は組み込みの単価表を返します。
+func (gr *GenerateRunner) loadPricing(ctx context.Context) (map[string]ai.Pricing, error) {
+	path := gr.options.PricingFile
+	if path == "" {
+		return ai.DefaultPricing, nil
+	}
+
+	rc, err := gr.reader.Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("単価表のオープンに失敗しました (%s): %w", path, err)
+	}
+	table, loadErr := ai.LoadPricing(rc)
+	if err := errors.Join(loadErr, rc.Close()); err != nil {
+		return nil, fmt.Errorf("単価表の読み込みに失敗しました (%s): %w", path, err)
+	}
+	return table, nil
+}
+
+// estimateCost はプロンプトと生成スクリプトの文字数の目安から、生成にかかるトークン数と費用を概算してログに出力します (--estimate-cost)。
+// 生成後に実測と比較できるよう、トークン数を集計する history.Run を格納した context を返します。
+func (gr *GenerateRunner) estimateCost(ctx context.Context, pricing map[string]ai.Pricing, promptContent string, targetLength int) (context.Context, *costEstimate) {
+	outputChars := targetLength
+	if outputChars <= 0 {
+		outputChars = defaultEstimatedOutputChars
+	}
+	est := &costEstimate{
+		inputTokens:  ai.EstimateTokens(promptContent),
+		outputTokens: outputChars,
+	}
+	est.pricing, est.priced = pricing[gr.options.AIModel]
+
+	// --record-db の集計がある場合はそれを共有し、無い場合は見積もりとの比較用に新しく集計する
+	run, ok := history.FromContext(ctx)
+	if !ok {
+		ctx, run = history.WithRun(ctx)
+	}
+	est.run = run
+	est.baselinePrompt, est.baselineOutput = run.Tokens()
+
+	attrs := []any{"model", gr.options.AIModel, "input_tokens", est.inputTokens, "output_tokens", est.outputTokens}
+	if !est.priced {
+		slog.WarnContext(ctx, "単価表にモデルが無いため、トークン数のみ見積もりました。--pricing-file で単価を指定できます。", attrs...)
+		return ctx, est
+	}
+	slog.InfoContext(ctx, "生成にかかる費用の見積もりです (トークン数は文字数からの概算です)。",
+		append(attrs, "cost_usd", fmt.Sprintf("%.4f", est.pricing.Cost(est.inputTokens, est.outputTokens)))...)
+	return ctx, est
+}
+
+// report は実測のトークン数と費用を、見積もりと比較してログに出力します。
+// 形式の再試行や継続生成、--cross-mode-check などによる追加の呼び出しも実測に含みます。
+func (est *costEstimate) report(ctx context.Context) {
+	prompt, output := est.run.Tokens()
+	prompt -= est.baselinePrompt
+	output -= est.baselineOutput
+	attrs := []any{
+		"input_tokens", prompt, "estimated_input_tokens", est.inputTokens,
+		"output_tokens", output, "estimated_output_tokens", est.outputTokens,
+	}
+	if est.priced {
+		attrs = append(attrs,
+			"cost_usd", fmt.Sprintf("%.4f", est.pricing.Cost(prompt, output)),
+			"estimated_cost_usd", fmt.Sprintf("%.4f", est.pricing.Cost(est.inputTokens, est.outputTokens)))
+	}
+	slog.InfoContext(ctx, "生成にかかった実測のトークン数と費用です。", attrs...)
+}
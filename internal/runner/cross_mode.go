@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// maxCrossModeTopics はモード間の網羅性の比較に使う主要トピックの最大数です。
+const maxCrossModeTopics = 15
+
+// crossModeCheckModes はモード間の網羅性の比較で生成するモードの一覧です。
+var crossModeCheckModes = []string{"solo", "duet", "dialogue", "faq"}
+
+// crossModeCheck は同じ入力を他のモードでも生成し、入力の主要トピックをどのモードがカバーしているかを比較します。
+// 一部のモードだけで欠落しているトピックを警告します。比較の失敗は本来の生成結果に影響させず、ログ出力のみに留めます。
+func (gr *GenerateRunner) crossModeCheck(ctx context.Context, data TemplateData, generated string) {
+	topics := script.ExtractTopics(data.InputText, maxCrossModeTopics)
+	if len(topics) == 0 {
+		slog.WarnContext(ctx, "入力から主要トピックを抽出できないため、モード間の比較をスキップします。")
+		return
+	}
+
+	scripts := map[string]string{gr.options.Mode: generated}
+	for _, mode := range crossModeCheckModes {
+		if mode == gr.options.Mode {
+			continue
+		}
+		promptContent, err := gr.promptBuilder.Build(mode, data)
+		if err != nil {
+			slog.WarnContext(ctx, "比較用のプロンプトを組み立てられないため、モードをスキップします。", "mode", mode, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "モード間の比較のためにスクリプトを生成します。", "mode", mode)
+		s, err := gr.generateScript(ctx, promptContent)
+		if err != nil {
+			slog.WarnContext(ctx, "比較用のスクリプト生成に失敗したため、モードをスキップします。", "mode", mode, "error", err)
+			continue
+		}
+		scripts[mode] = s
+	}
+
+	modes := make([]string, 0, len(scripts))
+	covered := make(map[string][]string, len(scripts))
+	for _, mode := range crossModeCheckModes {
+		s, ok := scripts[mode]
+		if !ok {
+			continue
+		}
+		modes = append(modes, mode)
+		covered[mode] = script.CoveredTopics(s, topics)
+		slog.InfoContext(ctx, "モード別のトピック網羅率", "mode", mode, "covered", len(covered[mode]), "topics", len(topics))
+	}
+
+	for _, topic := range topics {
+		var missing []string
+		for _, mode := range modes {
+			if !slices.Contains(covered[mode], topic) {
+				missing = append(missing, mode)
+			}
+		}
+		if len(missing) > 0 && len(missing) < len(modes) {
+			slog.WarnContext(ctx, "一部のモードで主要トピックが抜けています。", "topic", topic, "missing_modes", missing)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// loadAvailableStyles は --engine-styles が指定されている場合に、VOICEVOX エンジンに実在する話者ごとのスタイルを取得します。
+// 取得できない場合はスタイルの制約を加えずに生成を続けるため、警告を出して nil を返します。
+func (gr *GenerateRunner) loadAvailableStyles(ctx context.Context) []voicevox.SpeakerStyles {
+	if gr.speakers == nil {
+		return nil
+	}
+	data, err := gr.speakers.LoadSpeakers(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "エンジンのスタイル一覧を取得できないため、プロンプトに実在するスタイルを渡さずに生成します。", "error", err)
+		return nil
+	}
+	styles := data.AvailableStyles()
+	for _, s := range styles {
+		slog.InfoContext(ctx, "プロンプトで許可するスタイル", "speaker", s.Speaker, "styles", s.Styles)
+	}
+	return styles
+}
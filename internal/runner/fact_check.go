@@ -0,0 +1,22 @@
+package runner
+
+import (
+	"context"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// factCheck は生成スクリプトの各文を入力テキストと照合し、入力に根拠が見当たらない文を行番号付きで警告します (--fact-check)。
+// 照合は数値・固有名詞らしい語の有無による簡易的なもののため、警告のみに留めて生成結果は変更しません。
+func factCheck(ctx context.Context, source, generated string) {
+	claims := script.FactCheck(generated, source)
+	if len(claims) == 0 {
+		slog.InfoContext(ctx, "入力に根拠が見当たらない文は見つかりませんでした。")
+		return
+	}
+	for _, c := range claims {
+		slog.WarnContext(ctx, "入力に根拠が見当たらない文があります。", "line", c.Line, "sentence", c.Sentence, "terms", c.Terms)
+	}
+	slog.WarnContext(ctx, "ファクトチェックで入力に根拠が見当たらない文を検出しました。内容を確認してください。", "sentences", len(claims))
+}
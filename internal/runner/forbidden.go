@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// loadForbiddenTopics は --forbidden-topics で指定された禁止トピックを読み込みます。指定が無い場合は nil を返します。
+func (gr *GenerateRunner) loadForbiddenTopics(ctx context.Context) ([]script.ForbiddenTopic, error) {
+	path := gr.options.ForbiddenTopics
+	if path == "" {
+		return nil, nil
+	}
+
+	rc, err := gr.reader.Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("禁止トピックのオープンに失敗しました (%s): %w", path, err)
+	}
+	topics, loadErr := script.LoadForbiddenTopics(rc)
+	if err := errors.Join(loadErr, rc.Close()); err != nil {
+		return nil, fmt.Errorf("禁止トピックの読み込みに失敗しました (%s): %w", path, err)
+	}
+	return topics, nil
+}
+
+// checkForbiddenTopics は生成スクリプトから禁止トピックを検出し、検出した場合は理由を警告して音声合成の前に中断するエラーを返します。
+func checkForbiddenTopics(ctx context.Context, topics []script.ForbiddenTopic, generated string) error {
+	violations := script.DetectForbiddenTopics(generated, topics)
+	if len(violations) == 0 {
+		slog.InfoContext(ctx, "生成スクリプトに禁止トピックは見つかりませんでした。", "topics", len(topics))
+		return nil
+	}
+
+	reasons := make([]string, len(violations))
+	for i, v := range violations {
+		slog.WarnContext(ctx, "生成スクリプトに禁止トピックが含まれています。", "topic", v.Topic, "keywords", v.Keywords, "lines", v.Lines)
+		reasons[i] = v.String()
+	}
+	return fmt.Errorf("生成スクリプトに禁止トピックが含まれるため、音声合成を中止しました: %s", strings.Join(reasons, " / "))
+}
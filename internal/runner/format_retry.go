@@ -0,0 +1,64 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// formatReminder はタグ形式の崩れたスクリプトを再生成する際に、プロンプトの末尾に付ける補足指示です。
+const formatReminder = `
+
+# 出力形式の厳守
+前回の出力は指定したスクリプト形式になっていませんでした。指定フォーマットを厳守してください。
+* すべてのセリフを「[話者タグ][スタイルタグ] セリフ」の形式で、1行ずつ書いてください。
+* 話者タグには指示された話者のみを使ってください。
+* 前置きや説明、Markdown の装飾は出力せず、スクリプトのみを出力してください。`
+
+// generateWithFormatRetry はスクリプトを生成し、有効なセグメントが1件も無い場合は補足指示を付けて最大 --format-retries 回まで再生成します。
+// 再生成しても形式が直らない場合は、最後の生成結果をそのまま返します。
+func (gr *GenerateRunner) generateWithFormatRetry(ctx context.Context, promptContent string) (string, error) {
+	generated, err := gr.generateScript(ctx, promptContent)
+	if err != nil {
+		return "", err
+	}
+	for attempt := 1; attempt <= gr.options.FormatRetries; attempt++ {
+		reason := formatProblem(generated)
+		if reason == "" {
+			return generated, nil
+		}
+		slog.WarnContext(ctx, "生成スクリプトが指定フォーマットになっていないため、補足指示を付けて再生成します。",
+			"attempt", attempt, "max_attempts", gr.options.FormatRetries, "reason", reason)
+		generated, err = gr.generateScript(ctx, promptContent+formatReminder)
+		if err != nil {
+			return "", fmt.Errorf("スクリプトの再生成 (%d 回目) に失敗しました: %w", attempt, err)
+		}
+	}
+	if reason := formatProblem(generated); reason != "" {
+		slog.WarnContext(ctx, "再生成しても生成スクリプトが指定フォーマットになりませんでした。", "retries", gr.options.FormatRetries, "reason", reason)
+	}
+	return generated, nil
+}
+
+// formatProblem は生成スクリプトに既知の話者タグ付きのセグメントが1件も無い場合に、その理由を返します。問題が無い場合は空文字を返します。
+func formatProblem(generated string) string {
+	normalized, _ := voicevox.NormalizeSpeakerAliases(generated)
+	segments := script.Parse(normalized)
+	if len(segments) == 0 {
+		return "本文が空です"
+	}
+	speakers := supportedSpeakerNames()
+	valid := slices.ContainsFunc(segments, func(seg script.Segment) bool {
+		return slices.Contains(speakers, seg.Speaker())
+	})
+	if valid {
+		return ""
+	}
+	firstLine, _, _ := strings.Cut(strings.TrimSpace(generated), "\n")
+	return fmt.Sprintf("既知の話者タグ付きのセグメントが0件です (%d セグメント、先頭行: %q)", len(segments), firstLine)
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// frontMatterDelimiter はフロントマターの開始・終了を示す行です。
+const frontMatterDelimiter = "---"
+
+// frontMatter は入力ファイル先頭の YAML フロントマターで指定できる設定です。
+type frontMatter struct {
+	Mode         string `yaml:"mode"`
+	Model        string `yaml:"model"`
+	SourceType   string `yaml:"source_type"`
+	ScriptFormat string `yaml:"script_format"`
+	Title        string `yaml:"title"`
+	Audience     string `yaml:"audience"`
+	TargetLength int    `yaml:"target_length"`
+	// SpeakerConfig は --speaker-config と同じ形式の話者定義ファイルのパスです。
+	SpeakerConfig      string `yaml:"speaker_config"`
+	SpeakerConfigMerge bool   `yaml:"speaker_config_merge"`
+}
+
+// splitFrontMatter は入力の先頭にフロントマターがあれば取り出し、残りの本文とともに返します。
+// フロントマターが無い場合は nil と入力をそのまま返します。
+func splitFrontMatter(content []byte) (*frontMatter, []byte, error) {
+	text := bytes.TrimPrefix(content, []byte("\ufeff"))
+	firstLine, rest, found := bytes.Cut(text, []byte("\n"))
+	if !found || strings.TrimSpace(string(firstLine)) != frontMatterDelimiter {
+		return nil, content, nil
+	}
+
+	var header []byte
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		if strings.TrimSpace(string(line)) == frontMatterDelimiter {
+			var fm frontMatter
+			if err := yaml.Unmarshal(header, &fm); err != nil {
+				return nil, nil, fmt.Errorf("フロントマターの解析に失敗しました: %w", err)
+			}
+			return &fm, rest, nil
+		}
+		header = append(append(header, line...), '\n')
+	}
+	// 終了行が無い場合はフロントマターとみなさない
+	return nil, content, nil
+}
+
+// applyFrontMatter はフロントマターの設定をオプションに反映します。コマンドラインで明示的に指定されたフラグが優先されます。
+func applyFrontMatter(options *config.Config, fm *frontMatter) error {
+	if fm.SourceType != "" && !slices.Contains(config.SourceTypes, strings.ToLower(fm.SourceType)) {
+		return fmt.Errorf("フロントマターの source_type には %s のいずれかを指定してください: '%s'", strings.Join(config.SourceTypes, ", "), fm.SourceType)
+	}
+	if fm.ScriptFormat != "" && !slices.Contains(script.Formats, strings.ToLower(fm.ScriptFormat)) {
+		return fmt.Errorf("フロントマターの script_format には %s のいずれかを指定してください: '%s'", strings.Join(script.Formats, ", "), fm.ScriptFormat)
+	}
+
+	override := func(flag string, dst *string, value string) {
+		if value == "" {
+			return
+		}
+		if options.FlagChanged(flag) {
+			slog.Info("コマンドラインの指定を優先し、フロントマターの値を無視します。", "flag", flag, "front_matter", value)
+			return
+		}
+		*dst = value
+	}
+	override("mode", &options.Mode, strings.TrimSpace(fm.Mode))
+	override("model", &options.AIModel, strings.TrimSpace(fm.Model))
+	override("source-type", &options.SourceType, strings.ToLower(strings.TrimSpace(fm.SourceType)))
+	override("script-format", &options.ScriptFormat, strings.ToLower(strings.TrimSpace(fm.ScriptFormat)))
+	override("title", &options.Title, strings.TrimSpace(fm.Title))
+	override("audience", &options.Audience, strings.TrimSpace(fm.Audience))
+	if fm.TargetLength > 0 {
+		if options.FlagChanged("target-length") {
+			slog.Info("コマンドラインの指定を優先し、フロントマターの値を無視します。", "flag", "target-length", "front_matter", fm.TargetLength)
+		} else {
+			options.TargetLength = fm.TargetLength
+		}
+	}
+	return applyFrontMatterSpeakers(options, fm)
+}
+
+// applyFrontMatterSpeakers はフロントマターの話者定義ファイルを読み込みます。
+// --speaker-config はコマンドの開始時に読み込まれるため、フロントマターで指定された場合はここで読み込み、
+// 以降のプロンプトの組み立てと音声合成で使う話者を置き換えます。
+func applyFrontMatterSpeakers(options *config.Config, fm *frontMatter) error {
+	path := strings.TrimSpace(fm.SpeakerConfig)
+	if path == "" {
+		return nil
+	}
+	if options.FlagChanged("speaker-config") {
+		slog.Info("コマンドラインの指定を優先し、フロントマターの値を無視します。", "flag", "speaker-config", "front_matter", path)
+		return nil
+	}
+	if fm.SpeakerConfigMerge && !options.FlagChanged("speaker-config-merge") {
+		options.SpeakerConfigMerge = true
+	}
+	options.SpeakerConfig = path
+	if err := voicevox.LoadSpeakersFromConfig(path, options.SpeakerConfigMerge); err != nil {
+		return fmt.Errorf("フロントマターの speaker_config を読み込めませんでした: %w", err)
+	}
+	return nil
+}
@@ -1,42 +1,82 @@
 package runner
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"os"
 	"strings"
+	"unicode/utf8"
 
-	"github.com/shouni/go-gemini-client/gemini"
 	"github.com/shouni/go-remote-io/remoteio"
-	"github.com/shouni/go-web-exact/v2/ports"
 
+	"prototypus-ai-doc-go/internal/ai"
 	"prototypus-ai-doc-go/internal/config"
 	"prototypus-ai-doc-go/internal/domain"
+	"prototypus-ai-doc-go/internal/history"
+	"prototypus-ai-doc-go/internal/retryqueue"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/scriptversion"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // TemplateData はプロンプトテンプレートに渡すデータ構造です。
 type TemplateData struct {
 	InputText string
+	// SourceType は入力ソースの種類の表示名です (例: "ニュース記事")。不明な場合は空文字です。
+	SourceType string
+	// SourceTone は SourceType に応じたトーン調整の指示文です。
+	SourceTone string
+	// Title は番組タイトルです。--title、フロントマター、URLから抽出した記事タイトルの順に採用します。
+	Title string
+	// Audience は想定する聴衆です (例: "Go初心者")。視聴者レベルを指定した場合はその表示名です。未指定の場合は空文字です。
+	Audience string
+	// AudienceLevel は --audience で指定した視聴者レベル (beginner, intermediate, expert) です。自由記述または未指定の場合は空文字です。
+	AudienceLevel string
+	// AudienceGuide は AudienceLevel に応じた説明の詳しさの指示文です。
+	AudienceGuide string
+	// TargetLength はセリフ本文の目安の文字数です。未指定の場合は 0 です。
+	TargetLength int
+	// Characters は --character-profile で指定された話者ごとのキャラクター設定です。未指定の場合は nil です。
+	Characters []CharacterProfile
+	// AvailableStyles は --engine-styles で VOICEVOX エンジンから取得した、話者ごとに実在するスタイルです。未指定または取得できない場合は nil です。
+	AvailableStyles []voicevox.SpeakerStyles
+	// Bookends が true の場合、冒頭と締めの挨拶はツールが挿入するため、AIには本編のみを書かせます。
+	Bookends bool
+	// MarkConclusion が true の場合、結論にあたるセリフを [結論]...[/結論] で囲ませます。
+	MarkConclusion bool
 }
 
 // GenerateRunner は generate コマンドの実行に必要な依存とオプションを保持します。
 type GenerateRunner struct {
 	options       *config.Config
-	extractor     ports.Extractor
+	extractor     domain.ArticleExtractor
 	promptBuilder domain.PromptBuilder
-	aiClient      gemini.Generator
+	aiClient      ai.Generator
 	reader        remoteio.InputReader
+	retryQueue    *retryqueue.Queue
+	speakers      SpeakerLoader
+	versions      *scriptversion.Store
+	writer        remoteio.OutputWriter
+}
+
+// SpeakerLoader はエンジンの話者・スタイルの一覧を取得できる合成ステージです。*voicevox.Client が満たします。
+type SpeakerLoader interface {
+	LoadSpeakers(ctx context.Context) (*voicevox.SpeakerData, error)
 }
 
 // NewGenerateRunner は、依存関係を注入して GenerateRunner の新しいインスタンスを生成します。
 func NewGenerateRunner(
 	options *config.Config,
-	extractor ports.Extractor,
+	extractor domain.ArticleExtractor,
 	promptBuilder domain.PromptBuilder,
-	aiClient gemini.Generator,
+	aiClient ai.Generator,
 	reader remoteio.InputReader,
+	retryQueue *retryqueue.Queue,
 ) *GenerateRunner {
 	return &GenerateRunner{
 		options:       options,
@@ -44,67 +84,390 @@ func NewGenerateRunner(
 		promptBuilder: promptBuilder,
 		aiClient:      aiClient,
 		reader:        reader,
+		retryQueue:    retryQueue,
 	}
 }
 
+// WithSpeakerLoader はプロンプトに実在するスタイルを渡すために loader を使うよう設定し、GenerateRunner 自身を返します (--engine-styles)。
+func (gr *GenerateRunner) WithSpeakerLoader(loader SpeakerLoader) *GenerateRunner {
+	gr.speakers = loader
+	return gr
+}
+
+// WithScriptHistory は生成したスクリプトをバージョンとして store に保存するよう設定し、GenerateRunner 自身を返します (--script-history-dir)。
+func (gr *GenerateRunner) WithScriptHistory(store *scriptversion.Store) *GenerateRunner {
+	gr.versions = store
+	return gr
+}
+
+// WithMetadataWriter は生成したスクリプトの説明文とハッシュタグを AI に生成させ、writer で書き込むよう設定し、GenerateRunner 自身を返します (--generate-metadata)。
+func (gr *GenerateRunner) WithMetadataWriter(writer remoteio.OutputWriter) *GenerateRunner {
+	gr.writer = writer
+	return gr
+}
+
 // Run は、入力ソースからコンテンツを読み込み、AIモデルを使用してナレーションスクリプトを生成する一連の処理を実行します。
 func (gr *GenerateRunner) Run(ctx context.Context) (string, error) {
-	inputContent, err := gr.readInputContent(ctx)
+	// 生成後に辞書の誤りに気付いて AI 呼び出しが無駄にならないよう、先に読み込んでおく
+	glossary, err := gr.loadGlossary(ctx)
 	if err != nil {
 		return "", err
 	}
-	slog.Info("処理開始", "mode", gr.options.Mode, "model", gr.options.AIModel, "input_size", len(inputContent))
+	forbidden, err := gr.loadForbiddenTopics(ctx)
+	if err != nil {
+		return "", err
+	}
+	var pricing map[string]ai.Pricing
+	run, recording := history.FromContext(ctx)
+	if gr.options.EstimateCost || recording {
+		if pricing, err = gr.loadPricing(ctx); err != nil {
+			return "", err
+		}
+	}
+	inputContent, data, promptContent, err := gr.buildPrompt(ctx)
+	if err != nil {
+		return "", err
+	}
+	// フロントマターの model: でモデルが切り替わるため、単価はプロンプトを組み立てた後に引く
+	if p, ok := pricing[gr.options.AIModel]; ok && recording {
+		run.SetPrice(p.Input, p.Output)
+	}
 	slog.Info("AIによるスクリプト生成を開始します...")
 
+	var cost *costEstimate
+	if gr.options.EstimateCost {
+		ctx, cost = gr.estimateCost(ctx, pricing, promptContent, data.TargetLength)
+	}
+
+	generated, err := gr.generateWithFormatRetry(ctx, promptContent)
+	if err != nil {
+		gr.saveFailedInput(inputContent, err)
+		return "", fmt.Errorf("スクリプト生成に失敗しました: %w", err)
+	}
+	slog.Info("AI スクリプト生成完了", "script_length", len(generated))
+
+	if gr.options.CrossModeCheck {
+		gr.crossModeCheck(ctx, data, generated)
+	}
+	if cost != nil {
+		cost.report(ctx)
+	}
+
+	if glossary != nil {
+		generated = applyGlossary(ctx, glossary, generated)
+	}
+
+	if data.Bookends {
+		generated = applyBookends(gr.options.Mode, generated)
+	}
+
+	generated = fixStrayBrackets(ctx, cmp.Or(gr.options.StrayBrackets, script.StrayBracketWarn), generated)
+
+	if gr.options.FactCheck {
+		factCheck(ctx, data.InputText, generated)
+	}
+
+	// 不正なタグは合成段階で初めて表面化するため、エンジンを呼び出す前に検査する
+	if err := gr.validateScript(ctx, generated); err != nil {
+		return "", err
+	}
+	if forbidden != nil {
+		if err := checkForbiddenTopics(ctx, forbidden, generated); err != nil {
+			return "", err
+		}
+	}
+	if gr.options.GenerateMetadata {
+		gr.writeMetadata(ctx, data.Title, generated)
+	}
+	gr.saveVersion(ctx, inputContent, promptContent, generated)
+	return generated, nil
+}
+
+// Prompt は AI を呼び出さずに、Run が AI に渡すプロンプト全文を組み立てて返します (--print-prompt)。
+// テンプレートと入力の埋め込みの結果を、API キーや料金をかけずに確認するために使います。--auto-summarize による入力の要約は行いません。
+func (gr *GenerateRunner) Prompt(ctx context.Context) (string, error) {
+	_, _, promptContent, err := gr.buildPrompt(ctx)
+	return promptContent, err
+}
+
+// buildPrompt は入力ソースを読み込んでフロントマター・整形・要約を適用し、テンプレートに埋め込んだプロンプト全文を組み立てます。
+// 読み込んだ入力 (フロントマターを除いたもの)、テンプレートに渡したデータ、プロンプト全文を返します。
+func (gr *GenerateRunner) buildPrompt(ctx context.Context) ([]byte, TemplateData, string, error) {
+	inputContent, fm, article, err := gr.readInputContent(ctx)
+	if err != nil {
+		return nil, TemplateData{}, "", err
+	}
+	characters, err := gr.loadCharacterProfiles(ctx)
+	if err != nil {
+		return nil, TemplateData{}, "", err
+	}
+	if fm != nil {
+		if err := applyFrontMatter(gr.options, fm); err != nil {
+			return nil, TemplateData{}, "", err
+		}
+		slog.Info("入力ファイルのフロントマターを読み込みました。", "mode", gr.options.Mode, "model", gr.options.AIModel, "title", gr.options.Title)
+	}
+	title := gr.options.Title
+	var ogType string
+	if article != nil {
+		if title == "" {
+			title = article.Title
+		}
+		ogType = article.Type
+	}
+	if gr.options.Reflow {
+		inputContent = []byte(reflowParagraphs(string(inputContent)))
+	}
+	if gr.options.AutoSummarize && gr.exceedsInputLimit(string(inputContent)) {
+		if gr.aiClient == nil {
+			slog.Warn("AI を呼び出さないため、入力を要約せずにプロンプトを組み立てます。", "input_chars", utf8.RuneCountInString(string(inputContent)), "max_input_chars", gr.options.MaxInputChars)
+		} else {
+			summarized, err := gr.summarizeInput(ctx, string(inputContent))
+			if err != nil {
+				return nil, TemplateData{}, "", err
+			}
+			inputContent = []byte(summarized)
+		}
+	}
+	var sourceURL string
+	if len(gr.options.ScriptURLs) > 0 {
+		sourceURL = gr.options.ScriptURLs[0]
+	}
+	sourceType := resolveSourceType(gr.options.SourceType, sourceURL, ogType)
+	slog.Info("処理開始", "mode", gr.options.Mode, "model", gr.options.AIModel, "input_size", len(inputContent), "source_type", sourceType)
+
+	styles := gr.loadAvailableStyles(ctx)
+	_, hasBookends := modeBookends[gr.options.Mode]
 	data := TemplateData{
-		InputText: string(inputContent),
+		InputText:       string(inputContent),
+		Title:           title,
+		TargetLength:    max(gr.options.TargetLength, 0),
+		Characters:      characters,
+		AvailableStyles: styles,
+		Bookends:        gr.options.Bookends && hasBookends,
+		MarkConclusion:  gr.options.MarkConclusion,
+	}
+	if profile, ok := sourceTypeProfiles[sourceType]; ok {
+		data.SourceType = profile.label
+		data.SourceTone = profile.tone
 	}
+	data.Audience, data.AudienceLevel, data.AudienceGuide = resolveAudience(gr.options.Audience)
 	promptContent, err := gr.promptBuilder.Build(gr.options.Mode, data)
 	if err != nil {
-		return "", err
+		return nil, TemplateData{}, "", err
+	}
+	return inputContent, data, promptContent, nil
+}
+
+// generateScript はプロンプトからスクリプトを生成します。
+// 構造化出力が有効な場合はまず構造化出力を試み、利用できない場合はテキスト生成にフォールバックします。
+func (gr *GenerateRunner) generateScript(ctx context.Context, promptContent string) (string, error) {
+	if gr.options.StructuredOutput {
+		generated, err := gr.generateStructured(ctx, promptContent)
+		if err == nil {
+			return generated, nil
+		}
+		if !errors.Is(err, errStructuredUnavailable) {
+			return "", err
+		}
+		slog.Warn("構造化出力を利用できないため、テキスト生成にフォールバックします。", "model", gr.options.AIModel, "error", err)
+	}
+
+	if stream, ok := gr.aiClient.(ai.StreamGenerator); ok && gr.options.Stream {
+		// 生成の進捗が分かるよう、受信したチャンクを逐次表示する。標準出力はスクリプトの出力先になり得るため標準エラーに書く
+		generatedResponse, err := stream.GenerateScriptStream(ctx, gr.options.AIModel, promptContent, os.Stderr)
+		if err != nil {
+			return "", err
+		}
+		return generatedResponse.Text, nil
 	}
 
 	generatedResponse, err := gr.aiClient.GenerateContent(ctx, gr.options.AIModel, promptContent)
 	if err != nil {
-		return "", fmt.Errorf("スクリプト生成に失敗しました: %w", err)
+		return "", err
 	}
-	slog.Info("AI スクリプト生成完了", "script_length", len(generatedResponse.Text))
-
 	return generatedResponse.Text, nil
 }
 
+// errStructuredUnavailable はモデルが構造化出力に対応していない、または応答を解析できなかったことを表します。
+var errStructuredUnavailable = errors.New("構造化出力を利用できません")
+
+// generateStructured はセグメント配列のJSONを構造化出力で生成させ、"[話者][スタイル] テキスト" 形式のスクリプトに変換します。
+// タグの解析を経ないため、出力形式の揺れが生じません。
+func (gr *GenerateRunner) generateStructured(ctx context.Context, promptContent string) (string, error) {
+	schema := ai.ScriptSchema(supportedSpeakerNames(), voicevox.SupportedStyleNames())
+
+	resp, err := gr.aiClient.GenerateStructured(ctx, gr.options.AIModel, promptContent, schema)
+	if err != nil {
+		if ai.IsInvalidArgument(err) {
+			return "", fmt.Errorf("%w: %w", errStructuredUnavailable, err)
+		}
+		return "", err
+	}
+	decoded, err := ai.DecodeSegments(resp.Text)
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errStructuredUnavailable, err)
+	}
+
+	segments := make([]script.Segment, 0, len(decoded))
+	for _, seg := range decoded {
+		segments = append(segments, script.Segment{
+			SpeakerTag: "[" + seg.Speaker + "]",
+			StyleTag:   "[" + seg.Style + "]",
+			Text:       seg.Text,
+		})
+	}
+	return script.Render(segments), nil
+}
+
+// saveFailedInput は生成に失敗した入力を再試行キューに保存します。
+// 保存の失敗は本来のエラーを隠さないよう、ログ出力のみに留めます。
+func (gr *GenerateRunner) saveFailedInput(inputContent []byte, cause error) {
+	if gr.retryQueue == nil {
+		return
+	}
+
+	source := strings.Join(gr.options.ScriptURLs, ", ")
+	if source == "" {
+		source = gr.options.ScriptFile
+	}
+	entry, err := gr.retryQueue.Save(retryqueue.Entry{
+		ID:     retryqueue.EntryID(gr.options.Mode, inputContent),
+		Source: source,
+		Options: retryqueue.Options{
+			Mode:             gr.options.Mode,
+			AIModel:          gr.options.AIModel,
+			OutputFile:       gr.options.OutputFile,
+			VoicevoxOutput:   gr.options.VoicevoxOutput,
+			SourceType:       gr.options.SourceType,
+			ScriptFormat:     gr.options.ScriptFormat,
+			StructuredOutput: gr.options.StructuredOutput,
+			Bookends:         gr.options.Bookends,
+			MarkConclusion:   gr.options.MarkConclusion,
+		},
+	}, inputContent, cause)
+	if err != nil {
+		slog.Warn("失敗した入力を再試行キューに保存できませんでした。", "error", err)
+		return
+	}
+	slog.Info("失敗した入力を再試行キューに保存しました。retry-failed コマンドで再処理できます。", "id", entry.ID, "attempts", entry.Attempts)
+}
+
+// saveVersion は生成したスクリプトを、モデルとプロンプトとともに題材の新しいバージョンとして保存します。
+// 題材のIDは --script-id、省略時は入力元の URL・ファイルパス (標準入力の場合は入力本文) から算出します。
+// 保存の失敗は生成結果を無駄にしないよう、ログ出力のみに留めます。
+func (gr *GenerateRunner) saveVersion(ctx context.Context, inputContent []byte, promptContent, generated string) {
+	if gr.versions == nil {
+		return
+	}
+
+	source := strings.Join(gr.options.ScriptURLs, ", ")
+	if source == "" && gr.options.ScriptFile != "-" {
+		source = gr.options.ScriptFile
+	}
+	id := gr.options.ScriptID
+	if id == "" {
+		id = scriptversion.SubjectID(cmp.Or(source, string(inputContent)))
+	}
+	v, saved, err := gr.versions.Save(id, scriptversion.Version{
+		Source:     source,
+		Mode:       gr.options.Mode,
+		Model:      gr.options.AIModel,
+		PromptFile: gr.options.PromptFile,
+	}, generated, promptContent)
+	if err != nil {
+		slog.WarnContext(ctx, "生成したスクリプトを履歴に保存できませんでした。", "error", err)
+		return
+	}
+	if !saved {
+		slog.InfoContext(ctx, "生成したスクリプトは最新のバージョンと同じ内容のため、履歴に追加しませんでした。", "id", id, "hash", v.Hash)
+		return
+	}
+	slog.InfoContext(ctx, "生成したスクリプトを履歴に保存しました。script-history コマンドで差分を確認できます。", "id", id, "hash", v.Hash)
+}
+
 // --------------------------------------------------------------------------------
 // ヘルパー関数 (入力処理)
 // --------------------------------------------------------------------------------
 
-func (gr *GenerateRunner) readFromURL(ctx context.Context) ([]byte, error) {
-	slog.Info("URLからコンテンツを取得中", "url", gr.options.ScriptURL, "timeout", gr.options.HTTPTimeout.String())
+// readFromURLs は --script-url の各URLから本文を取得し、URLが1つの場合はその記事 (タイトルと og:type の参照用) も返します。
+// 複数のURLが指定された場合は各記事を見出し行で区切って連結し、記事は nil を返します。取得に失敗したURLは飛ばして続行し、最後にまとめて報告します。
+func (gr *GenerateRunner) readFromURLs(ctx context.Context) ([]byte, *domain.Article, error) {
+	urls := gr.options.ScriptURLs
+	if len(urls) == 1 {
+		article, err := gr.readFromURL(ctx, urls[0])
+		if err != nil {
+			return nil, nil, fmt.Errorf("URLからのコンテンツ取得に失敗しました: %w", err)
+		}
+		if article.Title == "" {
+			return []byte(article.Text), article, nil
+		}
+		return []byte(strings.TrimSpace("# " + article.Title + "\n\n" + article.Text)), article, nil
+	}
+
+	var articles []string
+	var failed []string
+	var errs []error
+	for _, u := range urls {
+		article, err := gr.readFromURL(ctx, u)
+		if err != nil {
+			slog.Warn("URLからのコンテンツ取得に失敗しました。残りのURLの処理を続行します。", "url", u, "error", err)
+			failed = append(failed, u)
+			errs = append(errs, fmt.Errorf("%s: %w", u, err))
+			continue
+		}
+		heading := fmt.Sprintf("## 記事%d (%s)", len(articles)+1, u)
+		if article.Title != "" {
+			heading = fmt.Sprintf("## 記事%d: %s (%s)", len(articles)+1, article.Title, u)
+		}
+		articles = append(articles, strings.TrimSpace(heading+"\n\n"+article.Text))
+	}
+	if len(articles) == 0 {
+		return nil, nil, fmt.Errorf("すべてのURLからのコンテンツ取得に失敗しました: %w", errors.Join(errs...))
+	}
+	if len(failed) > 0 {
+		slog.Warn("一部のURLからコンテンツを取得できませんでした。取得できた記事のみで処理を続行します。",
+			"failed", len(failed), "total", len(urls), "failed_urls", failed)
+	}
+	return []byte(strings.Join(articles, "\n\n")), nil, nil
+}
+
+// readFromURL は1つのURLから記事のタイトルと本文を取得します。
+func (gr *GenerateRunner) readFromURL(ctx context.Context, scriptURL string) (*domain.Article, error) {
+	slog.Info("URLからコンテンツを取得中", "url", scriptURL, "timeout", gr.options.HTTPTimeout.String())
 
-	text, hasBodyFound, err := gr.extractor.FetchAndExtractText(ctx, gr.options.ScriptURL)
+	article, err := gr.extractor.FetchArticle(ctx, scriptURL)
 	if err != nil {
-		return nil, fmt.Errorf("URLからのコンテンツ取得に失敗しました: %w", err)
+		return nil, err
+	}
+	if !article.HasBody {
+		slog.Info("記事本文が見つかりませんでした。タイトルのみで処理を続行します。", "url", scriptURL, "title", article.Title)
 	}
-	if !hasBodyFound {
-		slog.Info("記事本文が見つかりませんでした。タイトルのみで処理を続行します。", "url", gr.options.ScriptURL)
+	// ペイウォールの向こうの誘導文言だけから生成しても低品質なスクリプトにしかならないため、兆候があれば警告する
+	if signals := paywallSignals(article.Text, article.HasBody); len(signals) > 0 {
+		slog.Warn("ペイウォールまたはログイン誘導により本文を取得できていない可能性があります。生成結果の品質を確認してください。",
+			"url", scriptURL, "signals", signals)
 	}
-	return []byte(text), nil
+	return article, nil
 }
 
-// readInputContent は入力ソースからコンテンツを読み込みます。
-func (gr *GenerateRunner) readInputContent(ctx context.Context) ([]byte, error) {
+// readInputContent は入力ソースからコンテンツを読み込みます。先頭のフロントマターは取り除いて別に返し、本文の長さだけを検査します。
+// URLが1つの場合は抽出した記事も返します。
+func (gr *GenerateRunner) readInputContent(ctx context.Context) ([]byte, *frontMatter, *domain.Article, error) {
 	var inputContent []byte
+	var article *domain.Article
 	var err error
 
 	switch {
-	case gr.options.ScriptURL != "":
-		inputContent, err = gr.readFromURL(ctx)
+	case len(gr.options.ScriptURLs) > 0:
+		inputContent, article, err = gr.readFromURLs(ctx)
 	default:
 		// URLが指定されていない場合、--script-fileで指定されたパスからコンテンツを読み込む。
 		// パスが空文字列または"-"の場合、標準入力がソースとなる。
 		path := gr.options.ScriptFile
 		rc, openErr := gr.reader.Open(ctx, path)
 		if openErr != nil {
-			return nil, fmt.Errorf("入力ソースのオープンに失敗しました (%s): %w", path, openErr)
+			return nil, nil, nil, fmt.Errorf("入力ソースのオープンに失敗しました (%s): %w", path, openErr)
 		}
 
 		// 読み取りとクローズを同時に行い、エラーを結合
@@ -112,9 +475,21 @@ func (gr *GenerateRunner) readInputContent(ctx context.Context) ([]byte, error)
 		closeErr := rc.Close()
 
 		if joinedErr := errors.Join(readErr, closeErr); joinedErr != nil {
-			return nil, fmt.Errorf("入力ソース(%s)の処理に失敗しました: %w", path, joinedErr)
+			return nil, nil, nil, fmt.Errorf("入力ソース(%s)の処理に失敗しました: %w", path, joinedErr)
 		}
 		inputContent = readContent
+
+		if isPDFPath(path) {
+			text, pages, pdfErr := extractPDFText(readContent)
+			if pdfErr != nil {
+				return nil, nil, nil, fmt.Errorf("PDFからのテキスト抽出に失敗しました (%s): %w", path, pdfErr)
+			}
+			if len(strings.TrimSpace(text)) < config.MinInputContentLength {
+				return nil, nil, nil, fmt.Errorf("PDFから抽出したテキストが短すぎます (%s、最低%dバイト必要です)。画像のみのPDF (スキャン文書など) はテキストを抽出できません。", path, config.MinInputContentLength)
+			}
+			slog.InfoContext(ctx, "PDFからテキストを抽出しました。", "path", path, "pages", pages, "chars", utf8.RuneCountInString(text))
+			inputContent = []byte(text)
+		}
 	}
 
 	// 共通のエラーチェック (URL読み込みエラー、または標準入力が空の場合の判定)
@@ -122,15 +497,23 @@ func (gr *GenerateRunner) readInputContent(ctx context.Context) ([]byte, error)
 		// --script-file 指定なし、または明示的な "-" 指定の両方をチェック
 		isStdinEmpty := (gr.options.ScriptFile == "" || gr.options.ScriptFile == "-")
 		if errors.Is(err, io.EOF) && len(inputContent) == 0 && isStdinEmpty {
-			return nil, fmt.Errorf("標準入力が空です。文章を入力してください。")
+			return nil, nil, nil, fmt.Errorf("標準入力が空です。文章を入力してください。")
 		}
-		return nil, fmt.Errorf("コンテンツの読み込み中にエラーが発生しました: %w", err)
+		return nil, nil, nil, fmt.Errorf("コンテンツの読み込み中にエラーが発生しました: %w", err)
 	}
 
-	trimmedContent := strings.TrimSpace(string(inputContent))
+	fm, body, err := splitFrontMatter(bytes.TrimSpace(inputContent))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	trimmedContent := strings.TrimSpace(string(body))
 	if len(trimmedContent) < config.MinInputContentLength {
-		return nil, fmt.Errorf("入力されたコンテンツが短すぎます (最低%dバイト必要です)。", config.MinInputContentLength)
+		return nil, nil, nil, fmt.Errorf("入力されたコンテンツが短すぎます (最低%dバイト必要です)。", config.MinInputContentLength)
+	}
+	if gr.exceedsInputLimit(trimmedContent) && !gr.options.AutoSummarize {
+		return nil, nil, nil, fmt.Errorf("入力されたコンテンツが長すぎます (%d文字、上限%d文字)。--auto-summarize で要約してから生成するか、--max-input-chars で上限を変更してください。",
+			utf8.RuneCountInString(trimmedContent), gr.options.MaxInputChars)
 	}
 
-	return []byte(trimmedContent), nil
+	return []byte(trimmedContent), fm, article, nil
 }
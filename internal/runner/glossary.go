@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// loadGlossary は --glossary で指定された用語辞書を読み込みます。指定が無い場合は nil を返します。
+func (gr *GenerateRunner) loadGlossary(ctx context.Context) (*script.Glossary, error) {
+	path := gr.options.Glossary
+	if path == "" {
+		return nil, nil
+	}
+
+	rc, err := gr.reader.Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("用語辞書のオープンに失敗しました (%s): %w", path, err)
+	}
+	glossary, loadErr := script.LoadGlossary(rc)
+	if err := errors.Join(loadErr, rc.Close()); err != nil {
+		return nil, fmt.Errorf("用語辞書の読み込みに失敗しました (%s): %w", path, err)
+	}
+	return glossary, nil
+}
+
+// applyGlossary は生成されたスクリプトの表記揺れを用語辞書の正規形に統一し、置き換えた箇所をログに出力します。
+func applyGlossary(ctx context.Context, glossary *script.Glossary, generated string) string {
+	normalized, replacements := glossary.Apply(generated)
+	total := 0
+	for _, r := range replacements {
+		slog.InfoContext(ctx, "表記を統一しました。", "variant", r.Variant, "canonical", r.Canonical, "count", r.Count)
+		total += r.Count
+	}
+	slog.InfoContext(ctx, "用語辞書による表記統一が完了しました。", "replacements", total)
+	return normalized
+}
@@ -0,0 +1,90 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"prototypus-ai-doc-go/internal/ai"
+	"prototypus-ai-doc-go/internal/config"
+)
+
+const (
+	// metadataDescriptionChars は説明文の目安の文字数です。
+	metadataDescriptionChars = 150
+	// metadataHashtags はハッシュタグの目安の個数です。
+	metadataHashtags = 5
+)
+
+// metadataPrompt は生成したスクリプトから説明欄用のメタ情報を作らせる指示です。
+// %s は番組タイトルの行、%d は説明文の目安の文字数とハッシュタグの目安の個数、最後の %s はスクリプトです。
+const metadataPrompt = `以下はナレーションスクリプトです。この内容を動画や Podcast として公開するため、説明欄に載せる説明文とハッシュタグを作成してください。%s
+* 説明文は内容を要約し、%d文字程度で書いてください。視聴者が内容を把握でき、検索されやすい語を含めてください。
+* ハッシュタグは内容に関連するものを%d個程度挙げてください。
+* 行頭の [話者][スタイル] などのタグや、話者名は説明文に含めないでください。
+* スクリプトに無い情報を付け足さないでください。
+
+---
+%s`
+
+// scriptMetadata はメタ情報ファイルに書き込む内容です。
+type scriptMetadata struct {
+	Title       string   `json:"title,omitempty"`
+	Description string   `json:"description"`
+	Hashtags    []string `json:"hashtags"`
+}
+
+// metadataPath はメタ情報の出力先を返します。音声 (--voicevox) またはスクリプト (--output-file) の出力先の拡張子を .meta.json に置き換えたパスです。
+// どちらも指定されていない場合は空文字を返します。
+func metadataPath(cfg *config.Config) string {
+	output := cfg.VoicevoxOutput
+	if output == "" {
+		output = cfg.OutputFile
+	}
+	if output == "" {
+		return ""
+	}
+	return strings.TrimSuffix(output, filepath.Ext(output)) + ".meta.json"
+}
+
+// writeMetadata は生成したスクリプトから説明欄用の説明文とハッシュタグを AI に生成させ、JSON で書き込みます (--generate-metadata)。
+// メタ情報はスクリプトの付加物のため、生成や書き込みの失敗はログ出力のみに留め、スクリプトの生成・公開は続行します。
+func (gr *GenerateRunner) writeMetadata(ctx context.Context, title, generated string) {
+	path := metadataPath(gr.options)
+	if path == "" || gr.writer == nil {
+		slog.WarnContext(ctx, "メタ情報の出力先が無いため、メタ情報の生成をスキップします。")
+		return
+	}
+
+	var titleLine string
+	if title != "" {
+		titleLine = "\n番組タイトル: " + title
+	}
+	prompt := fmt.Sprintf(metadataPrompt, titleLine, metadataDescriptionChars, metadataHashtags, generated)
+	resp, err := gr.aiClient.GenerateStructured(ctx, gr.options.AIModel, prompt, ai.MetadataSchema())
+	if err != nil {
+		slog.WarnContext(ctx, "メタ情報の生成に失敗しました。スクリプトの生成は続行します。", "error", err)
+		return
+	}
+	m, err := ai.DecodeMetadata(resp.Text)
+	if err != nil {
+		slog.WarnContext(ctx, "メタ情報の生成に失敗しました。スクリプトの生成は続行します。", "error", err)
+		return
+	}
+
+	body, err := json.MarshalIndent(scriptMetadata{Title: title, Description: m.Description, Hashtags: m.Hashtags}, "", "  ")
+	if err != nil {
+		slog.WarnContext(ctx, "メタ情報のエンコードに失敗しました。", "error", err)
+		return
+	}
+	if err := gr.writer.Write(ctx, path, bytes.NewReader(append(body, '\n')), "application/json"); err != nil {
+		slog.WarnContext(ctx, "メタ情報の書き込みに失敗しました。スクリプトの生成は続行します。", "path", path, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "メタ情報を出力しました。", "path", path, "description_chars", utf8.RuneCountInString(m.Description), "hashtags", len(m.Hashtags))
+}
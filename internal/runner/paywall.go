@@ -0,0 +1,82 @@
+package runner
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// paywallShortBodyRunes は抽出本文がこの文字数未満の場合に、本文が極端に短いとみなす閾値です。
+	paywallShortBodyRunes = 400
+	// paywallKeywordLineRatio は誘導文言を含む行の割合がこの値以上の場合に、本文の大半が誘導文言とみなす閾値です。
+	paywallKeywordLineRatio = 0.3
+)
+
+// paywallKeywords はペイウォールやログイン誘導でよく使われる文言です。小文字で比較します。
+var paywallKeywords = []string{
+	"この記事は有料",
+	"有料会員",
+	"有料記事",
+	"会員限定",
+	"続きを読むには",
+	"ログインして続きを",
+	"会員登録して続きを",
+	"購読すると",
+	"subscribe to continue",
+	"subscribe to read",
+	"subscribers only",
+	"sign in to continue",
+	"log in to continue",
+	"already a subscriber",
+}
+
+// paywallSignals は抽出結果からペイウォールらしき兆候を検査し、兆候の説明を返します。兆候が無い場合は nil を返します。
+// 誤検出を避けるため、誘導文言が見つかり、かつ本文が見つからない・極端に短い・大半が誘導文言のいずれかに当てはまる場合のみ兆候とみなします。
+func paywallSignals(text string, hasBodyFound bool) []string {
+	lower := strings.ToLower(text)
+	var keywords []string
+	for _, kw := range paywallKeywords {
+		if strings.Contains(lower, kw) {
+			keywords = append(keywords, kw)
+		}
+	}
+	if len(keywords) == 0 {
+		return nil
+	}
+
+	var signals []string
+	if !hasBodyFound {
+		signals = append(signals, "記事本文が見つからない")
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(text)) < paywallShortBodyRunes {
+		signals = append(signals, "本文が極端に短い")
+	}
+	if keywordLineRatio(lower, keywords) >= paywallKeywordLineRatio {
+		signals = append(signals, "本文の大半が誘導文言")
+	}
+	if len(signals) == 0 {
+		return nil
+	}
+	return append(signals, "誘導文言: "+strings.Join(keywords, ", "))
+}
+
+// keywordLineRatio は空行を除いた行のうち、いずれかの誘導文言を含む行の割合を返します。
+func keywordLineRatio(lower string, keywords []string) float64 {
+	var lines, hits int
+	for line := range strings.SplitSeq(lower, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		lines++
+		for _, kw := range keywords {
+			if strings.Contains(line, kw) {
+				hits++
+				break
+			}
+		}
+	}
+	if lines == 0 {
+		return 0
+	}
+	return float64(hits) / float64(lines)
+}
@@ -0,0 +1,34 @@
+package runner
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+// isPDFPath は入力パスの拡張子が .pdf かどうかを返します (大文字小文字は区別しません)。
+func isPDFPath(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".pdf")
+}
+
+// extractPDFText は PDF のバイト列からプレーンテキストを抽出します。各ページのテキストはそのまま連結します。
+// 2番目の戻り値は PDF のページ数です。
+func extractPDFText(data []byte) (string, int, error) {
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", 0, fmt.Errorf("PDFの解析に失敗しました: %w", err)
+	}
+	textReader, err := r.GetPlainText()
+	if err != nil {
+		return "", 0, fmt.Errorf("PDFのテキスト抽出に失敗しました: %w", err)
+	}
+	text, err := io.ReadAll(textReader)
+	if err != nil {
+		return "", 0, fmt.Errorf("PDFのテキスト抽出に失敗しました: %w", err)
+	}
+	return string(text), r.NumPage(), nil
+}
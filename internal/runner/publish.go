@@ -1,17 +1,22 @@
 package runner
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"log/slog"
 	"path/filepath"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/shouni/go-remote-io/remoteio"
 	"github.com/shouni/go-utils/iohandler"
-	"github.com/shouni/go-voicevox/voicevox"
 
 	"prototypus-ai-doc-go/internal/config"
+	"prototypus-ai-doc-go/internal/profile"
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
 )
 
 // PublishRunner は、スクリプトの公開処理を実行する具象構造体です。
@@ -32,31 +37,137 @@ func NewPublisherRunner(options *config.Config, voicevoxExecutor voicevox.Engine
 
 // Run は公開処理のパイプライン全体を実行します。
 func (pr *PublishRunner) Run(ctx context.Context, scriptContent string) error {
+	// 音声を合成する場合は、エンジンが実際の音声の長さからスクリプト統計を書き込む
+	if pr.options.StatsOutput != "" && (pr.options.VoicevoxOutput == "" || pr.options.DryRun) {
+		stats := voicevox.EstimateStats(ctx, scriptContent)
+		if err := voicevox.WriteStats(ctx, pr.writer, pr.options.StatsOutput, stats); err != nil {
+			return err
+		}
+	}
+	if pr.options.BalanceChart != "" {
+		if err := pr.writeBalanceChart(ctx, scriptContent); err != nil {
+			return err
+		}
+	}
+
 	if pr.options.VoicevoxOutput != "" {
+		if pr.options.DryRun {
+			return pr.estimateSynthesis(ctx, scriptContent)
+		}
 		return pr.publishAudioAndScript(ctx, scriptContent)
 	}
 
-	return iohandler.WriteOutputString(pr.options.OutputFile, scriptContent)
+	if pr.options.ReadableOutput && (pr.options.ReviewFormat || pr.options.ScriptFormat != "") {
+		return fmt.Errorf("--readable-output は --review-format・--script-format と同時に指定できません")
+	}
+	output := scriptContent
+	if pr.options.ReadableOutput {
+		output = script.Readable(scriptContent)
+	} else if pr.options.ReviewFormat {
+		if pr.options.ScriptFormat != "" {
+			return fmt.Errorf("--review-format と --script-format は同時に指定できません")
+		}
+		output = script.RenderReview(script.Parse(scriptContent))
+	} else if pr.options.ScriptFormat != "" {
+		converted, err := script.Convert(scriptContent, pr.options.ScriptFormat)
+		if err != nil {
+			return err
+		}
+		output = converted
+	}
+
+	return iohandler.WriteOutputString(pr.options.OutputFile, output)
 }
 
 // publishAudioAndScript は音声合成とスクリプトのアップロードを実行します。
 func (pr *PublishRunner) publishAudioAndScript(ctx context.Context, scriptContent string) error {
 	slog.InfoContext(ctx, "VOICEVOXによる音声合成を開始します。", "output_path", pr.options.VoicevoxOutput)
+	start := time.Now()
 	if err := pr.voicevoxExecutor.Execute(ctx, scriptContent, pr.options.VoicevoxOutput); err != nil {
 		return fmt.Errorf("音声合成パイプラインの実行に失敗しました (%s): %w", pr.options.VoicevoxOutput, err)
 	}
-	slog.InfoContext(ctx, "音声合成が完了しました。", "output_path", pr.options.VoicevoxOutput)
+	elapsed := time.Since(start)
+	slog.InfoContext(ctx, "音声合成が完了しました。", "output_path", pr.options.VoicevoxOutput, "elapsed", elapsed)
+	pr.recordThroughput(ctx, scriptContent, elapsed)
 
 	// スクリプトのアップロード
 	ext := filepath.Ext(pr.options.VoicevoxOutput)
 	txtPath := strings.TrimSuffix(pr.options.VoicevoxOutput, ext) + ".txt"
+	if pr.options.ReadableOutput {
+		scriptContent = script.Readable(scriptContent)
+	}
 	contentReader := strings.NewReader(scriptContent)
 
 	slog.InfoContext(ctx, "スクリプトのアップロードを開始します。", "upload_path", txtPath)
-	if err := pr.writer.Write(ctx, txtPath, contentReader, "text/plain; charset=utf-8"); err != nil {
+	stop := profile.Start(ctx, "upload_script")
+	err := pr.writer.Write(ctx, txtPath, contentReader, "text/plain; charset=utf-8")
+	stop()
+	if err != nil {
 		return fmt.Errorf("スクリプトのアップロードに失敗しました (%s): %w", txtPath, err)
 	}
 	slog.InfoContext(ctx, "スクリプトのアップロードが完了しました。", "upload_path", txtPath)
 
 	return nil
 }
+
+// writeBalanceChart はスクリプトの話者別の文字数・セグメント数を棒グラフ (SVG) として出力します。
+func (pr *PublishRunner) writeBalanceChart(ctx context.Context, scriptContent string) error {
+	balances := script.Balance(script.Parse(scriptContent))
+	for _, b := range balances {
+		slog.InfoContext(ctx, "話者別の登場バランス", "speaker", b.Speaker, "chars", b.Chars, "segments", b.Segments,
+			"first_half_chars", b.FirstHalfChars, "second_half_chars", b.SecondHalfChars)
+	}
+
+	chart := script.RenderBalanceChart(balances)
+	if err := pr.writer.Write(ctx, pr.options.BalanceChart, bytes.NewReader(chart), "image/svg+xml"); err != nil {
+		return fmt.Errorf("バランスチャートの書き込みに失敗しました (%s): %w", pr.options.BalanceChart, err)
+	}
+	slog.InfoContext(ctx, "バランスチャートを出力しました。", "path", pr.options.BalanceChart)
+	return nil
+}
+
+// estimateSynthesis は音声合成を行わず、セグメント数と過去の合成実行の実測スループットから合成の所要時間を見積もります (--dry-run)。
+func (pr *PublishRunner) estimateSynthesis(ctx context.Context, scriptContent string) error {
+	segments := script.Parse(scriptContent)
+	chars := countChars(segments)
+
+	rate := defaultSegmentsPerSecond
+	measured := false
+	if l, err := loadThroughputLog(); err != nil {
+		slog.WarnContext(ctx, "スループット記録を読み込めないため既定値で見積もります。", "error", err)
+	} else {
+		rate, measured = l.segmentsPerSecond()
+	}
+
+	estimated := time.Duration(float64(len(segments)) / rate * float64(time.Second)).Round(time.Second)
+	slog.InfoContext(ctx, "ドライランのため音声合成をスキップしました。合成の所要時間の見積もりです。",
+		"segments", len(segments), "chars", chars, "segments_per_sec", fmt.Sprintf("%.2f", rate),
+		"measured", measured, "estimated", estimated, "output_path", pr.options.VoicevoxOutput)
+	return nil
+}
+
+// recordThroughput は合成の実測スループットを次回の見積もりのために記録します。記録の失敗はログ出力のみに留めます。
+func (pr *PublishRunner) recordThroughput(ctx context.Context, scriptContent string, elapsed time.Duration) {
+	segments := script.Parse(scriptContent)
+	l, err := loadThroughputLog()
+	if err == nil {
+		err = l.record(throughputRecord{
+			Segments:   len(segments),
+			Chars:      countChars(segments),
+			Seconds:    elapsed.Seconds(),
+			RecordedAt: time.Now(),
+		})
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "合成スループットを記録できませんでした。", "error", err)
+	}
+}
+
+// countChars はセグメントの本文の文字数 (ルーン数) の合計を返します。
+func countChars(segments []script.Segment) int {
+	n := 0
+	for _, seg := range segments {
+		n += utf8.RuneCountInString(seg.Text)
+	}
+	return n
+}
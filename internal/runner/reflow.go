@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var (
+	// reReflowKeepLine は段落整形で前後の行と結合しない行 (見出し・リスト・引用・表など) です。
+	reReflowKeepLine = regexp.MustCompile(`^(#{1,6}\s|[-*+・●■◆]\s?|\d+[.)．、]\s?|[（(]\d+[)）]|[①-⑳]|>|\||第[0-9一二三四五六七八九十百]+[章節条項])`)
+	// sentenceEndings は文末とみなす文字です。この文字で終わる行の後では改行を保持します。
+	sentenceEndings = "。．！？!?」』）)：:"
+)
+
+// reflowParagraphs は PDF 抽出などで文の途中に入った改行を取り除き、文境界で段落を組み直します。
+// 空行・見出し・リストなどの行は保持し、文末記号で終わらない本文行だけを次の行と結合します。
+func reflowParagraphs(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+
+	var out []string
+	var current string
+	flush := func() {
+		if current != "" {
+			out = append(out, current)
+			current = ""
+		}
+	}
+
+	for _, raw := range lines {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "":
+			flush()
+			out = append(out, "")
+		case reReflowKeepLine.MatchString(line):
+			flush()
+			out = append(out, line)
+		case current == "":
+			current = line
+		default:
+			current = joinReflowLine(current, line)
+		}
+
+		if current != "" && endsSentence(current) {
+			flush()
+		}
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+// joinReflowLine は2行を結合します。英数字同士の境界では単語が繋がらないよう空白を挟みます。
+func joinReflowLine(prev, next string) string {
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+	if isASCIIWordRune(last) && isASCIIWordRune(first) {
+		return prev + " " + next
+	}
+	// 英文で行末がハイフンの場合は単語の途中で改行されたものとみなす
+	if last == '-' && isASCIIWordRune(first) {
+		return strings.TrimSuffix(prev, "-") + next
+	}
+	return prev + next
+}
+
+// endsSentence は行が文末記号で終わっているかを返します。
+func endsSentence(line string) bool {
+	last, _ := utf8.DecodeLastRuneInString(line)
+	return strings.ContainsRune(sentenceEndings, last)
+}
+
+// isASCIIWordRune は英数字かどうかを返します。
+func isASCIIWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == ',' || r == ';')
+}
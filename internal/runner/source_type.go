@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"net/url"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/config"
+)
+
+// sourceTypeProfile は入力ソースの種類ごとにプロンプトへ渡す表示名とトーン指示です。
+type sourceTypeProfile struct {
+	label string
+	tone  string
+}
+
+// sourceTypeProfiles は config.SourceType* に対応するプロファイルの定義です。
+var sourceTypeProfiles = map[string]sourceTypeProfile{
+	config.SourceTypeNews: {
+		label: "ニュース記事",
+		tone:  "いつ・誰が・何をしたのかという事実関係を正確に伝え、客観的で簡潔なトーンにしてください。憶測や誇張は避けてください。",
+	},
+	config.SourceTypePaper: {
+		label: "学術論文",
+		tone:  "研究の背景・手法・結果・限界を順序立てて説明し、専門用語の定義を補足しながら落ち着いた解説トーンにしてください。",
+	},
+	config.SourceTypeBlog: {
+		label: "ブログ記事",
+		tone:  "筆者の経験や主張を尊重しつつ、要点を噛み砕いた親しみやすいトーンにしてください。",
+	},
+	config.SourceTypeManual: {
+		label: "マニュアル・技術文書",
+		tone:  "手順や設定項目を正しい順序で説明し、各操作の目的と注意点が明確に伝わる実用的なトーンにしてください。",
+	},
+}
+
+// sourceTypeHostHints はURLのホスト名からソースタイプを推定するための手がかりです。
+// 先頭から順に評価し、最初に一致したものを採用します。
+var sourceTypeHostHints = []struct {
+	sourceType string
+	keywords   []string
+}{
+	{config.SourceTypePaper, []string{"arxiv.org", "doi.org", "acm.org", "ieee.org", "springer.com", "sciencedirect.com", "jstage.jst.go.jp", "cir.nii.ac.jp", "openreview.net"}},
+	{config.SourceTypeNews, []string{"news", "nhk.or.jp", "nikkei.com", "asahi.com", "yomiuri.co.jp", "mainichi.jp", "reuters.com", "bloomberg.", "bbc.", "cnn.com", "itmedia.co.jp", "impress.co.jp", "techcrunch.com"}},
+	{config.SourceTypeManual, []string{"docs.", "readthedocs", "pkg.go.dev", "developer.", "learn.microsoft.com", "manual"}},
+	{config.SourceTypeBlog, []string{"blog", "note.com", "zenn.dev", "qiita.com", "medium.com", "hatena", "dev.to", "substack.com"}},
+}
+
+// sourceTypePathHints はURLのパスからソースタイプを推定するための手がかりです。
+var sourceTypePathHints = []struct {
+	sourceType string
+	keywords   []string
+}{
+	{config.SourceTypeNews, []string{"/news/"}},
+	{config.SourceTypeManual, []string{"/docs/", "/manual/", "/reference/"}},
+	{config.SourceTypeBlog, []string{"/blog/", "/entry/", "/articles/"}},
+}
+
+// sourceTypeOGHints はページの og:type からソースタイプを推定するための対応表です。
+// og:type の "article" はニュースとブログの両方で使われるため、主要なニュースサイトはURLの手がかりで先に判定し、残りをブログとして扱います。
+var sourceTypeOGHints = map[string]string{
+	"blog":    config.SourceTypeBlog,
+	"article": config.SourceTypeBlog,
+}
+
+// resolveSourceType は明示指定を優先し、未指定の場合は入力URL、次にページの og:type からソースタイプを推定します。
+// 推定できない場合は空文字を返します。
+func resolveSourceType(explicit, rawURL, ogType string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if sourceType := sourceTypeFromURL(rawURL); sourceType != "" {
+		return sourceType
+	}
+	return sourceTypeOGHints[ogType]
+}
+
+// sourceTypeFromURL は入力URLのホスト名とパスからソースタイプを推定します。推定できない場合は空文字を返します。
+func sourceTypeFromURL(rawURL string) string {
+	if rawURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, hint := range sourceTypeHostHints {
+		for _, kw := range hint.keywords {
+			if strings.Contains(host, kw) {
+				return hint.sourceType
+			}
+		}
+	}
+	path := strings.ToLower(u.Path)
+	for _, hint := range sourceTypePathHints {
+		for _, kw := range hint.keywords {
+			if strings.Contains(path, kw) {
+				return hint.sourceType
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,86 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+// summarizePrompt は長すぎる入力の各部分を要約させる指示です。%d/%d は部分の番号と総数、%d は目安の文字数です。
+const summarizePrompt = `以下は長い文書を分割した第%d部 (全%d部) です。この後ナレーションスクリプトの素材として使うため、内容を要約してください。
+* 事実・数値・固有名詞・結論は省略せず、原文の表記のまま残してください。
+* 原文に無い情報や意見を付け足さないでください。
+* 見出しや箇条書きで構成を保ち、%d文字以内にまとめてください。
+* 前置きや説明は書かず、要約のみを出力してください。
+
+---
+%s`
+
+// exceedsInputLimit は入力が --max-input-chars の上限を超えているかを返します。上限が 0 以下の場合は制限しません。
+func (gr *GenerateRunner) exceedsInputLimit(content string) bool {
+	return gr.options.MaxInputChars > 0 && utf8.RuneCountInString(content) > gr.options.MaxInputChars
+}
+
+// summarizeInput は上限を超える入力を上限以下の部分に分割して部分ごとに AI に要約させ、要約を結合した入力を返します (--auto-summarize)。
+// 要約を結合しても上限を超える場合はエラーを返します。
+func (gr *GenerateRunner) summarizeInput(ctx context.Context, content string) (string, error) {
+	limit := gr.options.MaxInputChars
+	chunks := splitByParagraph(content, limit)
+	target := limit / len(chunks)
+	slog.InfoContext(ctx, "入力が上限を超えるため、分割して要約します。", "chars", utf8.RuneCountInString(content), "max_input_chars", limit, "parts", len(chunks))
+
+	summaries := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		prompt := fmt.Sprintf(summarizePrompt, i+1, len(chunks), target, chunk)
+		resp, err := gr.aiClient.GenerateContent(ctx, gr.options.AIModel, prompt)
+		if err != nil {
+			return "", fmt.Errorf("入力の要約に失敗しました (第%d部): %w", i+1, err)
+		}
+		summaries[i] = strings.TrimSpace(resp.Text)
+		slog.InfoContext(ctx, "入力の一部を要約しました。", "part", i+1, "parts", len(chunks), "chars", utf8.RuneCountInString(chunk), "summary_chars", utf8.RuneCountInString(summaries[i]))
+	}
+
+	summarized := strings.Join(summaries, "\n\n")
+	if gr.exceedsInputLimit(summarized) {
+		return "", fmt.Errorf("要約後の入力も長すぎます (%d文字、上限%d文字)。--max-input-chars を見直してください", utf8.RuneCountInString(summarized), limit)
+	}
+	return summarized, nil
+}
+
+// splitByParagraph はテキストを段落 (空行) の境界で、それぞれ limit 文字以下の部分に分割します。
+// limit を超える段落は文字数で区切ります。
+func splitByParagraph(text string, limit int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentLen := 0
+	flush := func() {
+		if currentLen > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentLen = 0
+		}
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		runes := []rune(para)
+		for len(runes) > limit {
+			flush()
+			chunks = append(chunks, string(runes[:limit]))
+			runes = runes[limit:]
+		}
+		// 段落の区切りの空行 (2文字) を含めて上限を超える場合は、新しい部分を始める
+		if currentLen > 0 && currentLen+2+len(runes) > limit {
+			flush()
+		}
+		if currentLen > 0 {
+			current.WriteString("\n\n")
+			currentLen += 2
+		}
+		current.WriteString(string(runes))
+		currentLen += len(runes)
+	}
+	flush()
+	return chunks
+}
@@ -0,0 +1,100 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// defaultSegmentsPerSecond は合成の実測記録が無い場合に見積もりに使うスループット (セグメント/秒) です。
+	defaultSegmentsPerSecond = 1.0
+	// maxThroughputRecords はスループットの見積もりに使う直近の合成実行の記録数です。
+	maxThroughputRecords = 10
+	// throughputFileName はユーザーのキャッシュディレクトリに置くスループット記録のファイル名です。
+	throughputFileName = "prototypus-ai-doc/throughput.json"
+)
+
+// throughputRecord は1回の合成実行の実測値です。
+type throughputRecord struct {
+	Segments   int       `json:"segments"`
+	Chars      int       `json:"chars"`
+	Seconds    float64   `json:"seconds"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// throughputLog は直近の合成実行の実測値の記録です。
+type throughputLog struct {
+	Records []throughputRecord `json:"records"`
+}
+
+// throughputPath はスループット記録のパスを返します。
+func throughputPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("キャッシュディレクトリを特定できません: %w", err)
+	}
+	return filepath.Join(dir, throughputFileName), nil
+}
+
+// loadThroughputLog はスループット記録を読み込みます。記録が無い場合は空の記録を返します。
+func loadThroughputLog() (*throughputLog, error) {
+	path, err := throughputPath()
+	if err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &throughputLog{}, nil
+		}
+		return nil, fmt.Errorf("スループット記録の読み込みに失敗しました (%s): %w", path, err)
+	}
+	var l throughputLog
+	if err := json.Unmarshal(body, &l); err != nil {
+		return nil, fmt.Errorf("スループット記録の解析に失敗しました (%s): %w", path, err)
+	}
+	return &l, nil
+}
+
+// record は合成実行の実測値を追加し、直近 maxThroughputRecords 件を残して保存します。
+func (l *throughputLog) record(r throughputRecord) error {
+	l.Records = append(l.Records, r)
+	if len(l.Records) > maxThroughputRecords {
+		l.Records = l.Records[len(l.Records)-maxThroughputRecords:]
+	}
+
+	path, err := throughputPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("スループット記録のディレクトリ作成に失敗しました: %w", err)
+	}
+	body, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return fmt.Errorf("スループット記録のエンコードに失敗しました: %w", err)
+	}
+	if err := os.WriteFile(path, body, 0o644); err != nil {
+		return fmt.Errorf("スループット記録の書き込みに失敗しました (%s): %w", path, err)
+	}
+	return nil
+}
+
+// segmentsPerSecond は記録された合成実行の合計から平均スループットを返します。記録が無い場合は既定値と false を返します。
+func (l *throughputLog) segmentsPerSecond() (float64, bool) {
+	var segments int
+	var seconds float64
+	for _, r := range l.Records {
+		segments += r.Segments
+		seconds += r.Seconds
+	}
+	if segments == 0 || seconds <= 0 {
+		return defaultSegmentsPerSecond, false
+	}
+	return float64(segments) / seconds, true
+}
@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/script"
+	"prototypus-ai-doc-go/internal/voicevox"
+)
+
+// validateScript は生成スクリプトを音声合成の前に検査し、タグの形式・既知の話者・空の本文の問題を行番号付きで警告します。
+// --strict-script が指定された場合、合成でセリフが失われる問題 (未知の話者・壊れた括弧) があればエラーを返します。
+func (gr *GenerateRunner) validateScript(ctx context.Context, generated string) error {
+	// 話者タグの表記揺れは合成時に正規化されるため、未知の話者として扱わない
+	normalized, _ := voicevox.NormalizeSpeakerAliases(generated)
+	issues := script.Validate(normalized, supportedSpeakerNames(), voicevox.SupportedStyleNames())
+	fatal := 0
+	for _, issue := range issues {
+		if issue.Fatal {
+			fatal++
+		}
+		slog.WarnContext(ctx, "生成スクリプトに問題があります。", "line", issue.Line, "problem", issue.Message, "fatal", issue.Fatal)
+	}
+	if fatal > 0 && gr.options.StrictScript {
+		return fmt.Errorf("生成スクリプトの検査で合成できない問題が %d 件見つかりました (最初の問題: %s)", fatal, firstFatal(issues))
+	}
+	return nil
+}
+
+// firstFatal は最初の Fatal な問題を返します。
+func firstFatal(issues []script.Issue) script.Issue {
+	for _, issue := range issues {
+		if issue.Fatal {
+			return issue
+		}
+	}
+	return script.Issue{}
+}
+
+// supportedSpeakerNames は括弧を除いた既知の話者名の一覧を返します。
+func supportedSpeakerNames() []string {
+	speakers := make([]string, 0, len(voicevox.SupportedSpeakers))
+	for _, sp := range voicevox.SupportedSpeakers {
+		speakers = append(speakers, script.TrimBrackets(sp.ToolTag))
+	}
+	return speakers
+}
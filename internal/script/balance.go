@@ -0,0 +1,98 @@
+package script
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// untaggedSpeakerName はタグの無いテキストを集計する際の話者名です。
+const untaggedSpeakerName = "(タグなし)"
+
+// SpeakerBalance は話者ごとの登場量の集計です。
+type SpeakerBalance struct {
+	Speaker  string `json:"speaker"`
+	Chars    int    `json:"chars"`
+	Segments int    `json:"segments"`
+	// FirstHalfChars と SecondHalfChars はスクリプト全体の文字数の前半・後半に分けた文字数です。
+	FirstHalfChars  int `json:"first_half_chars"`
+	SecondHalfChars int `json:"second_half_chars"`
+}
+
+// Balance はセグメントを話者ごとに集計し、登場順に並べて返します。
+// 前半・後半はスクリプト全体の文字数の中間点より前に始まるかどうかで振り分けます。
+func Balance(segments []Segment) []SpeakerBalance {
+	total := 0
+	for _, seg := range segments {
+		total += utf8.RuneCountInString(seg.Text)
+	}
+
+	var result []SpeakerBalance
+	index := make(map[string]int)
+	offset := 0
+	for _, seg := range segments {
+		name := seg.Speaker()
+		if name == "" {
+			name = untaggedSpeakerName
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(result)
+			index[name] = i
+			result = append(result, SpeakerBalance{Speaker: name})
+		}
+
+		n := utf8.RuneCountInString(seg.Text)
+		b := &result[i]
+		b.Chars += n
+		b.Segments++
+		if offset*2 < total {
+			b.FirstHalfChars += n
+		} else {
+			b.SecondHalfChars += n
+		}
+		offset += n
+	}
+	return result
+}
+
+// balanceChart* は登場バランスの棒グラフのレイアウトです。
+const (
+	balanceChartWidth     = 720
+	balanceChartLabelW    = 140
+	balanceChartBarMaxW   = 440
+	balanceChartRowHeight = 56
+	balanceChartTop       = 56
+)
+
+// RenderBalanceChart は話者別の文字数 (前半・後半の内訳付き) とセグメント数を横棒グラフの SVG 画像として描画します。
+func RenderBalanceChart(balances []SpeakerBalance) []byte {
+	maxChars := 1
+	for _, b := range balances {
+		maxChars = max(maxChars, b.Chars)
+	}
+	height := balanceChartTop + len(balances)*balanceChartRowHeight + 40
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="13">`+"\n", balanceChartWidth, height)
+	fmt.Fprintf(&sb, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(&sb, `<text x="16" y="28" font-size="16" font-weight="bold">話者別の登場バランス（文字数・セグメント数）</text>`+"\n")
+
+	for i, b := range balances {
+		y := balanceChartTop + i*balanceChartRowHeight
+		firstW := b.FirstHalfChars * balanceChartBarMaxW / maxChars
+		secondW := b.SecondHalfChars * balanceChartBarMaxW / maxChars
+		fmt.Fprintf(&sb, `<text x="16" y="%d">%s</text>`+"\n", y+20, html.EscapeString(b.Speaker))
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="28" fill="#4e79a7"/>`+"\n", balanceChartLabelW, y, firstW)
+		fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%d" height="28" fill="#a0cbe8"/>`+"\n", balanceChartLabelW+firstW, y, secondW)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d">%d字 / %dセグメント</text>`+"\n", balanceChartLabelW+firstW+secondW+8, y+20, b.Chars, b.Segments)
+		fmt.Fprintf(&sb, `<text x="%d" y="%d" font-size="11" fill="#555555">前半 %d字・後半 %d字</text>`+"\n", balanceChartLabelW, y+42, b.FirstHalfChars, b.SecondHalfChars)
+	}
+
+	legendY := height - 20
+	fmt.Fprintf(&sb, `<rect x="16" y="%d" width="12" height="12" fill="#4e79a7"/><text x="34" y="%d">前半</text>`+"\n", legendY-10, legendY)
+	fmt.Fprintf(&sb, `<rect x="80" y="%d" width="12" height="12" fill="#a0cbe8"/><text x="98" y="%d">後半</text>`+"\n", legendY-10, legendY)
+	sb.WriteString("</svg>\n")
+	return []byte(sb.String())
+}
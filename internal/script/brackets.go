@@ -0,0 +1,81 @@
+package script
+
+import (
+	"regexp"
+	"strings"
+)
+
+// StrayBracket* は --stray-brackets に指定できる、本文中の定義済みタグ以外の括弧表現の扱いです。
+const (
+	// StrayBracketWarn は括弧表現を残したまま、行番号とともに警告します。
+	StrayBracketWarn = "warn"
+	// StrayBracketUnwrap は括弧だけを外し、中の語句を本文として残します (例: [補足] → 補足)。
+	StrayBracketUnwrap = "unwrap"
+	// StrayBracketStrip は括弧表現を中の語句ごと除去します。
+	StrayBracketStrip = "strip"
+)
+
+// StrayBracketModes は --stray-brackets に指定できる値の一覧です。
+var StrayBracketModes = []string{StrayBracketWarn, StrayBracketUnwrap, StrayBracketStrip}
+
+// reBracket は全角・半角の角括弧と隅付き括弧で囲まれた表現を検出します。
+var reBracket = regexp.MustCompile(`[\[［【]([^\[\]［］【】]+)[\]］】]`)
+
+// StrayBracket は本文中で見つかった、定義済みのタグではない括弧表現です。
+type StrayBracket struct {
+	// Line は括弧表現のある行の行番号 (1始まり) です。
+	Line int
+	// Text は括弧を含む括弧表現です (例: "[補足]")。
+	Text string
+}
+
+// FixStrayBrackets は本文中の、話者・スタイル・感情・音響効果・合成パラメータのタグと結論マーカーのいずれでもない括弧表現を探し、
+// mode に従って処理したスクリプトと、見つかった括弧表現を返します。
+// このような括弧表現は、短ければ未知のタグとして合成時に除去され、長ければタグらしき表記のまま読み上げられるため、
+// AI が説明のために使った [補足] のような表現が意図せず失われたり残ったりします。
+// mode が StrayBracketWarn の場合はスクリプトを変更しません。行頭の話者・スタイル・合成パラメータのタグは対象にしません。
+func FixStrayBrackets(content, mode string) (string, []StrayBracket) {
+	var found []StrayBracket
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		leading := leadingTagCount(line)
+		var seen int
+		lines[i] = reBracket.ReplaceAllStringFunc(line, func(expr string) string {
+			if expr == ConclusionOpen || expr == ConclusionClose {
+				return expr
+			}
+			if seen++; seen <= leading || isDefinedTag(expr) {
+				return expr
+			}
+			found = append(found, StrayBracket{Line: i + 1, Text: expr})
+			switch mode {
+			case StrayBracketUnwrap:
+				return reBracket.FindStringSubmatch(expr)[1]
+			case StrayBracketStrip:
+				return ""
+			}
+			return expr
+		})
+	}
+	if mode == StrayBracketWarn || len(found) == 0 {
+		return content, found
+	}
+	return strings.Join(lines, "\n"), found
+}
+
+// leadingTagCount は行頭の話者・スタイル・合成パラメータのタグの数を返します。結論マーカーと箇条書きの記号は数えません。
+func leadingTagCount(line string) int {
+	line = trimListMarker(strings.TrimSpace(strings.NewReplacer(ConclusionOpen, "", ConclusionClose, "").Replace(line)))
+	_, _, rest, ok := splitTaggedLine(line)
+	if !ok {
+		return 0
+	}
+	_, text := parseProsody(rest)
+	return len(reBracket.FindAllString(line[:len(line)-len(text)], -1))
+}
+
+// isDefinedTag は本文中に書かれる感情・音響効果のタグ、または表記揺れを含む話者・スタイル・感情タグかどうかを返します。
+// これらは合成時に意図して除去・解釈されるため、括弧表現の検査の対象にしません。
+func isDefinedTag(expr string) bool {
+	return reEmotionParse.MatchString(expr) || reEffectParse.MatchString(expr) || reLeakedTag.MatchString(expr)
+}
@@ -0,0 +1,86 @@
+package script
+
+import (
+	"regexp"
+	"strings"
+)
+
+// reFactNumber はファクトチェックで照合する数値 (桁区切り・小数を含む) を検出します。
+var reFactNumber = regexp.MustCompile(`[0-9]+(?:[.,][0-9]+)*`)
+
+// reFactTerm はファクトチェックで照合する固有名詞らしい語 (3文字以上のカタカナ語、英字で始まる英数字の語) を検出します。
+// 漢字語は言い換えで表記が変わりやすく誤検出が多いため対象にしません。
+var reFactTerm = regexp.MustCompile(`[\p{Katakana}ー]{3,}|[A-Za-z][A-Za-z0-9+#.\-]+`)
+
+// reSentenceEnd は文の区切りとなる文末記号です。
+var reSentenceEnd = regexp.MustCompile(`[^。！？!?]+[。！？!?]*`)
+
+// factTermStopwords は入力に無くても説明の言い回しとして現れやすいカタカナ語です。
+var factTermStopwords = map[string]bool{
+	"ポイント": true, "テーマ": true, "ニュース": true, "トピック": true, "イメージ": true, "メリット": true,
+	"デメリット": true, "ケース": true, "チェック": true, "リスナー": true, "エピソード": true,
+}
+
+// UnsupportedClaim は入力テキストに根拠が見当たらない文です。
+type UnsupportedClaim struct {
+	// Line はスクリプトの行番号 (1始まり) です。
+	Line     int
+	Sentence string
+	// Terms は入力テキストに見当たらない数値・語です。
+	Terms []string
+}
+
+// FactCheck はスクリプトの各文に含まれる数値と固有名詞らしい語を入力テキストと照合し、根拠が見当たらない文を返します。
+// 入力に無い数値を含む文、または照合した語 (2語以上) の過半数が入力に無い文を、AI が付け足した可能性のある文とみなします。
+// 表記の揺れ (全角・半角、英字の大文字・小文字、桁区切り) は区別しません。意味の照合は行わないため、明らかな創作を見つけるための補助です。
+func FactCheck(scriptContent, source string) []UnsupportedClaim {
+	src := normalizeFactText(source)
+	srcNumbers := make(map[string]bool)
+	for _, n := range reFactNumber.FindAllString(src, -1) {
+		srcNumbers[strings.ReplaceAll(n, ",", "")] = true
+	}
+
+	var claims []UnsupportedClaim
+	for _, seg := range Parse(scriptContent) {
+		for _, sentence := range reSentenceEnd.FindAllString(seg.Text, -1) {
+			sentence = strings.TrimSpace(sentence)
+			text := normalizeFactText(sentence)
+
+			var missing []string
+			numberMissing := false
+			for _, n := range reFactNumber.FindAllString(text, -1) {
+				if !srcNumbers[strings.ReplaceAll(n, ",", "")] {
+					missing = append(missing, n)
+					numberMissing = true
+				}
+			}
+			terms, termsMissing := 0, 0
+			for _, t := range reFactTerm.FindAllString(text, -1) {
+				t = strings.TrimRight(t, ".-")
+				if factTermStopwords[t] || len([]rune(t)) < 2 {
+					continue
+				}
+				terms++
+				if !strings.Contains(src, t) {
+					missing = append(missing, t)
+					termsMissing++
+				}
+			}
+
+			if numberMissing || (terms >= 2 && termsMissing*2 > terms) {
+				claims = append(claims, UnsupportedClaim{Line: seg.Line, Sentence: sentence, Terms: missing})
+			}
+		}
+	}
+	return claims
+}
+
+// normalizeFactText は照合のため、全角英数字を半角に、英字を小文字に揃えます。
+func normalizeFactText(s string) string {
+	return strings.ToLower(strings.Map(func(r rune) rune {
+		if r >= '！' && r <= '～' {
+			return r - '！' + '!'
+		}
+		return r
+	}, s))
+}
@@ -0,0 +1,141 @@
+package script
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ForbiddenTopic はスクリプトに含めてはならないトピックの定義です。
+type ForbiddenTopic struct {
+	// Name はトピック名です (例: "医療アドバイス")。
+	Name string `yaml:"-"`
+	// Keywords はトピックを示す語句です。英字の大文字・小文字は区別しません。
+	Keywords []string `yaml:"keywords"`
+	// Exclude のいずれかを含む行では、キーワードに一致しても数えません (例: 「投資助言ではありません」のような注意書き)。
+	Exclude []string `yaml:"exclude"`
+	// MinMatches は検出とみなすのに必要な、一致した異なるキーワードの数です。0 の場合は 1 です。
+	MinMatches int `yaml:"min_matches"`
+}
+
+// TopicViolation はスクリプトで検出された禁止トピックです。
+type TopicViolation struct {
+	Topic string
+	// Keywords は一致したキーワードです。
+	Keywords []string
+	// Lines はキーワードに一致した行の行番号 (1始まり) です。
+	Lines []int
+}
+
+// String は「トピック (キーワード: ...; 行: ...)」の形式で検出理由を返します。
+func (v TopicViolation) String() string {
+	lines := make([]string, len(v.Lines))
+	for i, l := range v.Lines {
+		lines[i] = fmt.Sprint(l)
+	}
+	return fmt.Sprintf("%s (キーワード: %s; 行: %s)", v.Topic, strings.Join(v.Keywords, ", "), strings.Join(lines, ", "))
+}
+
+// LoadForbiddenTopics は "トピック名: {keywords, exclude, min_matches}" 形式の YAML から禁止トピックを読み込みます。
+//
+//	投資助言:
+//	  keywords: [必ず儲かる, 買うべき銘柄, 元本保証]
+//	  exclude: [投資助言ではありません]
+//	  min_matches: 1
+func LoadForbiddenTopics(r io.Reader) ([]ForbiddenTopic, error) {
+	var entries map[string]ForbiddenTopic
+	if err := yaml.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("禁止トピックの解析に失敗しました: %w", err)
+	}
+
+	var topics []ForbiddenTopic
+	for name, t := range entries {
+		t.Name = strings.TrimSpace(name)
+		t.Keywords = normalizePhrases(t.Keywords)
+		t.Exclude = normalizePhrases(t.Exclude)
+		if len(t.Keywords) == 0 {
+			return nil, fmt.Errorf("禁止トピック '%s' にキーワードがありません", t.Name)
+		}
+		if t.MinMatches > len(t.Keywords) {
+			return nil, fmt.Errorf("禁止トピック '%s' の min_matches (%d) がキーワードの数 (%d) を超えています", t.Name, t.MinMatches, len(t.Keywords))
+		}
+		topics = append(topics, t)
+	}
+	slices.SortFunc(topics, func(a, b ForbiddenTopic) int { return cmp.Compare(a.Name, b.Name) })
+	return topics, nil
+}
+
+// normalizePhrases は語句の前後の空白を除いて小文字にし、空の語句と重複を取り除きます。
+func normalizePhrases(phrases []string) []string {
+	var out []string
+	for _, p := range phrases {
+		if p = strings.ToLower(strings.TrimSpace(p)); p != "" && !slices.Contains(out, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// DetectForbiddenTopics はスクリプトの本文から禁止トピックを検出します。行頭の話者・スタイルタグは対象外です。
+// 誤検出を抑えるため、英数字やカタカナの語の一部に一致した場合 (例: "AI" に対する "EMAIL") は数えません。
+func DetectForbiddenTopics(content string, topics []ForbiddenTopic) []TopicViolation {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		if _, _, rest, ok := splitTaggedLine(line); ok {
+			line = rest
+		}
+		lines[i] = strings.ToLower(line)
+	}
+
+	var violations []TopicViolation
+	for _, t := range topics {
+		v := TopicViolation{Topic: t.Name}
+		for i, line := range lines {
+			if slices.ContainsFunc(t.Exclude, func(ex string) bool { return strings.Contains(line, ex) }) {
+				continue
+			}
+			matched := false
+			for _, kw := range t.Keywords {
+				if !containsWord(line, kw) {
+					continue
+				}
+				matched = true
+				if !slices.Contains(v.Keywords, kw) {
+					v.Keywords = append(v.Keywords, kw)
+				}
+			}
+			if matched {
+				v.Lines = append(v.Lines, i+1)
+			}
+		}
+		if len(v.Keywords) >= max(t.MinMatches, 1) {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+// containsWord は text に word が、前後を同じ種類の文字 (英数字・カタカナ) で続けずに含まれるかを返します。
+func containsWord(text, word string) bool {
+	first, _ := utf8.DecodeRuneInString(word)
+	last, _ := utf8.DecodeLastRuneInString(word)
+	for pos := 0; ; {
+		i := strings.Index(text[pos:], word)
+		if i < 0 {
+			return false
+		}
+		start := pos + i
+		end := start + len(word)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !sameWordClass(before, first)) && (end == len(text) || !sameWordClass(after, last)) {
+			return true
+		}
+		pos = start + 1
+	}
+}
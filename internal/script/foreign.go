@@ -0,0 +1,133 @@
+package script
+
+import (
+	"regexp"
+	"strings"
+)
+
+// 外来語 (英語) 区間の読み上げ方法です。
+const (
+	// ForeignWordKeep は英語区間をそのまま VOICEVOX に渡し、エンジンの読みに任せます。
+	ForeignWordKeep = "keep"
+	// ForeignWordKatakana は英語区間をカタカナ読みに変換してから合成します。
+	ForeignWordKatakana = "katakana"
+)
+
+// ForeignWordModes は --foreign-word-handling に指定できる値の一覧です。
+var ForeignWordModes = []string{ForeignWordKeep, ForeignWordKatakana}
+
+// reForeignWord は英字の連続 (途中のハイフン・アポストロフィを含む) を英語の語として検出します。
+// 数字は語に含めず、"Go1.26" は "Go" と "1.26" に分けて扱います。
+var reForeignWord = regexp.MustCompile(`[A-Za-z]+(?:['\-][A-Za-z]+)*`)
+
+// katakanaDictionary は技術記事で頻出する英単語のカタカナ読みです。キーは小文字です。
+var katakanaDictionary = map[string]string{
+	"ai":         "エーアイ",
+	"api":        "エーピーアイ",
+	"async":      "アシンク",
+	"aws":        "エーダブリューエス",
+	"backend":    "バックエンド",
+	"bug":        "バグ",
+	"cache":      "キャッシュ",
+	"channel":    "チャネル",
+	"cli":        "シーエルアイ",
+	"client":     "クライアント",
+	"cloud":      "クラウド",
+	"code":       "コード",
+	"context":    "コンテキスト",
+	"cpu":        "シーピーユー",
+	"css":        "シーエスエス",
+	"data":       "データ",
+	"database":   "データベース",
+	"deploy":     "デプロイ",
+	"docker":     "ドッカー",
+	"error":      "エラー",
+	"framework":  "フレームワーク",
+	"frontend":   "フロントエンド",
+	"function":   "ファンクション",
+	"gcp":        "ジーシーピー",
+	"gemini":     "ジェミニ",
+	"git":        "ギット",
+	"github":     "ギットハブ",
+	"go":         "ゴー",
+	"golang":     "ゴーラング",
+	"google":     "グーグル",
+	"goroutine":  "ゴルーチン",
+	"gpu":        "ジーピーユー",
+	"html":       "エイチティーエムエル",
+	"http":       "エイチティーティーピー",
+	"https":      "エイチティーティーピーエス",
+	"interface":  "インターフェース",
+	"java":       "ジャバ",
+	"javascript": "ジャバスクリプト",
+	"json":       "ジェイソン",
+	"kubernetes": "クーバネティス",
+	"library":    "ライブラリ",
+	"linux":      "リナックス",
+	"llm":        "エルエルエム",
+	"memory":     "メモリ",
+	"model":      "モデル",
+	"mutex":      "ミューテックス",
+	"open":       "オープン",
+	"package":    "パッケージ",
+	"pipeline":   "パイプライン",
+	"prompt":     "プロンプト",
+	"python":     "パイソン",
+	"rust":       "ラスト",
+	"server":     "サーバー",
+	"sql":        "エスキューエル",
+	"sdk":        "エスディーケー",
+	"source":     "ソース",
+	"test":       "テスト",
+	"thread":     "スレッド",
+	"typescript": "タイプスクリプト",
+	"url":        "ユーアールエル",
+	"voicevox":   "ボイスボックス",
+	"web":        "ウェブ",
+	"yaml":       "ヤムル",
+}
+
+// letterReadings はアルファベット1文字の読みです。略語を1文字ずつ読む場合に使います。
+var letterReadings = map[rune]string{
+	'a': "エー", 'b': "ビー", 'c': "シー", 'd': "ディー", 'e': "イー", 'f': "エフ", 'g': "ジー",
+	'h': "エイチ", 'i': "アイ", 'j': "ジェー", 'k': "ケー", 'l': "エル", 'm': "エム", 'n': "エヌ",
+	'o': "オー", 'p': "ピー", 'q': "キュー", 'r': "アール", 's': "エス", 't': "ティー", 'u': "ユー",
+	'v': "ブイ", 'w': "ダブリュー", 'x': "エックス", 'y': "ワイ", 'z': "ゼット",
+}
+
+// acronymMaxLen は辞書に無い英大文字のみの語を略語とみなして1文字ずつ読む最大の長さです。
+const acronymMaxLen = 5
+
+// ToKatakanaReading はテキスト中の英語区間をカタカナ読みに変換し、変換できなかった語とともに返します。
+// 辞書にある語は辞書の読みに、辞書に無い英大文字のみの短い語 (略語) は1文字ずつの読みに変換します。
+// それ以外の語は誤った読みを避けるため変換せず、エンジンの読みに任せます。
+func ToKatakanaReading(text string) (string, []string) {
+	var unresolved []string
+	converted := reForeignWord.ReplaceAllStringFunc(text, func(word string) string {
+		if reading, ok := katakanaDictionary[strings.ToLower(word)]; ok {
+			return reading
+		}
+		if reading, ok := acronymReading(word); ok {
+			return reading
+		}
+		unresolved = append(unresolved, word)
+		return word
+	})
+	return converted, unresolved
+}
+
+// acronymReading は英大文字のみからなる短い語を1文字ずつの読みに変換します。
+func acronymReading(word string) (string, bool) {
+	if len(word) > acronymMaxLen || strings.ToUpper(word) != word {
+		return "", false
+	}
+	var b strings.Builder
+	for _, r := range strings.ToLower(word) {
+		reading, ok := letterReadings[r]
+		if !ok {
+			return "", false
+		}
+		b.WriteString(reading)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,127 @@
+package script
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Format* は生成スクリプトの出力形式です。
+const (
+	// FormatPlain は "話者: テキスト" 形式です。
+	FormatPlain = "plain"
+	// FormatJSON はセグメント配列のJSON形式です。
+	FormatJSON = "json"
+	// FormatXML は <speaker name="..."> 要素を並べたXML形式です。
+	FormatXML = "xml"
+	// FormatVoicevox は "[話者][スタイル] テキスト" 形式に正規化したものです。
+	FormatVoicevox = "voicevox"
+	// FormatMarkdown は話者名を太字に、感情タグを括弧の注釈にした台本形式の Markdown です。
+	FormatMarkdown = "markdown"
+)
+
+// Formats は指定可能な出力形式の一覧です。
+var Formats = []string{FormatPlain, FormatJSON, FormatXML, FormatVoicevox, FormatMarkdown}
+
+// jsonSegment は JSON 出力における1セグメントの表現です。
+type jsonSegment struct {
+	Speaker string `json:"speaker"`
+	Style   string `json:"style"`
+	Text    string `json:"text"`
+}
+
+// xmlScript は XML 出力のルート要素です。
+type xmlScript struct {
+	XMLName  xml.Name     `xml:"script"`
+	Speakers []xmlSpeaker `xml:"speaker"`
+}
+
+// xmlSpeaker は XML 出力における1セグメントの表現です。
+type xmlSpeaker struct {
+	Name  string `xml:"name,attr"`
+	Style string `xml:"style,attr,omitempty"`
+	Text  string `xml:",chardata"`
+}
+
+// Convert はスクリプトを解析し、指定された形式の文字列に変換します。
+func Convert(script, format string) (string, error) {
+	segments := Parse(script)
+
+	switch format {
+	case FormatPlain:
+		var sb strings.Builder
+		for _, seg := range segments {
+			if seg.SpeakerTag == "" {
+				fmt.Fprintf(&sb, "%s\n", seg.Text)
+				continue
+			}
+			fmt.Fprintf(&sb, "%s: %s\n", seg.Speaker(), seg.Text)
+		}
+		return sb.String(), nil
+
+	case FormatJSON:
+		out := make([]jsonSegment, 0, len(segments))
+		for _, seg := range segments {
+			out = append(out, jsonSegment{Speaker: seg.Speaker(), Style: seg.Style(), Text: seg.Text})
+		}
+		var sb strings.Builder
+		enc := json.NewEncoder(&sb)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return "", fmt.Errorf("スクリプトのJSON変換に失敗しました: %w", err)
+		}
+		return sb.String(), nil
+
+	case FormatXML:
+		out := xmlScript{Speakers: make([]xmlSpeaker, 0, len(segments))}
+		for _, seg := range segments {
+			out.Speakers = append(out.Speakers, xmlSpeaker{Name: seg.Speaker(), Style: seg.Style(), Text: seg.Text})
+		}
+		b, err := xml.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("スクリプトのXML変換に失敗しました: %w", err)
+		}
+		return xml.Header + string(b) + "\n", nil
+
+	case FormatVoicevox:
+		return Render(segments), nil
+
+	case FormatMarkdown:
+		// 感情タグを注釈として残すため、解析前のスクリプトから変換する
+		return RenderMarkdown(script), nil
+
+	default:
+		return "", fmt.Errorf("不明なスクリプト形式です: '%s' (%s のいずれかを指定してください)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Render はセグメントを "[話者][スタイル] テキスト" 形式のスクリプトに書き出します。合成パラメータ・音響効果・結論マーカーはタグとして残します。Parse で元のセグメントに戻せます。
+func Render(segments []Segment) string {
+	var sb strings.Builder
+	for i, seg := range segments {
+		text := renderEffects(seg.Effects) + seg.Text
+		if seg.Conclusion && (i == 0 || !segments[i-1].Conclusion) {
+			text = ConclusionOpen + text
+		}
+		if seg.Conclusion && (i == len(segments)-1 || !segments[i+1].Conclusion) {
+			text += ConclusionClose
+		}
+		if seg.SpeakerTag == "" {
+			fmt.Fprintf(&sb, "%s\n", text)
+			continue
+		}
+		fmt.Fprintf(&sb, "%s%s%s %s\n", seg.SpeakerTag, seg.StyleTag, renderProsody(seg.Prosody), text)
+	}
+	return sb.String()
+}
+
+// renderEffects は音響効果をタグとして書き出します。Parse で再び取り出せます。
+func renderEffects(effects []string) string {
+	var sb strings.Builder
+	for _, effect := range effects {
+		sb.WriteString("[" + effect + "]")
+	}
+	return sb.String()
+}
@@ -0,0 +1,154 @@
+package script
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Glossary は用語の正規形と表記揺れの対応を持つ辞書です。
+type Glossary struct {
+	rules []glossaryRule
+}
+
+// glossaryRule は1つの表記揺れを正規形に置き換える規則です。
+type glossaryRule struct {
+	canonical string
+	variant   string
+}
+
+// Replacement は表記統一で置き換えた箇所の集計です。
+type Replacement struct {
+	Variant   string
+	Canonical string
+	Count     int
+}
+
+// LoadGlossary は "正規形: [表記揺れ, ...]" 形式の YAML から Glossary を読み込みます。
+//
+//	人工知能:
+//	  - AI
+//	  - エーアイ
+func LoadGlossary(r io.Reader) (*Glossary, error) {
+	var entries map[string][]string
+	if err := yaml.NewDecoder(r).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("用語辞書の解析に失敗しました: %w", err)
+	}
+
+	g := &Glossary{}
+	for canonical, variants := range entries {
+		canonical = strings.TrimSpace(canonical)
+		for _, v := range variants {
+			v = strings.TrimSpace(v)
+			if v == "" || v == canonical {
+				continue
+			}
+			g.rules = append(g.rules, glossaryRule{canonical: canonical, variant: v})
+		}
+	}
+	// 長い表記揺れから順に置き換え、短い表記が長い表記の一部を先に置き換えてしまうのを防ぐ
+	slices.SortFunc(g.rules, func(a, b glossaryRule) int {
+		if c := cmp.Compare(utf8.RuneCountInString(b.variant), utf8.RuneCountInString(a.variant)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.variant, b.variant)
+	})
+	return g, nil
+}
+
+// Apply はスクリプトの本文の表記揺れを正規形に統一し、置き換えた箇所の集計とともに返します。
+// 行頭の話者・スタイルタグは置き換えの対象外です。
+func (g *Glossary) Apply(script string) (string, []Replacement) {
+	counts := make(map[glossaryRule]int)
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		prefix, text := "", line
+		if _, _, rest, ok := splitTaggedLine(line); ok {
+			prefix, text = line[:len(line)-len(rest)], rest
+		}
+		for _, rule := range g.rules {
+			var n int
+			text, n = rule.replace(text)
+			counts[rule] += n
+		}
+		lines[i] = prefix + text
+	}
+
+	var report []Replacement
+	for _, rule := range g.rules {
+		if n := counts[rule]; n > 0 {
+			report = append(report, Replacement{Variant: rule.variant, Canonical: rule.canonical, Count: n})
+		}
+	}
+	return strings.Join(lines, "\n"), report
+}
+
+// replace はテキスト中の表記揺れを正規形に置き換え、置き換えた数を返します。
+// 英数字やカタカナの語の一部に一致した場合 (例: "AI" に対する "EMAIL") と、既に正規形の一部である場合は置き換えません。
+func (r glossaryRule) replace(text string) (string, int) {
+	var sb strings.Builder
+	count := 0
+	pos := 0
+	for {
+		i := strings.Index(text[pos:], r.variant)
+		if i < 0 {
+			break
+		}
+		start := pos + i
+		end := start + len(r.variant)
+		if !r.atWordBoundary(text, start, end) || r.insideCanonical(text, start, end) {
+			sb.WriteString(text[pos:end])
+			pos = end
+			continue
+		}
+		sb.WriteString(text[pos:start])
+		sb.WriteString(r.canonical)
+		count++
+		pos = end
+	}
+	sb.WriteString(text[pos:])
+	return sb.String(), count
+}
+
+// atWordBoundary は一致箇所の前後が、表記揺れの先頭・末尾の文字と同じ種類の文字 (英数字・カタカナ) で続いていないかを判定します。
+func (r glossaryRule) atWordBoundary(text string, start, end int) bool {
+	first, _ := utf8.DecodeRuneInString(r.variant)
+	last, _ := utf8.DecodeLastRuneInString(r.variant)
+	if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && sameWordClass(before, first) {
+		return false
+	}
+	if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && sameWordClass(after, last) {
+		return false
+	}
+	return true
+}
+
+// insideCanonical は一致箇所が既に正規形の表記の一部かを判定します (例: 正規形 "生成AI" 中の "AI")。
+func (r glossaryRule) insideCanonical(text string, start, end int) bool {
+	offset := strings.Index(r.canonical, r.variant)
+	if offset < 0 {
+		return false
+	}
+	cStart := start - offset
+	cEnd := cStart + len(r.canonical)
+	return cStart >= 0 && cEnd <= len(text) && text[cStart:cEnd] == r.canonical
+}
+
+// sameWordClass は2つの文字が、語の境界判定において同じ語を構成する種類かを返します。
+func sameWordClass(a, b rune) bool {
+	isWord := func(r rune) bool { return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)) }
+	isKatakana := func(r rune) bool { return unicode.Is(unicode.Katakana, r) || r == 'ー' }
+	switch {
+	case isWord(a) && isWord(b):
+		return true
+	case isKatakana(a) && isKatakana(b):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,68 @@
+package script
+
+import "strings"
+
+// RenderMarkdown はスクリプトを、合成せずに台本として配布するための読みやすい Markdown に書き出します (--script-format markdown)。
+// 話者タグは太字の話者名に、本文中の感情・音響効果のタグは全角括弧の注釈 (例: "（驚き）") に変換し、発話ごとに段落を分けます。
+// スタイル・合成パラメータのタグは読み手に不要なため省略し、結論マーカーで囲まれた区間は引用として書き出します。
+// 感情タグを注釈として残すため、Parse で除去される前の行から変換します。タグの無い継続行は直前の発話の段落に結合します。
+func RenderMarkdown(content string) string {
+	var paragraphs []string
+	var current string
+	inConclusion := false
+	flush := func() {
+		if current == "" {
+			return
+		}
+		if inConclusion {
+			current = "> " + current
+		}
+		paragraphs = append(paragraphs, current)
+		current = ""
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		opens, closes := strings.Contains(line, ConclusionOpen), strings.Contains(line, ConclusionClose)
+		line = trimListMarker(strings.TrimSpace(strings.NewReplacer(ConclusionOpen, "", ConclusionClose, "").Replace(line)))
+		if opens {
+			flush()
+			inConclusion = true
+		}
+
+		if speakerTag, _, rest, ok := splitTaggedLine(line); ok {
+			flush()
+			_, text := parseProsody(rest)
+			current = "**" + TrimBrackets(speakerTag) + "**: " + annotateTags(text)
+		} else if line != "" {
+			if current == "" {
+				current = annotateTags(line)
+			} else {
+				current = JoinText(current, annotateTags(line))
+			}
+		}
+
+		if closes {
+			flush()
+			inConclusion = false
+		}
+	}
+	flush()
+
+	if len(paragraphs) == 0 {
+		return ""
+	}
+	return "# 台本\n\n" + strings.Join(paragraphs, "\n\n") + "\n"
+}
+
+// annotateTags は本文中の感情・音響効果のタグを全角括弧の注釈に変換し、本文に紛れた話者・スタイルのタグを除去します。
+func annotateTags(text string) string {
+	text = reEmotionParse.ReplaceAllStringFunc(text, bracketNote)
+	text = reEffectParse.ReplaceAllStringFunc(text, bracketNote)
+	return strings.TrimSpace(reLeakedTag.ReplaceAllString(text, ""))
+}
+
+// bracketNote は "[驚き]" のようなタグを "（驚き）" のような注釈に変換します。
+func bracketNote(tag string) string {
+	return "（" + TrimBrackets(tag) + "）"
+}
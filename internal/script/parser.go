@@ -0,0 +1,375 @@
+package script
+
+import (
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxSegmentCharLength は1セグメントあたりの最大文字数（ルーン数）です。
+// これを超えるテキストは句読点の位置で分割されます。
+const MaxSegmentCharLength = 250
+
+var (
+	// reScriptParse は行頭の [話者タグ][スタイルタグ] と本文を取り出します。
+	reScriptParse = regexp.MustCompile(`^(\[[^\[\]]+\])\s*(\[[^\[\]]+\])\s*(.*)$`)
+	// reSpeakerOnlyParse はスタイルタグを省略した行の、行頭の [話者タグ] と本文を取り出します。
+	reSpeakerOnlyParse = regexp.MustCompile(`^(\[[^\[\]]+\])\s*(.*)$`)
+	// reListMarker は行頭のタグの前に付いた箇条書きの記号や番号 (例: "1. "、"- "、"・"、"(1)") を検出します。
+	// 本文の番号や記号を誤って除去しないよう、直後にタグの "[" が続く場合に限ります。
+	reListMarker = regexp.MustCompile(`^(?:[-*+・•●]|[0-9０-９]+[.．)）、]|[(（][0-9０-９]+[)）])\s*\[`)
+	// emotionTagsPattern は演出用感情タグとして定義されているタグ名です。
+	emotionTagsPattern = `解説|疑問|驚き|理解|落ち着き|納得|断定|呼びかけ`
+	// reEmotionParse は本文中の演出用感情タグを検出します。
+	reEmotionParse = regexp.MustCompile(`\[(?:` + emotionTagsPattern + `)\]`)
+	// reEffectParse は本文中の音響効果タグを検出し、タグ名を取り出します。
+	reEffectParse = regexp.MustCompile(`\[(` + EffectFadeIn + `|` + EffectFadeOut + `)\]`)
+	// styleNamesPattern は組み込みのスタイル名です。
+	styleNamesPattern = `ノーマル|あまあま|ツンツン|セクシー|ささやき|ヒソヒソ|ヘロヘロ|なみだめ`
+	// reStyleOnlyTag は話者タグとして扱わない、組み込みのスタイル名のみのタグを検出します。
+	reStyleOnlyTag = regexp.MustCompile(`^\[(?:` + styleNamesPattern + `)\]$`)
+	// speakerNamesPattern は組み込みの話者名です。カタカナ表記の揺れも含みます。
+	speakerNamesPattern = `ずんだもん|ズンダモン|四国めたん|めたん|メタン`
+	// reBuiltinSpeakerTag はスタイルタグを省略した行の行頭で、既定で話者タグとして受け付けるタグを検出します。
+	reBuiltinSpeakerTag = regexp.MustCompile(`^\[\s*(?:` + speakerNamesPattern + `)\s*\]$`)
+	// leakableTagsPattern は本文に紛れて読み上げられやすい話者名・スタイル名です。カタカナ表記の揺れも含みます。
+	leakableTagsPattern = speakerNamesPattern + `|` + styleNamesPattern
+	// reLeakedTag は本文に紛れた話者・スタイル・感情タグを、全角・半角の括弧や括弧内のスペースの揺れを含めて検出します。
+	// Go の \s は全角スペースに一致しないため、括弧内の全角スペースは明示的に含めます。
+	reLeakedTag = regexp.MustCompile(`[\[［【][\s　]*(?:` + leakableTagsPattern + `|` + emotionTagsPattern + `)[\s　]*[\]］】]`)
+	// reUnknownTag は既知のタグを除去した後も本文に残った [〜] 形式のトークンを検出します。
+	// エンジンに新しく追加されたスタイル名 (例: [ヘロヘロ]) などがそのまま読み上げられるのを防ぐために使います。
+	reUnknownTag = regexp.MustCompile(`\[[^\[\]]{1,12}\]`)
+	// reResidualTag は除去後も本文に残ったタグらしき括弧表記を検出します。
+	reResidualTag = regexp.MustCompile(`[\[［【][^\[\]［］【】]{1,12}[\]］】]`)
+)
+
+// Effect* はセグメントの合成後に音声へ適用する音響効果のタグ名です。
+const (
+	// EffectFadeIn はセグメントの音量を徐々に上げる効果です。
+	EffectFadeIn = "フェードイン"
+	// EffectFadeOut はセグメントの音量を徐々に下げる効果です。
+	EffectFadeOut = "フェードアウト"
+)
+
+// 結論マーカーは、教育コンテンツの結論 (ネタバレ) にあたる区間を囲むマーカーです。合成時には本文から除去されます。
+const (
+	ConclusionOpen  = "[結論]"
+	ConclusionClose = "[/結論]"
+)
+
+// Segment はスクリプトの1発話分（話者・スタイル・本文）を表します。
+type Segment struct {
+	// SpeakerTag は話者タグです (例: "[ずんだもん]")。タグの無いテキストでは空文字です。
+	SpeakerTag string
+	// StyleTag はスタイルタグです (例: "[ノーマル]")。タグの無いテキストでは空文字です。
+	StyleTag string
+	// Text は演出用感情タグを除去した読み上げ対象のテキストです。
+	Text string
+	// Line はこのセグメントが始まるスクリプト上の行番号 (1始まり) です。
+	Line int
+	// Effects は本文から取り出した音響効果のタグ名です (例: "フェードアウト")。合成後のPCMに適用します。
+	Effects []string
+	// Prosody はスタイルタグの直後の合成パラメータタグ (例: "[速1.2]") で指定された話速・音高・抑揚です。
+	Prosody Prosody
+	// Conclusion は結論マーカー ([結論]...[/結論]) で囲まれた区間のセグメントかどうかです。
+	Conclusion bool
+}
+
+// Speaker は話者タグから括弧を除いた話者名を返します。
+func (s Segment) Speaker() string {
+	return TrimBrackets(s.SpeakerTag)
+}
+
+// Style はスタイルタグから括弧を除いたスタイル名を返します。
+func (s Segment) Style() string {
+	return TrimBrackets(s.StyleTag)
+}
+
+// TrimBrackets はタグの前後の角括弧を取り除きます。
+func TrimBrackets(tag string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(tag, "["), "]")
+}
+
+// parser は parseScript の状態を保持します。
+type parser struct {
+	split      SplitFunc
+	segments   []Segment
+	speakerTag string
+	styleTag   string
+	prosody    Prosody
+	// inConclusion は結論マーカーの区間内を解析中かどうかです。
+	inConclusion bool
+	textBuffer   string
+	bufferStart  int
+}
+
+// Parse はAIが生成したスクリプトを解析し、セグメントの一覧を返します。
+// 同じタグが続く行とタグの無い継続行は、タグが変わるまで一つのテキストとして結合されます。
+func Parse(script string) []Segment {
+	return ParseWith(script, nil)
+}
+
+// SplitFunc は maxLen ルーンを超えるセグメントのテキストを、それぞれ maxLen ルーン以下の部分に分割する関数です。
+type SplitFunc func(text string, maxLen int) []string
+
+// ParseWith は長すぎるテキストの分割に split を使って Parse と同様にスクリプトを解析します。
+// split が nil の場合は句読点で分割します (splitTextByPunctuation)。
+func ParseWith(script string, split SplitFunc) []Segment {
+	if split == nil {
+		split = splitTextByPunctuation
+	}
+	p := &parser{split: split}
+	for i, line := range strings.Split(script, "\n") {
+		p.processLine(strings.TrimSpace(line), i+1)
+	}
+	p.flush()
+	return p.segments
+}
+
+// processLine は1行を解析し、タグの有無に応じてバッファへ追加またはセグメントを確定します。
+func (p *parser) processLine(line string, lineNo int) {
+	if line == "" {
+		return
+	}
+
+	// 結論マーカーの開始・終了ではセグメントを区切り、間のセグメントを結論区間として記録する
+	opens, closes := strings.Contains(line, ConclusionOpen), strings.Contains(line, ConclusionClose)
+	if opens || closes {
+		line = strings.TrimSpace(strings.NewReplacer(ConclusionOpen, "", ConclusionClose, "").Replace(line))
+	}
+	if opens {
+		p.flush()
+		p.inConclusion = true
+	}
+	p.processText(trimListMarker(line), lineNo)
+	if closes {
+		p.flush()
+		p.inConclusion = false
+	}
+}
+
+// processText はマーカーを除いた1行を、タグの有無に応じてバッファへ追加またはセグメントを確定します。
+func (p *parser) processText(line string, lineNo int) {
+	if line == "" {
+		return
+	}
+	speakerTag, styleTag, rest, ok := splitTaggedLine(line)
+	if !ok {
+		p.processUntaggedLine(line, lineNo)
+		return
+	}
+
+	prosody, text := parseProsody(rest)
+	if speakerTag != p.speakerTag || styleTag != p.styleTag || !prosody.Equal(p.prosody) {
+		p.flush()
+		p.speakerTag = speakerTag
+		p.styleTag = styleTag
+		p.prosody = prosody
+	}
+	p.appendText(text, lineNo)
+}
+
+// splitTaggedLine は行頭の [話者タグ][スタイルタグ] と残りの本文を取り出します。rest は line の末尾部分です。
+// スタイルタグを省略した行 ([話者タグ] 本文) は styleTag を空で返し、合成時にその話者の既定スタイルで補完します。
+// 行頭のタグが感情・音響効果・合成パラメータのタグの場合は話者タグとみなさず、ok に false を返します。
+func splitTaggedLine(line string) (speakerTag, styleTag, rest string, ok bool) {
+	if m := reScriptParse.FindStringSubmatch(line); m != nil && !isInlineTag(m[1]) && !isInlineTag(m[2]) {
+		return m[1], m[2], m[3], true
+	}
+	// スタイルタグを省略した行は、行頭のタグが既知の話者の場合に限る。
+	// "[補足] ..." や "[1] ..." のような見出し・番号は、従来どおり直前の話者の本文として扱う
+	if m := reSpeakerOnlyParse.FindStringSubmatch(line); m != nil && !isInlineTag(m[1]) && !reStyleOnlyTag.MatchString(m[1]) && isSpeakerTag(m[1]) {
+		return m[1], "", m[2], true
+	}
+	return "", "", "", false
+}
+
+// isSpeakerTag はスタイルタグを省略した行の行頭のタグを、話者タグとして受け付けるかを返します。
+// 既定では組み込みの話者名 (表記揺れを含む) のみを受け付けます。SetSpeakerMatcher で差し替えられます。
+var isSpeakerTag = reBuiltinSpeakerTag.MatchString
+
+// SetSpeakerMatcher はスタイルタグを省略した行の行頭のタグを話者タグとして受け付けるかの判定を差し替えます。
+// voicevox パッケージが --speaker-config などで定義した話者と表記揺れを受け付けるよう登録します。
+func SetSpeakerMatcher(match func(tag string) bool) {
+	isSpeakerTag = match
+}
+
+// trimListMarker は行頭のタグの前に付いた箇条書きの記号や番号を取り除きます。
+// AI が "1. [ずんだもん][ノーマル] ..." のように箇条書きで出力した行を、タグの無い継続行として結合しないようにします。
+func trimListMarker(line string) string {
+	if loc := reListMarker.FindStringIndex(line); loc != nil {
+		return line[loc[1]-len("["):]
+	}
+	return line
+}
+
+// isInlineTag は本文中に書かれる感情・音響効果・合成パラメータのタグかどうかを返します。
+func isInlineTag(tag string) bool {
+	return reEmotionParse.MatchString(tag) || reEffectParse.MatchString(tag) || reProsodyTag.MatchString(tag)
+}
+
+// processUntaggedLine はタグの無い行を直前のテキストに結合します。
+func (p *parser) processUntaggedLine(line string, lineNo int) {
+	p.appendText(line, lineNo)
+}
+
+// appendText はテキストバッファに行を追加します。
+func (p *parser) appendText(line string, lineNo int) {
+	if p.textBuffer == "" {
+		p.textBuffer = line
+		p.bufferStart = lineNo
+		return
+	}
+	p.textBuffer = JoinText(p.textBuffer, line)
+}
+
+// JoinText は2行のテキストを結合します。空白は英数字などの半角文字同士の境界にのみ挟み、
+// 日本語の文の途中に空白が入って VOICEVOX が不自然な間を作らないようにします。
+func JoinText(prev, next string) string {
+	last, _ := utf8.DecodeLastRuneInString(prev)
+	first, _ := utf8.DecodeRuneInString(next)
+	if isASCIIGraphic(last) && isASCIIGraphic(first) {
+		return prev + " " + next
+	}
+	return prev + next
+}
+
+// isASCIIGraphic は空白以外の半角文字 (英数字・記号) かどうかを返します。
+func isASCIIGraphic(r rune) bool {
+	return r < utf8.RuneSelf && unicode.IsGraphic(r) && !unicode.IsSpace(r)
+}
+
+// flush はバッファに溜まったテキストをセグメントとして確定します。
+func (p *parser) flush() {
+	if p.textBuffer != "" {
+		p.addSegment(p.textBuffer)
+	}
+	p.textBuffer = ""
+	p.bufferStart = 0
+}
+
+// addSegment は演出用感情タグ、本文に紛れた話者・スタイルタグ、未知のタグを除去し、長すぎるテキストを分割してセグメントを追加します。
+func (p *parser) addSegment(text string) {
+	var effects []string
+	for _, m := range reEffectParse.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(effects, m[1]) {
+			effects = append(effects, m[1])
+		}
+	}
+	text = reEffectParse.ReplaceAllString(text, "")
+
+	cleaned := stripTags(text)
+	// 未知のスタイルタグなどはそのまま読み上げられてしまうため除去し、プロンプト改善の手掛かりとして列挙する
+	if unknown := reUnknownTag.FindAllString(cleaned, -1); len(unknown) > 0 {
+		cleaned = reUnknownTag.ReplaceAllString(cleaned, "")
+		slog.Warn("本文から未知のタグを除去しました。", "line", p.bufferStart, "speaker", p.speakerTag, "tags", unknown)
+	}
+	cleaned = strings.TrimSpace(cleaned)
+	if cleaned == "" {
+		return
+	}
+	// 除去しきれなかったタグはそのまま読み上げられてしまうため、明示的に警告する
+	if residual := reResidualTag.FindAllString(cleaned, -1); len(residual) > 0 {
+		slog.Warn("本文にタグらしき表記が残っています。読み上げられる可能性があります。",
+			"line", p.bufferStart, "speaker", p.speakerTag, "tags", residual)
+	}
+	parts := p.split(cleaned, MaxSegmentCharLength)
+	for i, part := range parts {
+		p.segments = append(p.segments, Segment{
+			SpeakerTag: p.speakerTag,
+			StyleTag:   p.styleTag,
+			Text:       part,
+			Line:       p.bufferStart,
+			Effects:    partEffects(effects, i, len(parts)),
+			Prosody:    p.prosody,
+			Conclusion: p.inConclusion,
+		})
+	}
+}
+
+// partEffects は長いテキストを分割した場合に、音響効果を適用する部分を選びます。
+// フェードインは先頭の部分に、フェードアウトは末尾の部分にのみ適用します。
+func partEffects(effects []string, index, total int) []string {
+	var out []string
+	for _, effect := range effects {
+		switch {
+		case effect == EffectFadeIn && index != 0:
+		case effect == EffectFadeOut && index != total-1:
+		default:
+			out = append(out, effect)
+		}
+	}
+	return out
+}
+
+// stripTags は本文から演出用感情タグと、表記揺れを含む話者・スタイル・感情タグを除去します。
+func stripTags(text string) string {
+	text = reEmotionParse.ReplaceAllString(text, "")
+	return reLeakedTag.ReplaceAllString(text, "")
+}
+
+// splitTextByPunctuation は maxLen ルーンを超えないよう、文末記号、読点、最後に文字数の順でテキストを分割します。
+func splitTextByPunctuation(text string, maxLen int) []string {
+	if utf8.RuneCountInString(text) <= maxLen {
+		return []string{text}
+	}
+
+	var parts []string
+	var current []rune
+	flush := func() {
+		if s := strings.TrimSpace(string(current)); s != "" {
+			parts = append(parts, s)
+		}
+		current = current[:0]
+	}
+
+	for _, sentence := range splitAfter(text, "。！？!?") {
+		runes := []rune(sentence)
+		if len(current)+len(runes) <= maxLen {
+			current = append(current, runes...)
+			continue
+		}
+		flush()
+		if len(runes) <= maxLen {
+			current = append(current, runes...)
+			continue
+		}
+		// 一文が上限を超える場合は読点で分割し、それでも超える場合は文字数で切る
+		for _, clause := range splitAfter(sentence, "、，,") {
+			clauseRunes := []rune(clause)
+			if len(current)+len(clauseRunes) > maxLen {
+				flush()
+			}
+			for len(clauseRunes) > maxLen {
+				current = append(current, clauseRunes[:maxLen]...)
+				flush()
+				clauseRunes = clauseRunes[maxLen:]
+			}
+			current = append(current, clauseRunes...)
+		}
+	}
+	flush()
+
+	return parts
+}
+
+// splitAfter は seps に含まれる文字の直後でテキストを分割します。区切り文字は直前の要素に残ります。
+func splitAfter(text, seps string) []string {
+	var parts []string
+	start := 0
+	for i, r := range text {
+		if strings.ContainsRune(seps, r) {
+			end := i + utf8.RuneLen(r)
+			parts = append(parts, text[start:end])
+			start = end
+		}
+	}
+	if start < len(text) {
+		parts = append(parts, text[start:])
+	}
+	return parts
+}
@@ -0,0 +1,178 @@
+package script
+
+import (
+	"bytes"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestStripTags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"半角括弧の話者タグ", "こんにちは[ずんだもん]なのだ", "こんにちはなのだ"},
+		{"全角括弧の話者タグ", "こんにちは［ずんだもん］なのだ", "こんにちはなのだ"},
+		{"隅付き括弧の話者タグ", "こんにちは【ずんだもん】なのだ", "こんにちはなのだ"},
+		{"括弧内のスペース", "こんにちは[ ずんだもん ]なのだ", "こんにちはなのだ"},
+		{"括弧内の全角スペース", "こんにちは【　めたん　】ですわ", "こんにちはですわ"},
+		{"カタカナ表記の話者名", "[ズンダモン]と[メタン]の解説です", "との解説です"},
+		{"フルネームの話者名", "［四国めたん］ですわ", "ですわ"},
+		{"スタイルタグ", "[ノーマル]ふつうに［あまあま］話します", "ふつうに話します"},
+		{"感情タグ", "[驚き]本当なのだ？", "本当なのだ？"},
+		{"全角括弧の感情タグ", "【 疑問 】どうしてですの", "どうしてですの"},
+		{"未知のタグは残す", "[注意]ここは残す", "[注意]ここは残す"},
+		{"タグの無い本文", "ただの本文です。", "ただの本文です。"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stripTags(tt.text); got != tt.want {
+				t.Errorf("stripTags(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		want   []Segment
+	}{
+		{
+			name:   "話者とスタイルのタグ",
+			script: "[ずんだもん][ノーマル] こんにちはなのだ。",
+			want:   []Segment{{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "こんにちはなのだ。", Line: 1}},
+		},
+		{
+			name:   "本文に紛れたタグの表記揺れ",
+			script: "[ずんだもん][ノーマル] ［ズンダモン］今日は【 あまあま 】[驚き]晴れなのだ。",
+			want:   []Segment{{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "今日は晴れなのだ。", Line: 1}},
+		},
+		{
+			name:   "スタイルタグの省略",
+			script: "[四国めたん] ごきげんよう。",
+			want:   []Segment{{SpeakerTag: "[四国めたん]", Text: "ごきげんよう。", Line: 1}},
+		},
+		{
+			name:   "話者ではない行頭の見出しは本文として結合",
+			script: "[ずんだもん][ノーマル] 今日はAIの話なのだ。\n[補足] ここでいうAIは生成AIのことなのだ。",
+			want:   []Segment{{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "今日はAIの話なのだ。 ここでいうAIは生成AIのことなのだ。", Line: 1}},
+		},
+		{
+			name:   "行頭の番号は本文として結合",
+			script: "[ずんだもん][ノーマル] ポイントは二つなのだ。\n[1] 最初のポイントなのだ。",
+			want:   []Segment{{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "ポイントは二つなのだ。 最初のポイントなのだ。", Line: 1}},
+		},
+		{
+			name:   "タグの無い継続行の結合",
+			script: "[ずんだもん][ノーマル] 一行目なのだ。\n二行目なのだ。\n\n[四国めたん][ノーマル] 次の話者ですわ。",
+			want: []Segment{
+				{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "一行目なのだ。二行目なのだ。", Line: 1},
+				{SpeakerTag: "[四国めたん]", StyleTag: "[ノーマル]", Text: "次の話者ですわ。", Line: 4},
+			},
+		},
+		{
+			name:   "箇条書きの記号",
+			script: "1. [ずんだもん][ノーマル] 最初なのだ。\n- [四国めたん][ノーマル] 次ですわ。",
+			want: []Segment{
+				{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "最初なのだ。", Line: 1},
+				{SpeakerTag: "[四国めたん]", StyleTag: "[ノーマル]", Text: "次ですわ。", Line: 2},
+			},
+		},
+		{
+			name:   "半角文字どうしの継続行は空白で結合",
+			script: "[ずんだもん][ノーマル] Go is\nfast なのだ。",
+			want:   []Segment{{SpeakerTag: "[ずんだもん]", StyleTag: "[ノーマル]", Text: "Go is fast なのだ。", Line: 1}},
+		},
+		{
+			name:   "タグだけの行は読み上げない",
+			script: "[ずんだもん][ノーマル] [ずんだもん]",
+			want:   nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.script)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Parse() = %d segments %+v, want %d", len(got), got, len(tt.want))
+			}
+			for i := range got {
+				g, w := got[i], tt.want[i]
+				if g.SpeakerTag != w.SpeakerTag || g.StyleTag != w.StyleTag || g.Text != w.Text || g.Line != w.Line {
+					t.Errorf("segment %d = {%s %s %q line %d}, want {%s %s %q line %d}",
+						i, g.SpeakerTag, g.StyleTag, g.Text, g.Line, w.SpeakerTag, w.StyleTag, w.Text, w.Line)
+				}
+			}
+		})
+	}
+}
+
+func TestParseWarnsOnLeftoverTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		script   string
+		wantText string
+		wantWarn string
+	}{
+		{
+			name:     "未知のタグを除去して警告",
+			script:   "[ずんだもん][ノーマル] [ハイテンション]元気なのだ。",
+			wantText: "元気なのだ。",
+			wantWarn: "本文から未知のタグを除去しました。",
+		},
+		{
+			name:     "除去できない括弧表記を警告",
+			script:   "[ずんだもん][ノーマル] 【重要】ここは大事なのだ。",
+			wantText: "【重要】ここは大事なのだ。",
+			wantWarn: "本文にタグらしき表記が残っています。",
+		},
+		{
+			name:     "既知のタグだけなら警告しない",
+			script:   "[ずんだもん][ノーマル] ［メタン］【 驚き 】本当なのだ。",
+			wantText: "本当なのだ。",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logs := captureLogs(t)
+
+			segments := Parse(tt.script)
+			if len(segments) != 1 || segments[0].Text != tt.wantText {
+				t.Fatalf("Parse() = %+v, want one segment with text %q", segments, tt.wantText)
+			}
+			switch {
+			case tt.wantWarn == "" && logs.Len() > 0:
+				t.Errorf("unexpected warning: %s", logs)
+			case tt.wantWarn != "" && !strings.Contains(logs.String(), tt.wantWarn):
+				t.Errorf("warning %q not logged, got: %s", tt.wantWarn, logs)
+			}
+		})
+	}
+}
+
+func TestParseKeepsEffects(t *testing.T) {
+	segments := Parse("[ずんだもん][ノーマル] [フェードイン]はじまりなのだ。[フェードアウト]")
+	if len(segments) != 1 {
+		t.Fatalf("Parse() = %+v, want one segment", segments)
+	}
+	if got, want := segments[0].Effects, []string{EffectFadeIn, EffectFadeOut}; !slices.Equal(got, want) {
+		t.Errorf("Effects = %v, want %v", got, want)
+	}
+	if got := segments[0].Text; got != "はじまりなのだ。" {
+		t.Errorf("Text = %q, want %q", got, "はじまりなのだ。")
+	}
+}
+
+// captureLogs はテストの間 slog の既定のロガーの出力を記録します。
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+	return &buf
+}
@@ -0,0 +1,78 @@
+package script
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Prosody* はスタイルタグの直後に置いて合成パラメータを指定するタグの名前です (例: "[速1.2]")。
+const (
+	// ProsodySpeed は話速 (speedScale) を指定するタグです。
+	ProsodySpeed = "速"
+	// ProsodyPitch は音高 (pitchScale) を指定するタグです。
+	ProsodyPitch = "高"
+	// ProsodyIntonation は抑揚 (intonationScale) を指定するタグです。
+	ProsodyIntonation = "抑揚"
+)
+
+// reProsodyTag は本文の先頭の合成パラメータタグを1つ取り出します。
+var reProsodyTag = regexp.MustCompile(`^\s*\[(` + ProsodySpeed + `|` + ProsodyPitch + `|` + ProsodyIntonation + `)\s*([+-]?(?:\d+\.?\d*|\.\d+))\]`)
+
+// Prosody はタグで指定されたセグメントの合成パラメータです。nil のフィールドはエンジンの値をそのまま使います。
+type Prosody struct {
+	Speed      *float64
+	Pitch      *float64
+	Intonation *float64
+}
+
+// IsZero はパラメータが一つも指定されていないかを返します。
+func (p Prosody) IsZero() bool {
+	return p.Speed == nil && p.Pitch == nil && p.Intonation == nil
+}
+
+// Equal は2つの Prosody が同じ値を指定しているかを返します。
+func (p Prosody) Equal(o Prosody) bool {
+	eq := func(a, b *float64) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	return eq(p.Speed, o.Speed) && eq(p.Pitch, o.Pitch) && eq(p.Intonation, o.Intonation)
+}
+
+// parseProsody は本文の先頭に連続する合成パラメータタグを取り出し、タグを除いた本文とともに返します。
+func parseProsody(text string) (Prosody, string) {
+	var p Prosody
+	for {
+		m := reProsodyTag.FindStringSubmatch(text)
+		if m == nil {
+			return p, text
+		}
+		text = text[len(m[0]):]
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			continue
+		}
+		switch m[1] {
+		case ProsodySpeed:
+			p.Speed = &v
+		case ProsodyPitch:
+			p.Pitch = &v
+		case ProsodyIntonation:
+			p.Intonation = &v
+		}
+	}
+}
+
+// renderProsody は合成パラメータをタグとして書き出します。parseProsody で再び取り出せます。
+func renderProsody(p Prosody) string {
+	var sb strings.Builder
+	write := func(name string, v *float64) {
+		if v != nil {
+			sb.WriteString("[" + name + strconv.FormatFloat(*v, 'f', -1, 64) + "]")
+		}
+	}
+	write(ProsodySpeed, p.Speed)
+	write(ProsodyPitch, p.Pitch)
+	write(ProsodyIntonation, p.Intonation)
+	return sb.String()
+}
@@ -0,0 +1,22 @@
+package script
+
+import "strings"
+
+// Readable はスクリプトの話者が交代する行の前に空行を挿入し、人が読みやすい表示用のテキストを返します (--readable-output)。
+// 空行はパース時に無視され、セグメントの結合 (同じタグの行やタグの無い継続行の結合) も話者の交代で区切られるため、
+// 整形後のテキストを合成しても、行番号を除いて整形前と同じセグメントになります。区切り線は継続行として読み上げられるため挿入しません。
+func Readable(content string) string {
+	lines := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	out := make([]string, 0, len(lines))
+	prevSpeaker := ""
+	for _, line := range lines {
+		if speakerTag, _, _, ok := splitTaggedLine(strings.TrimSpace(line)); ok {
+			if prevSpeaker != "" && speakerTag != prevSpeaker && len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+				out = append(out, "")
+			}
+			prevSpeaker = speakerTag
+		}
+		out = append(out, line)
+	}
+	return strings.Join(out, "\n")
+}
@@ -0,0 +1,107 @@
+package script
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reviewHeader はレビュー用の表の見出し行です。ParseReview はこの行でレビュー形式かを判定します。
+const reviewHeader = "| No. | 話者 | スタイル | セリフ | コメント |"
+
+// RenderReview はセグメントを、各セグメントにレビュー用のコメント欄を設けた Markdown の表に書き出します。
+// コメント欄に指摘を書き込んだファイルは ParseReview で合成用のスクリプトに戻せます。
+func RenderReview(segments []Segment) string {
+	var sb strings.Builder
+	sb.WriteString("# 台本レビュー\n\n")
+	sb.WriteString("「コメント」列に指摘を記入してください。コメント列と表の外の記述は合成時に無視されます。\n\n")
+	sb.WriteString(reviewHeader + "\n")
+	sb.WriteString("| ---: | --- | --- | --- | --- |\n")
+	for i, seg := range segments {
+		text := renderProsody(seg.Prosody) + renderEffects(seg.Effects) + seg.Text
+		if seg.Conclusion {
+			// 表では行ごとに独立して編集されるため、結論マーカーはセグメントごとに閉じる
+			text = ConclusionOpen + text + ConclusionClose
+		}
+		fmt.Fprintf(&sb, "| %d | %s | %s | %s |  |\n",
+			i+1, escapeReviewCell(seg.Speaker()), escapeReviewCell(seg.Style()), escapeReviewCell(text))
+	}
+	return sb.String()
+}
+
+// ParseReview は RenderReview 形式の表からコメント列と表の外の注釈を取り除き、"[話者][スタイル] テキスト" 形式のスクリプトを返します。
+// レビュー形式でない場合は false を返します。
+func ParseReview(content string) (string, bool) {
+	lines := strings.Split(content, "\n")
+	start := -1
+	for i, line := range lines {
+		if normalizeReviewRow(line) == normalizeReviewRow(reviewHeader) {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", false
+	}
+
+	var segments []Segment
+	for _, line := range lines[start+1:] {
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "|") {
+			// 表の終わり以降はレビューの注釈として扱う
+			break
+		}
+		cells := splitReviewRow(line)
+		if len(cells) < 4 || strings.Trim(cells[0], "-: ") == "" {
+			// 区切り行
+			continue
+		}
+		text := strings.TrimSpace(cells[3])
+		if text == "" {
+			continue
+		}
+		seg := Segment{Text: text}
+		if speaker := strings.TrimSpace(cells[1]); speaker != "" {
+			seg.SpeakerTag = "[" + speaker + "]"
+			if style := strings.TrimSpace(cells[2]); style != "" {
+				seg.StyleTag = "[" + style + "]"
+			}
+		}
+		segments = append(segments, seg)
+	}
+	return Render(segments), true
+}
+
+// escapeReviewCell は表のセルを壊さないよう、縦棒と改行をエスケープします。
+func escapeReviewCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// splitReviewRow は表の行を、エスケープされていない縦棒でセルに分割します。
+func splitReviewRow(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	var cells []string
+	var cell strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			cell.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, cell.String())
+			cell.Reset()
+		default:
+			cell.WriteByte(line[i])
+		}
+	}
+	return append(cells, cell.String())
+}
+
+// normalizeReviewRow は見出し行の比較のため、セル内の空白の揺れを取り除きます。
+func normalizeReviewRow(line string) string {
+	cells := splitReviewRow(strings.TrimSpace(line))
+	for i, c := range cells {
+		cells[i] = strings.TrimSpace(c)
+	}
+	return strings.Join(cells, "|")
+}
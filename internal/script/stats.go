@@ -0,0 +1,71 @@
+package script
+
+import "unicode/utf8"
+
+// 再生時間の算出方法です。
+const (
+	// DurationEstimated は合成前に文字数から概算した再生時間です。
+	DurationEstimated = "estimated"
+	// DurationMeasured は合成した音声の PCM 長から算出した再生時間です。
+	DurationMeasured = "measured"
+)
+
+// SpeakerSummary はスクリプトにおける1人の話者の発話量の集計です。
+type SpeakerSummary struct {
+	Speaker string `json:"speaker"`
+	// Utterances はスクリプトの行単位の発話回数です。長いセリフを分割したセグメントは1回と数えます。
+	Utterances int `json:"utterances"`
+	Segments   int `json:"segments"`
+	Chars      int `json:"chars"`
+	// DurationSec は話者のセグメントの再生時間の合計です。
+	DurationSec float64 `json:"duration_sec"`
+}
+
+// ScriptStats は生成スクリプトのメタデータのレポートです (--stats-output)。
+type ScriptStats struct {
+	// DurationSource は再生時間の算出方法 (DurationEstimated または DurationMeasured) です。
+	DurationSource string `json:"duration_source"`
+	Utterances     int    `json:"utterances"`
+	Segments       int    `json:"segments"`
+	Chars          int    `json:"chars"`
+	// DurationSec は全体の再生時間です。
+	DurationSec float64          `json:"duration_sec"`
+	Speakers    []SpeakerSummary `json:"speakers"`
+}
+
+// Summarize はセグメントを話者ごとに集計し、登場順に並べたレポートを返します。
+// durations[i] は segments[i] の再生時間 (秒) で、全体の再生時間はその合計です。
+func Summarize(segments []Segment, durations []float64, source string) ScriptStats {
+	stats := ScriptStats{DurationSource: source, Speakers: []SpeakerSummary{}}
+	index := make(map[string]int)
+	lastLine := make(map[string]int)
+	for i, seg := range segments {
+		name := seg.Speaker()
+		if name == "" {
+			name = untaggedSpeakerName
+		}
+		n, ok := index[name]
+		if !ok {
+			n = len(stats.Speakers)
+			index[name] = n
+			stats.Speakers = append(stats.Speakers, SpeakerSummary{Speaker: name})
+		}
+
+		s := &stats.Speakers[n]
+		chars := utf8.RuneCountInString(seg.Text)
+		s.Segments++
+		s.Chars += chars
+		stats.Segments++
+		stats.Chars += chars
+		if line, seen := lastLine[name]; !seen || line != seg.Line {
+			s.Utterances++
+			stats.Utterances++
+			lastLine[name] = seg.Line
+		}
+		if i < len(durations) {
+			s.DurationSec += durations[i]
+			stats.DurationSec += durations[i]
+		}
+	}
+	return stats
+}
@@ -0,0 +1,77 @@
+package script
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// reTopicTerm はトピック候補となる語 (3文字以上のカタカナ語、英字で始まる英数字の語、2文字以上の漢字語) を検出します。
+var reTopicTerm = regexp.MustCompile(`[\p{Katakana}ー]{3,}|[A-Za-z][A-Za-z0-9+#.\-]+|\p{Han}{2,}`)
+
+// topicStopwords はトピックとして意味を持たない頻出語です。
+var topicStopwords = map[string]bool{
+	"今日": true, "今回": true, "以下": true, "以上": true, "場合": true, "必要": true, "方法": true, "可能": true,
+	"説明": true, "解説": true, "内容": true, "部分": true, "一つ": true, "次回": true, "最後": true, "最初": true,
+}
+
+// ExtractTopics はテキストに頻出する語を主要トピックとして、出現回数の多い順に最大 limit 件返します。
+// 出現回数が同じ語は先に現れた順に並べます。
+func ExtractTopics(text string, limit int) []string {
+	type term struct {
+		word  string
+		count int
+		first int
+	}
+	terms := make(map[string]*term)
+	for i, m := range reTopicTerm.FindAllString(text, -1) {
+		key := strings.ToLower(strings.TrimRight(m, ".-"))
+		if topicStopwords[key] || len([]rune(key)) < 2 {
+			continue
+		}
+		if t, ok := terms[key]; ok {
+			t.count++
+			continue
+		}
+		terms[key] = &term{word: key, count: 1, first: i}
+	}
+
+	sorted := make([]*term, 0, len(terms))
+	for _, t := range terms {
+		// 一度しか現れない語は主要トピックとみなさない
+		if t.count >= 2 {
+			sorted = append(sorted, t)
+		}
+	}
+	slices.SortFunc(sorted, func(a, b *term) int {
+		if c := cmp.Compare(b.count, a.count); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.first, b.first)
+	})
+
+	topics := make([]string, 0, min(limit, len(sorted)))
+	for _, t := range sorted[:min(limit, len(sorted))] {
+		topics = append(topics, t.word)
+	}
+	return topics
+}
+
+// CoveredTopics は topics のうち、スクリプトの本文で言及されているものを返します。英字の大文字・小文字は区別しません。
+func CoveredTopics(scriptContent string, topics []string) []string {
+	var sb strings.Builder
+	for _, seg := range Parse(scriptContent) {
+		sb.WriteString(seg.Text)
+		sb.WriteString("\n")
+	}
+	text := strings.ToLower(sb.String())
+
+	var covered []string
+	for _, topic := range topics {
+		if strings.Contains(text, topic) {
+			covered = append(covered, topic)
+		}
+	}
+	return covered
+}
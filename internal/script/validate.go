@@ -0,0 +1,63 @@
+package script
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Issue はスクリプトの検査で見つかった1件の問題です。
+type Issue struct {
+	// Line は問題のある行の行番号 (1始まり) です。
+	Line int
+	// Message は問題の説明です。
+	Message string
+	// Fatal は音声合成でそのセリフが失われる問題かどうかです。false の問題は合成自体は可能です。
+	Fatal bool
+}
+
+// String は「行 N: 説明」の形式で問題を返します。
+func (i Issue) String() string {
+	return fmt.Sprintf("行 %d: %s", i.Line, i.Message)
+}
+
+// Validate は生成スクリプトを音声合成の前に軽量に検査し、タグの形式・既知の話者とスタイル・空の本文の問題を行番号付きで返します。
+// speakers と styles は括弧を除いた既知の話者名・スタイル名です。空の場合はその検査を省略します。
+func Validate(content string, speakers, styles []string) []Issue {
+	var issues []Issue
+	for i, raw := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		lineNo := i + 1
+		line := trimListMarker(strings.TrimSpace(strings.NewReplacer(ConclusionOpen, "", ConclusionClose, "").Replace(raw)))
+		if line == "" {
+			continue
+		}
+		if strings.Count(line, "[") != strings.Count(line, "]") {
+			issues = append(issues, Issue{Line: lineNo, Message: "角括弧の対応が壊れています", Fatal: true})
+			continue
+		}
+		if !strings.HasPrefix(line, "[") {
+			// タグの無い行は直前の話者のセリフとして結合されるため問題にしない
+			continue
+		}
+
+		speakerTag, styleTag, rest, ok := splitTaggedLine(line)
+		if !ok {
+			issues = append(issues, Issue{Line: lineNo, Message: "行頭のタグが [話者][スタイル] の形式ではありません"})
+			continue
+		}
+		if speaker := TrimBrackets(speakerTag); len(speakers) > 0 && !slices.Contains(speakers, speaker) {
+			issues = append(issues, Issue{Line: lineNo, Message: fmt.Sprintf("未知の話者タグです: %s", speakerTag), Fatal: true})
+			continue
+		}
+		// スタイルタグの省略は既定スタイルで補完するため問題にしない
+		if style := TrimBrackets(styleTag); styleTag != "" && len(styles) > 0 && !slices.Contains(styles, style) {
+			issues = append(issues, Issue{Line: lineNo, Message: fmt.Sprintf("未知のスタイルタグです (既定スタイルで合成されます): %s", styleTag)})
+		}
+		_, text := parseProsody(rest)
+		text = reUnknownTag.ReplaceAllString(stripTags(reEffectParse.ReplaceAllString(text, "")), "")
+		if strings.TrimSpace(text) == "" {
+			issues = append(issues, Issue{Line: lineNo, Message: "本文が空です"})
+		}
+	}
+	return issues
+}
@@ -0,0 +1,241 @@
+package scriptversion
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	indexFile  = "versions.jsonl"
+	scriptExt  = ".txt"
+	promptsDir = "prompts"
+	hashDigits = 12
+)
+
+// reSubjectID は題材のIDとして使える文字列です。ディレクトリ名になるため、パス区切りなどを含めません。
+var reSubjectID = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// ErrVersionNotFound は指定したバージョンが履歴に無い場合に返されます。
+var ErrVersionNotFound = errors.New("バージョンが見つかりません")
+
+// Version は保存したスクリプトの1バージョンのメタ情報です。スクリプト本文とプロンプトは内容ハッシュをファイル名とする別ファイルに保存します。
+type Version struct {
+	// Hash はスクリプト本文の内容ハッシュ (SHA-256 の先頭12桁) です。同じ内容のスクリプトは同じバージョンになります。
+	Hash      string    `json:"hash"`
+	CreatedAt time.Time `json:"created_at"`
+	// Source は入力元の URL またはファイルパスです。
+	Source string `json:"source,omitempty"`
+	Mode   string `json:"mode,omitempty"`
+	Model  string `json:"model,omitempty"`
+	// PromptFile は --prompt-file で指定したプロンプトテンプレートのパスです。組み込みのプロンプトの場合は空です。
+	PromptFile string `json:"prompt_file,omitempty"`
+	// PromptHash は AI に渡したプロンプト全文の内容ハッシュです。
+	PromptHash string `json:"prompt_hash"`
+}
+
+// Subject は同じ題材 (ID) で保存したスクリプトの履歴の概要です。
+type Subject struct {
+	ID       string
+	Source   string
+	Versions int
+	Updated  time.Time
+}
+
+// Store はディレクトリ上のファイルで題材ごとのスクリプトの履歴を管理します。
+// 題材ごとのディレクトリに、バージョンの一覧 (JSON Lines) と、内容ハッシュをファイル名とするスクリプト本文・プロンプトを保存します。
+type Store struct {
+	dir string
+}
+
+// New は dir を保存先とする Store を生成します。
+func New(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// SubjectID は入力元の URL・ファイルパス (標準入力の場合は入力本文) から題材のIDを算出します。同じ入力から作り直したスクリプトは同じ履歴に集約されます。
+func SubjectID(source string) string {
+	return contentHash(source)
+}
+
+// ValidateSubjectID は id が題材のIDとして使えるかを検査します。
+func ValidateSubjectID(id string) error {
+	if !reSubjectID.MatchString(id) {
+		return fmt.Errorf("題材のIDには英数字と . _ - のみを使えます: '%s'", id)
+	}
+	return nil
+}
+
+// contentHash は s の SHA-256 の先頭12桁を返します。
+func contentHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:hashDigits]
+}
+
+// Save はスクリプトを題材 id の新しいバージョンとして保存します。v の Hash・PromptHash・CreatedAt は内容から設定します。
+// 最新のバージョンと同じ内容の場合は保存せず、最新のバージョンと false を返します。
+func (s *Store) Save(id string, v Version, scriptContent, prompt string) (*Version, bool, error) {
+	if err := ValidateSubjectID(id); err != nil {
+		return nil, false, err
+	}
+	versions, err := s.Versions(id)
+	if err != nil {
+		return nil, false, err
+	}
+	v.Hash = contentHash(scriptContent)
+	if len(versions) > 0 && versions[len(versions)-1].Hash == v.Hash {
+		return &versions[len(versions)-1], false, nil
+	}
+	v.PromptHash = contentHash(prompt)
+	v.CreatedAt = time.Now()
+
+	dir := filepath.Join(s.dir, id)
+	if err := os.MkdirAll(filepath.Join(dir, promptsDir), 0o755); err != nil {
+		return nil, false, fmt.Errorf("スクリプト履歴のディレクトリ作成に失敗しました (%s): %w", dir, err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, v.Hash+scriptExt), []byte(scriptContent), 0o644); err != nil {
+		return nil, false, fmt.Errorf("スクリプト本文の保存に失敗しました: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, promptsDir, v.PromptHash+scriptExt), []byte(prompt), 0o644); err != nil {
+		return nil, false, fmt.Errorf("プロンプトの保存に失敗しました: %w", err)
+	}
+
+	line, err := json.Marshal(v)
+	if err != nil {
+		return nil, false, fmt.Errorf("スクリプト履歴のエンコードに失敗しました: %w", err)
+	}
+	path := filepath.Join(dir, indexFile)
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, false, fmt.Errorf("スクリプト履歴のオープンに失敗しました (%s): %w", path, err)
+	}
+	_, writeErr := f.Write(append(line, '\n'))
+	if err := errors.Join(writeErr, f.Close()); err != nil {
+		return nil, false, fmt.Errorf("スクリプト履歴の書き込みに失敗しました (%s): %w", path, err)
+	}
+	return &v, true, nil
+}
+
+// Versions は題材 id のバージョンを古い順に返します。履歴が無い場合は空を返します。
+// 書き込み途中で中断されたなどで解析できない行は読み飛ばします。
+func (s *Store) Versions(id string) ([]Version, error) {
+	if err := ValidateSubjectID(id); err != nil {
+		return nil, err
+	}
+	path := filepath.Join(s.dir, id, indexFile)
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("スクリプト履歴のオープンに失敗しました (%s): %w", path, err)
+	}
+	defer f.Close()
+
+	var versions []Version
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var v Version
+		if err := json.Unmarshal([]byte(line), &v); err != nil {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("スクリプト履歴の読み込みに失敗しました (%s): %w", path, err)
+	}
+	return versions, nil
+}
+
+// Subjects は保存されている題材の一覧を、最後に更新された順 (新しい順) に返します。保存先が無い場合は空を返します。
+func (s *Store) Subjects() ([]Subject, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("スクリプト履歴の読み込みに失敗しました (%s): %w", s.dir, err)
+	}
+
+	var subjects []Subject
+	for _, d := range dirs {
+		if !d.IsDir() || ValidateSubjectID(d.Name()) != nil {
+			continue
+		}
+		versions, err := s.Versions(d.Name())
+		if err != nil {
+			return nil, err
+		}
+		if len(versions) == 0 {
+			continue
+		}
+		latest := versions[len(versions)-1]
+		subjects = append(subjects, Subject{ID: d.Name(), Source: latest.Source, Versions: len(versions), Updated: latest.CreatedAt})
+	}
+	slices.SortFunc(subjects, func(a, b Subject) int {
+		return b.Updated.Compare(a.Updated)
+	})
+	return subjects, nil
+}
+
+// Resolve は ref で指定したバージョンを versions から探し、1始まりの番号とともに返します。
+// ref にはバージョン番号 (1 が最古、負の値は最新から数えて -1 が最新) または内容ハッシュの先頭部分を指定できます。
+func Resolve(versions []Version, ref string) (int, *Version, error) {
+	if n, err := strconv.Atoi(ref); err == nil {
+		if n < 0 {
+			n += len(versions) + 1
+		}
+		if n < 1 || n > len(versions) {
+			return 0, nil, fmt.Errorf("%w: バージョン番号は 1〜%d で指定してください: '%s'", ErrVersionNotFound, len(versions), ref)
+		}
+		return n, &versions[n-1], nil
+	}
+
+	found := -1
+	for i, v := range versions {
+		if ref == "" || !strings.HasPrefix(v.Hash, ref) {
+			continue
+		}
+		if found >= 0 && versions[found].Hash != v.Hash {
+			return 0, nil, fmt.Errorf("ハッシュ '%s' に一致するバージョンが複数あります。より長く指定してください", ref)
+		}
+		found = i
+	}
+	if found < 0 {
+		return 0, nil, fmt.Errorf("%w: '%s'", ErrVersionNotFound, ref)
+	}
+	return found + 1, &versions[found], nil
+}
+
+// Script は題材 id に保存したバージョン v のスクリプト本文を返します。
+func (s *Store) Script(id string, v Version) (string, error) {
+	body, err := os.ReadFile(filepath.Join(s.dir, id, v.Hash+scriptExt))
+	if err != nil {
+		return "", fmt.Errorf("スクリプト本文の読み込みに失敗しました (%s): %w", v.Hash, err)
+	}
+	return string(body), nil
+}
+
+// Prompt は題材 id に保存したバージョン v の生成に使ったプロンプト全文を返します。
+func (s *Store) Prompt(id string, v Version) (string, error) {
+	body, err := os.ReadFile(filepath.Join(s.dir, id, promptsDir, v.PromptHash+scriptExt))
+	if err != nil {
+		return "", fmt.Errorf("プロンプトの読み込みに失敗しました (%s): %w", v.PromptHash, err)
+	}
+	return string(body), nil
+}
@@ -0,0 +1,116 @@
+package voicevox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// volumeRange はオートメーションで指定できる音量 (volumeScale) の範囲です。
+var volumeRange = prosodyRange{min: 0, max: 2.0}
+
+// automationParams はオートメーションで変化させられる合成パラメータです。キーは audio_query のフィールド名です。
+var automationParams = []struct {
+	name  string
+	r     prosodyRange
+	field func(p *SynthesisParams) **float64
+}{
+	{"speedScale", speedRange, func(p *SynthesisParams) **float64 { return &p.SpeedScale }},
+	{"pitchScale", pitchRange, func(p *SynthesisParams) **float64 { return &p.PitchScale }},
+	{"intonationScale", intonationRange, func(p *SynthesisParams) **float64 { return &p.IntonationScale }},
+	{"volumeScale", volumeRange, func(p *SynthesisParams) **float64 { return &p.VolumeScale }},
+}
+
+// Automation は合成パラメータをスクリプトの先頭から末尾にかけて始点の値から終点の値へ線形に変化させる指定です (--automate)。
+type Automation struct {
+	Param    string
+	From, To float64
+	field    func(p *SynthesisParams) **float64
+}
+
+// ParseAutomation は "speedScale:1.0->1.2" 形式の指定を Automation に変換します。
+// パラメータ名は audio_query のフィールド名で、値は合成パラメータタグと同じ範囲に収まる必要があります。
+func ParseAutomation(spec string) (Automation, error) {
+	name, values, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	from, to, ok2 := strings.Cut(values, "->")
+	if !ok || !ok2 {
+		return Automation{}, fmt.Errorf("--automate は 'speedScale:1.0->1.2' の形式で指定してください: '%s'", spec)
+	}
+
+	names := make([]string, len(automationParams))
+	for i, p := range automationParams {
+		names[i] = p.name
+		if !strings.EqualFold(p.name, strings.TrimSpace(name)) {
+			continue
+		}
+		a := Automation{Param: p.name, field: p.field}
+		var err error
+		if a.From, err = strconv.ParseFloat(strings.TrimSpace(from), 64); err != nil {
+			return Automation{}, fmt.Errorf("--automate の始点の値が不正です: '%s'", spec)
+		}
+		if a.To, err = strconv.ParseFloat(strings.TrimSpace(to), 64); err != nil {
+			return Automation{}, fmt.Errorf("--automate の終点の値が不正です: '%s'", spec)
+		}
+		for _, v := range []float64{a.From, a.To} {
+			if v < p.r.min || v > p.r.max {
+				return Automation{}, fmt.Errorf("--automate の %s には %g〜%g の値を指定してください: '%s'", p.name, p.r.min, p.r.max, spec)
+			}
+		}
+		return a, nil
+	}
+	return Automation{}, fmt.Errorf("--automate のパラメータには %s のいずれかを指定してください: '%s'", strings.Join(names, ", "), spec)
+}
+
+// ParseAutomations は複数の --automate の指定を変換します。同じパラメータを重複して指定することはできません。
+func ParseAutomations(specs []string) ([]Automation, error) {
+	var automations []Automation
+	seen := make(map[string]bool)
+	for _, spec := range specs {
+		a, err := ParseAutomation(spec)
+		if err != nil {
+			return nil, err
+		}
+		if seen[a.Param] {
+			return nil, fmt.Errorf("--automate で %s が重複して指定されています", a.Param)
+		}
+		seen[a.Param] = true
+		automations = append(automations, a)
+	}
+	return automations, nil
+}
+
+// valueAt は位置 pos (0〜1) における補間値を返します。
+func (a Automation) valueAt(pos float64) float64 {
+	return a.From + (a.To-a.From)*pos
+}
+
+// applyAutomations は各リクエストのスクリプト上の経過位置に応じて、オートメーションの補間値を合成パラメータに設定します。
+// 経過位置はセグメント先頭までの文字数の割合 (先頭のセグメントが 0、最後のセグメントが 1) です。
+// セグメントに合成パラメータタグがある場合は、タグの値を優先します。
+func applyAutomations(requests []synthRequest, automations []Automation) {
+	if len(automations) == 0 || len(requests) == 0 {
+		return
+	}
+
+	starts := make([]int, len(requests))
+	total := 0
+	for i, req := range requests {
+		starts[i] = total
+		total += utf8.RuneCountInString(req.segment.Text)
+	}
+	last := starts[len(starts)-1]
+
+	for i := range requests {
+		pos := 0.0
+		if last > 0 {
+			pos = float64(starts[i]) / float64(last)
+		}
+		for _, a := range automations {
+			if field := a.field(&requests[i].params); *field == nil {
+				v := a.valueAt(pos)
+				*field = &v
+			}
+		}
+	}
+}
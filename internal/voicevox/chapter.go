@@ -0,0 +1,99 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// maxChapterTitleLength はチャプタータイトルとして使う本文の最大文字数（ルーン数）です。
+const maxChapterTitleLength = 30
+
+// Chapter は無音区間を境界として自動で区切ったチャプターです。
+type Chapter struct {
+	Index int     `json:"index"`
+	Title string  `json:"title"`
+	Start float64 `json:"start_sec"`
+	End   float64 `json:"end_sec"`
+}
+
+// buildChapters は無音区間をチャプター境界とし、境界直後のセグメント本文からタイトルを推定してチャプターを組み立てます。
+// segmentStarts は結合後の音声における各セグメントの開始秒数、texts はその本文です。
+func buildChapters(silences []audio.Span, segmentStarts []float64, texts []string, total float64) []Chapter {
+	if len(texts) == 0 {
+		return nil
+	}
+
+	chapters := []Chapter{{Title: chapterTitle(texts[0])}}
+	for _, silence := range silences {
+		// 先頭・末尾の無音はチャプター境界にしない
+		if silence.Start <= 0 || silence.End >= total {
+			continue
+		}
+		next := -1
+		for i, start := range segmentStarts {
+			if start >= silence.Start {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			continue
+		}
+
+		chapters[len(chapters)-1].End = silence.End
+		chapters = append(chapters, Chapter{Title: chapterTitle(texts[next]), Start: silence.End})
+	}
+	chapters[len(chapters)-1].End = total
+
+	for i := range chapters {
+		chapters[i].Index = i + 1
+	}
+	return chapters
+}
+
+// chapterTitle はセグメント本文の最初の文をチャプタータイトルとして切り出します。
+func chapterTitle(text string) string {
+	title := text
+	if i := strings.IndexAny(title, "。！？!?"); i >= 0 {
+		title = title[:i]
+	}
+	if utf8.RuneCountInString(title) > maxChapterTitleLength {
+		title = string([]rune(title)[:maxChapterTitleLength]) + "…"
+	}
+	return title
+}
+
+// writeChapters は結合後の音声から一定以上の長さの無音を検出し、チャプター一覧を <出力パス>.chapters.json に書き込みます。
+func (e *Engine) writeChapters(ctx context.Context, combined []byte, spans []audio.Span, texts []string, outputPath string) error {
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return fmt.Errorf("チャプター検出のためのWAV解析に失敗しました: %w", err)
+	}
+
+	segmentStarts := make([]float64, len(spans))
+	for i, span := range spans {
+		segmentStarts[i] = span.Start
+	}
+
+	silences := audio.DetectSilences(w, e.config.AutoChapterSilence.Seconds(), audio.DefaultSilenceLevelDB)
+	chapters := buildChapters(silences, segmentStarts, texts, w.Duration())
+
+	body, err := json.MarshalIndent(chapters, "", "  ")
+	if err != nil {
+		return fmt.Errorf("チャプター一覧のエンコードに失敗しました: %w", err)
+	}
+	chaptersPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".chapters.json"
+	if err := e.writer.Write(ctx, chaptersPath, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("チャプター一覧の書き込みに失敗しました (%s): %w", chaptersPath, err)
+	}
+	slog.InfoContext(ctx, "無音区間からチャプターを自動生成しました。", "chapters", len(chapters), "path", chaptersPath)
+	return nil
+}
@@ -0,0 +1,216 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pingTimeout は Ping の応答を待つ時間の上限です。
+const pingTimeout = 5 * time.Second
+
+// Doer は HTTP リクエストを送信するクライアントの抽象です。*http.Client が満たします。
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// APIError は VOICEVOX エンジンが成功以外のステータスを返したことを表します。
+type APIError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Body       string
+}
+
+// Error は error インターフェースを実装します。
+func (e *APIError) Error() string {
+	return fmt.Sprintf("VOICEVOXエンジンがエラーを返しました (%s %s: %d): %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// Retryable は再試行で回復する見込みのあるエラーかどうかを返します。
+func (e *APIError) Retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError
+}
+
+// Client は VOICEVOX エンジンの HTTP API を呼び出すクライアントです。
+type Client struct {
+	apiURL       string
+	httpClient   Doer
+	speakerCache *SpeakerCache
+	userDict     *UserDict
+	// requestTimeout は合成以外のリクエスト (話者一覧・アクセント句など) のタイムアウトです。0 の場合は制限しません。
+	// 合成のタイムアウトはテキストの長さに応じて synthPool が決めるため、HTTP クライアント全体には設定しません。
+	requestTimeout time.Duration
+}
+
+// NewClient は指定されたエンジンURLに接続する Client を生成します。
+func NewClient(apiURL string, httpClient Doer) *Client {
+	return &Client{
+		apiURL:     strings.TrimRight(apiURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// WithSpeakerCache は話者一覧の取得に cache を使うよう設定し、Client 自身を返します。
+func (c *Client) WithSpeakerCache(cache *SpeakerCache) *Client {
+	c.speakerCache = cache
+	return c
+}
+
+// WithRequestTimeout は合成以外のリクエストのタイムアウトを設定し、Client 自身を返します。
+func (c *Client) WithRequestTimeout(timeout time.Duration) *Client {
+	c.requestTimeout = timeout
+	return c
+}
+
+// WithUserDict は合成時に dict の読みとアクセントを適用するよう設定し、Client 自身を返します。
+func (c *Client) WithUserDict(dict *UserDict) *Client {
+	c.userDict = dict
+	return c
+}
+
+// Get は指定パスに GET リクエストを送り、レスポンスボディを返します。
+func (c *Client) Get(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.apiURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("リクエストの作成に失敗しました (%s): %w", path, err)
+	}
+	return c.do(req, path)
+}
+
+// Ping は軽量な /version を呼び出し、エンジンに到達できるかを確認します。
+// URL の誤りやエンジンの停止を、全セグメントの合成が再試行を使い切る前に検出するために使います。
+func (c *Client) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	body, err := c.Get(ctx, "/version")
+	if err != nil {
+		return fmt.Errorf("VOICEVOXエンジン (%s) に接続できません。VOICEVOX_API_URL が正しいか、エンジンが起動しているかを確認してください: %w", c.apiURL, err)
+	}
+	var version string
+	if err := json.Unmarshal(body, &version); err == nil {
+		slog.DebugContext(ctx, "VOICEVOXエンジンに接続しました。", "url", c.apiURL, "version", version)
+	}
+	return nil
+}
+
+// withRequestTimeout は合成以外のリクエストに requestTimeout の期限を設定した context を返します。
+func (c *Client) withRequestTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.requestTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.requestTimeout)
+}
+
+// runAudioQuery は /audio_query を呼び出し、合成用クエリのJSONを返します。
+func (c *Client) runAudioQuery(ctx context.Context, text string, styleID int) ([]byte, error) {
+	params := url.Values{}
+	params.Set("text", text)
+	params.Set("speaker", strconv.Itoa(styleID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/audio_query?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("audio_queryリクエストの作成に失敗しました: %w", err)
+	}
+	return c.do(req, "/audio_query")
+}
+
+// AccentPhrases は /accent_phrases を呼び出し、テキストを VOICEVOX が解析したアクセント句の一覧を返します。
+func (c *Client) AccentPhrases(ctx context.Context, text string, styleID int) ([]AccentPhrase, error) {
+	ctx, cancel := c.withRequestTimeout(ctx)
+	defer cancel()
+
+	params := url.Values{}
+	params.Set("text", text)
+	params.Set("speaker", strconv.Itoa(styleID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/accent_phrases?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("accent_phrasesリクエストの作成に失敗しました: %w", err)
+	}
+	body, err := c.do(req, "/accent_phrases")
+	if err != nil {
+		return nil, err
+	}
+	var phrases []AccentPhrase
+	if err := json.Unmarshal(body, &phrases); err != nil {
+		return nil, fmt.Errorf("accent_phrasesレスポンスの解析に失敗しました: %w", err)
+	}
+	return phrases, nil
+}
+
+// runSynthesis は /synthesis を呼び出し、クエリJSONから合成したWAVデータを返します。
+func (c *Client) runSynthesis(ctx context.Context, query []byte, styleID int) ([]byte, error) {
+	params := url.Values{}
+	params.Set("speaker", strconv.Itoa(styleID))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/synthesis?"+params.Encode(), bytes.NewReader(query))
+	if err != nil {
+		return nil, fmt.Errorf("synthesisリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "audio/wav")
+	return c.do(req, "/synthesis")
+}
+
+// do はリクエストを送信し、2xx 以外のステータスを APIError に変換します。
+func (c *Client) do(req *http.Request, path string) ([]byte, error) {
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("VOICEVOXエンジンへの接続に失敗しました (%s): %w", path, err)
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	closeErr := resp.Body.Close()
+	if err := errors.Join(readErr, closeErr); err != nil {
+		return nil, fmt.Errorf("VOICEVOXエンジンのレスポンス読み込みに失敗しました (%s): %w", path, err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return nil, &APIError{
+			Method:     req.Method,
+			Path:       path,
+			StatusCode: resp.StatusCode,
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+	return body, nil
+}
+
+// LoadSpeakers は Synthesizer を実装します。エンジンの /speakers からスタイルIDの対応表を取得します。
+func (c *Client) LoadSpeakers(ctx context.Context) (*SpeakerData, error) {
+	return LoadSpeakers(ctx, c)
+}
+
+// Synthesize は Synthesizer を実装します。audio_query で得たクエリにユーザー辞書のアクセントと合成パラメータを反映し、synthesis でWAVデータを生成します。
+func (c *Client) Synthesize(ctx context.Context, text string, styleID int, params SynthesisParams) ([]byte, error) {
+	if c.userDict != nil {
+		text = c.userDict.Rewrite(text)
+	}
+	query, err := c.runAudioQuery(ctx, text, styleID)
+	if err != nil {
+		return nil, err
+	}
+	query, err = c.applyUserDict(ctx, query, styleID)
+	if err != nil {
+		return nil, err
+	}
+	query, err = applyQueryParams(query, params)
+	if err != nil {
+		return nil, err
+	}
+	return c.runSynthesis(ctx, query, styleID)
+}
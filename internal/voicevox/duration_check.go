@@ -0,0 +1,83 @@
+package voicevox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"unicode"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+const (
+	// estimatedCharsPerSecond は話速 1.0 での読み上げ速度 (文字/秒) の目安です。文字数からの推定尺の算出に使います。
+	estimatedCharsPerSecond = 7.5
+	// durationCheckMinChars は推定尺と比較するセグメントの最小文字数です。短いセグメントは誤差が大きいため対象外にします。
+	durationCheckMinChars = 10
+	// durationDeviationRatio は合成尺と推定尺の比がこの倍率を超える (または逆数を下回る) 場合に乖離とみなす閾値です。
+	durationDeviationRatio = 2.5
+	// durationSnippetRunes は警告に含めるセグメント本文の最大文字数です。
+	durationSnippetRunes = 30
+)
+
+// checkDurations は各セグメントの文字数から推定した尺と合成したWAVの尺を比較し、大きく乖離するセグメントを警告します (--duration-sanity-check)。
+// 記号の羅列のように読み上げられない本文や、分割の失敗による異常に長い本文の早期検出に使います。
+func checkDurations(ctx context.Context, requests []synthRequest, segmentWavs [][]byte) error {
+	var totalEstimated, totalActual float64
+	deviated := 0
+	for i, req := range requests {
+		w, err := audio.ParseWAV(segmentWavs[i])
+		if err != nil {
+			return fmt.Errorf("セグメント %d (行 %d) のWAV解析に失敗しました: %w", i+1, req.segment.Line, err)
+		}
+		chars := countSpokenChars(req.segment.Text)
+		estimated := estimatedSeconds(chars, req.params)
+		actual := w.Duration()
+		totalEstimated += estimated
+		totalActual += actual
+
+		if chars < durationCheckMinChars || estimated == 0 {
+			continue
+		}
+		if ratio := actual / estimated; ratio > durationDeviationRatio || ratio < 1/durationDeviationRatio {
+			deviated++
+			slog.WarnContext(ctx, "セグメントの合成尺が文字数からの推定と大きく乖離しています。本文に読み上げられない記号の羅列などが無いか確認してください。",
+				"segment", i+1, "line", req.segment.Line, "speaker", req.segment.Speaker(), "chars", chars,
+				"estimated_sec", fmt.Sprintf("%.1f", estimated), "actual_sec", fmt.Sprintf("%.1f", actual), "text", snippet(req.segment.Text, durationSnippetRunes))
+		}
+	}
+
+	slog.InfoContext(ctx, "合成尺の妥当性を検査しました。", "segments", len(requests), "deviated", deviated,
+		"estimated_sec", fmt.Sprintf("%.1f", totalEstimated), "actual_sec", fmt.Sprintf("%.1f", totalActual))
+	return nil
+}
+
+// countSpokenChars は空白を除いた本文の文字数 (ルーン数) を返します。
+func countSpokenChars(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// estimatedSeconds は文字数と話速の指定から読み上げの尺を推定します。
+func estimatedSeconds(chars int, params SynthesisParams) float64 {
+	speed := 1.0
+	if params.SpeedScale != nil && *params.SpeedScale > 0 {
+		speed = *params.SpeedScale
+	}
+	return float64(chars) / estimatedCharsPerSecond / speed
+}
+
+// snippet はテキストを先頭から最大 n 文字に切り詰めます。
+func snippet(text string, n int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n]) + "…"
+}
@@ -0,0 +1,47 @@
+package voicevox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// fadeFloorGain はフェードの最も小さい音量の倍率です。完全な無音にすると語頭・語尾が聞き取れなくなるため、-20dB に留めます。
+const fadeFloorGain = 0.1
+
+// effectEnvelopes は音響効果のタグ名と、セグメント内の位置 (0〜1) に対する音量の倍率の対応です。
+var effectEnvelopes = map[string]func(pos float64) float64{
+	script.EffectFadeIn: func(pos float64) float64 {
+		return fadeFloorGain + (1-fadeFloorGain)*pos
+	},
+	script.EffectFadeOut: func(pos float64) float64 {
+		return 1 - (1-fadeFloorGain)*pos
+	},
+}
+
+// applyEffects は音響効果タグの付いたセグメントのPCMに音量エンベロープを適用します (effect ステージ)。
+// segmentWavs は適用後のWAVデータで置き換えます。
+func applyEffects(ctx context.Context, requests []synthRequest, segmentWavs [][]byte) error {
+	for i, req := range requests {
+		if len(req.segment.Effects) == 0 {
+			continue
+		}
+		w, err := audio.ParseWAV(segmentWavs[i])
+		if err != nil {
+			return fmt.Errorf("セグメント %d (行 %d) のWAV解析に失敗しました: %w", i+1, req.segment.Line, err)
+		}
+		for _, effect := range req.segment.Effects {
+			envelope, ok := effectEnvelopes[effect]
+			if !ok {
+				continue
+			}
+			w.ApplyEnvelope(envelope)
+			slog.DebugContext(ctx, "セグメントに音響効果を適用しました。", "line", req.segment.Line, "effect", effect)
+		}
+		segmentWavs[i] = w.Bytes()
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// ScriptChunkID は --embed-script でスクリプト全文と設定を埋め込む WAV のチャンクIDです。
+const ScriptChunkID = "pscr"
+
+// ErrNoEmbeddedScript は音声ファイルにスクリプトが埋め込まれていない場合に返されます。
+var ErrNoEmbeddedScript = errors.New("スクリプトが埋め込まれていません")
+
+// EmbeddedScript は合成した音声に埋め込むスクリプト全文と、生成・合成時の設定です (--embed-script)。
+type EmbeddedScript struct {
+	Script string `json:"script"`
+	// Settings は生成モードやモデル、合成オプションなどの設定です。キーはコマンドラインのフラグ名です。
+	Settings  map[string]string `json:"settings,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ScriptSidecarPath は WAV 以外の形式で出力する場合に、埋め込む代わりに書き込むサイドカーファイルのパスを返します
+// (例: out.mp3 → out.script.json)。
+func ScriptSidecarPath(outputPath string) string {
+	return strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".script.json"
+}
+
+// embedScript は出力する音声にスクリプト全文と設定を埋め込みます。
+// WAV の場合は ScriptChunkID のチャンクを追加したデータを返し、それ以外の形式の場合はサイドカーファイルを書き込んで data をそのまま返します。
+func (e *Engine) embedScript(ctx context.Context, outputPath string, data []byte, contentType, scriptContent string) ([]byte, error) {
+	body, err := json.Marshal(EmbeddedScript{Script: scriptContent, Settings: e.config.EmbedSettings, CreatedAt: time.Now()})
+	if err != nil {
+		return nil, fmt.Errorf("埋め込むスクリプトのエンコードに失敗しました: %w", err)
+	}
+
+	if contentType == "audio/wav" {
+		embedded, err := audio.AppendChunk(data, ScriptChunkID, body)
+		if err != nil {
+			return nil, fmt.Errorf("スクリプトの埋め込みに失敗しました: %w", err)
+		}
+		slog.InfoContext(ctx, "音声ファイルにスクリプトを埋め込みました。", "path", outputPath, "bytes", len(body))
+		return embedded, nil
+	}
+
+	sidecar := ScriptSidecarPath(outputPath)
+	if err := e.writer.Write(ctx, sidecar, bytes.NewReader(body), "application/json"); err != nil {
+		return nil, fmt.Errorf("スクリプトのサイドカーファイルの書き込みに失敗しました (%s): %w", sidecar, err)
+	}
+	slog.InfoContext(ctx, "WAV以外の形式のため、スクリプトをサイドカーファイルに書き込みました。", "path", sidecar)
+	return data, nil
+}
+
+// ReadEmbeddedScript は WAV のバイト列、または ScriptSidecarPath のサイドカーファイルの内容から、埋め込まれたスクリプトを読み出します。
+// WAV にスクリプトのチャンクが無い場合は ErrNoEmbeddedScript を返します。
+func ReadEmbeddedScript(data []byte) (*EmbeddedScript, error) {
+	body := data
+	if bytes.HasPrefix(data, []byte("RIFF")) {
+		chunk, err := audio.FindChunk(data, ScriptChunkID)
+		if errors.Is(err, audio.ErrChunkNotFound) {
+			return nil, ErrNoEmbeddedScript
+		}
+		if err != nil {
+			return nil, err
+		}
+		body = chunk
+	}
+
+	var embedded EmbeddedScript
+	if err := json.Unmarshal(body, &embedded); err != nil {
+		return nil, fmt.Errorf("埋め込まれたスクリプトの解析に失敗しました: %w", err)
+	}
+	return &embedded, nil
+}
@@ -0,0 +1,591 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/history"
+	"prototypus-ai-doc-go/internal/profile"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+const (
+	// DefaultConcurrency はエンジンへ同時に投入するセグメント数の上限の既定値です。
+	DefaultConcurrency = 6
+	// DefaultSegmentTimeoutBase は1セグメントの合成（audio_query + synthesis）のタイムアウトの基準値です。
+	DefaultSegmentTimeoutBase = 20 * time.Second
+	// DefaultSegmentTimeoutPerChar はセグメントの1文字あたりに加算するタイムアウトです。最大長 (250文字) で 120 秒になります。
+	DefaultSegmentTimeoutPerChar = 400 * time.Millisecond
+	// failFastMinCompleted は FailFastRate で失敗率を判定し始める完了セグメント数です。冒頭の数件の失敗だけで中断しないようにします。
+	failFastMinCompleted = 5
+)
+
+// AudioWriter は合成した音声の書き込み先です。remoteio.OutputWriter が満たします。
+type AudioWriter interface {
+	Write(ctx context.Context, path string, r io.Reader, contentType string) error
+}
+
+// EngineExecutor はスクリプトを音声合成して出力先に書き込む責務を持つインターフェースです。
+type EngineExecutor interface {
+	Execute(ctx context.Context, scriptContent string, outputPath string) error
+}
+
+// Synthesizer はエンジンとの通信を担う合成ステージです。*Client が満たします。
+// テストではこのインターフェースを差し替えることで、実エンジン無しで Engine の各ステージを検証できます。
+type Synthesizer interface {
+	// LoadSpeakers は話者・スタイルとスタイルIDの対応表を取得します。
+	LoadSpeakers(ctx context.Context) (*SpeakerData, error)
+	// Synthesize は1つのテキストを styleID で合成し、WAVデータを返します。
+	Synthesize(ctx context.Context, text string, styleID int, params SynthesisParams) ([]byte, error)
+}
+
+// Pinger は合成の前にエンジンに到達できるかを確認できる合成ステージです。*Client が満たします。
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// EngineConfig は Engine の動作オプションです。
+type EngineConfig struct {
+	// ScheduleByStyle が true の場合、同一スタイルのセグメントをまとめてエンジンに投入します。
+	ScheduleByStyle bool
+	// MergeSegments が true の場合、同じ話者・スタイルID・合成パラメータで隣接する短いセグメントを、
+	// 合計が1セグメントの最大文字数に収まる範囲で結合してから合成し、エンジンの呼び出し回数を減らします。
+	MergeSegments bool
+	// FallbackSpeakerTag はタグの無いテキストを合成する話者タグです。空の場合は合成時点の SupportedSpeakers の先頭を使います。
+	FallbackSpeakerTag string
+	// AutoChapterSilence が 0 より大きい場合、この長さ以上の無音をチャプター境界として検出し、チャプター一覧を出力します。
+	AutoChapterSilence time.Duration
+	// ABPointsPath が空でない場合、各セグメントの開始・終了時刻のリストを JSON で書き込みます。
+	ABPointsPath string
+	// SubtitlePath が空でない場合、各セグメントの本文と開始・終了時刻から SRT 形式の字幕を書き込みます。
+	SubtitlePath string
+	// SpeakerStatsPath が空でない場合、実際の合成尺から集計した話者別の発話統計を JSON で書き込みます。
+	SpeakerStatsPath string
+	// StatsPath が空でない場合、話者別の発話回数・文字数・セグメント数と実際の再生時間を集計したスクリプト統計を JSON で書き込みます。
+	StatsPath string
+	// SubtitleSpeakerPrefix が true の場合、字幕の各エントリに「ずんだもん: 」のような話者名を付けます。
+	SubtitleSpeakerPrefix bool
+	// SegmentGap はセグメント間に挿入する無音の長さです。0 の場合は隙間なく連結します。
+	SegmentGap time.Duration
+	// GapOnSpeakerChangeOnly が true の場合、SegmentGap の無音を話者タグが変わる境界にのみ挿入します。
+	GapOnSpeakerChangeOnly bool
+	// SegmentMarker は後段ツールでの自動分割のため、各セグメント境界に挿入する区切りマーカー音です。Duration が 0 の場合は挿入しません。
+	SegmentMarker SegmentMarker
+	// NormalizeSilence が 0 より大きい場合、結合した音声からこの長さより長い無音を検出し、この長さに詰めます。
+	// 字幕やチャプターなどの時刻は詰めた後の音声に合わせます。
+	NormalizeSilence time.Duration
+	// Automations はスクリプトの経過位置に応じて補間し、各セグメントに適用する合成パラメータの変化です。
+	Automations []Automation
+	// EmbedScript が true の場合、スクリプト全文と EmbedSettings を出力する WAV のチャンクに埋め込みます。
+	// WAV 以外の形式で出力する場合は、代わりにサイドカーファイル (<出力名>.script.json) に書き込みます。
+	EmbedScript bool
+	// EmbedSettings は EmbedScript で埋め込む生成・合成時の設定です。
+	EmbedSettings map[string]string
+	// SavePartial が true の場合、合成の中断時に先頭から連続して完成したセグメントを結合した部分WAVとメタ情報を書き込みます。
+	SavePartial bool
+	// DurationSanityCheck が true の場合、合成後に各セグメントの合成尺を文字数からの推定尺と比較し、大きく乖離するセグメントを警告します。
+	DurationSanityCheck bool
+	// PreciseSplit が true の場合、長すぎるセグメントをエンジンに問い合わせたアクセント句の境界に沿って分割します。
+	// 分割位置の候補ごとにエンジンへの問い合わせが発生します。
+	PreciseSplit bool
+	// LoudnessGate は結合した音声を書き込む前に、統合ラウドネスとピークを配信先の基準と照合する設定です。
+	// 圧縮形式へのエンコードの前に照合するため、出力形式によらず適用されます。
+	LoudnessGate LoudnessGate
+	// OutputSampleRate が 0 より大きく、結合した音声のサンプルレートと異なる場合は、書き込む前にこのサンプルレート (Hz) に変換します。
+	OutputSampleRate int
+	// SegmentDir が空でない場合、音響効果を適用した各セグメントの WAV を、番号と話者名を付けたファイル名 (例: 01_ずんだもん.wav) でこのディレクトリに書き込みます。
+	SegmentDir string
+	// SegmentsOnly が true の場合、SegmentDir へのセグメントごとの書き込みのみを行い、結合した音声と付随するメタ情報は書き込みません。
+	SegmentsOnly bool
+	// MaxMemory が 0 より大きい場合、合成した音声を保持するメモリの上限 (バイト) として扱います。
+	// 結合した音声をメモリ上に作ると上限を超える場合は、ストリーミングで書き込みます。それでも超える場合は合成の前または結合の前にエラーを返します。
+	// 合成中は上限に収まるよう同時実行数を抑え、ヒープの使用量が上限に近い間は新しいリクエストの投入を待機します。
+	MaxMemory int64
+	// MaxTempDisk が 0 より大きい場合、圧縮形式へのエンコードで使う一時ファイルの大きさの上限 (バイト) として扱い、超える場合は合成の前にエラーを返します。
+	MaxTempDisk int64
+	// AudioBitrate は出力を MP3・Opus・AAC にエンコードする際のビットレートです (例: "64k")。空の場合は形式ごとの既定値を使います。
+	AudioBitrate string
+	// SegmentTimeoutBase は1セグメントの合成のタイムアウトの基準値です。0 の場合は DefaultSegmentTimeoutBase を使います。
+	SegmentTimeoutBase time.Duration
+	// SegmentTimeoutPerChar はセグメントの1文字あたりに加算するタイムアウトです。0 の場合は DefaultSegmentTimeoutPerChar を使います。
+	SegmentTimeoutPerChar time.Duration
+	// PriorityGate が nil でない場合、同じエンジンを共有する他のプロセスのジョブとの間で合成の優先度を調停します。
+	// より高い優先度のジョブの実行中は、各セグメントの合成を開始する前に待機します。
+	PriorityGate *PriorityGate
+	// RequestsPerSecond が 0 より大きい場合、セグメントの合成 (audio_query + synthesis) の開始を秒間この回数までに制限します。
+	// 同時実行数の上限 (Concurrency) とは独立に、一定間隔でエンジンへ投入します。
+	RequestsPerSecond float64
+	// Concurrency はエンジンへ同時に投入するセグメント数の上限です。0 以下の場合は DefaultConcurrency を使います。
+	Concurrency int
+	// AllowPartial が true の場合、合成に失敗したセグメントを省略して残りのセグメントで出力し、失敗した一覧を警告します。
+	// すべてのセグメントが失敗した場合はエラーを返します。
+	AllowPartial bool
+	// FailFastRate が 0 より大きい場合、AllowPartial で完了したセグメントのうち失敗した割合がこの値を超えた時点で、
+	// 残りのセグメントの合成を中断してエラーを返します。エンジンの不調で大半が失敗する場合に、全セグメントを試し切る前に打ち切ります。
+	FailFastRate float64
+	// ForeignWords が script.ForeignWordKatakana の場合、英語区間をカタカナ読みに変換してから合成します。
+	// 空または script.ForeignWordKeep の場合はそのままエンジンに渡します。
+	ForeignWords string
+	// Progress が nil でない場合、セグメントの合成が1つ完了するたびに完了数と総数を通知します。
+	// 呼び出しは1つのゴルーチンから順に行われます。
+	Progress func(completed, total int)
+}
+
+// Engine はスクリプトを解析し、VOICEVOX エンジンで並列に合成して1つのWAVとして書き込みます。
+// 処理は ping / parse / resolve / synthesize / effect / combine / write の各ステージに分かれています。
+type Engine struct {
+	synthesizer Synthesizer
+	writer      AudioWriter
+	config      EngineConfig
+	pool        *synthPool
+
+	mu          sync.Mutex
+	speakerData *SpeakerData
+}
+
+// NewEngine は Engine を生成します。
+func NewEngine(synthesizer Synthesizer, writer AudioWriter, config EngineConfig) *Engine {
+	if config.SegmentTimeoutBase <= 0 {
+		config.SegmentTimeoutBase = DefaultSegmentTimeoutBase
+	}
+	if config.SegmentTimeoutPerChar <= 0 {
+		config.SegmentTimeoutPerChar = DefaultSegmentTimeoutPerChar
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = DefaultConcurrency
+	}
+	return &Engine{
+		synthesizer: synthesizer,
+		writer:      writer,
+		config:      config,
+		pool:        newSynthPool(synthesizer, config),
+	}
+}
+
+// fallbackSpeakerTag はタグの無いテキストを合成する話者タグを返します。
+// 入力のフロントマターで話者定義が読み込み直される場合があるため、Engine の生成時ではなく合成時に決めます。
+func (e *Engine) fallbackSpeakerTag() string {
+	if e.config.FallbackSpeakerTag != "" {
+		return e.config.FallbackSpeakerTag
+	}
+	return SupportedSpeakers[0].ToolTag
+}
+
+// synthRequest はエンジンへ投入する1セグメント分の合成リクエストです。
+type synthRequest struct {
+	segment script.Segment
+	styleID int
+	params  SynthesisParams
+	// reading は英語区間をカタカナ読みに変換した合成テキストです。空の場合は segment.Text を合成します。
+	reading string
+	// parts は MergeSegments で結合した元のリクエストです。結合していない場合は nil です。
+	parts []synthRequest
+}
+
+// Execute は EngineExecutor を実装します。
+func (e *Engine) Execute(ctx context.Context, scriptContent string, outputPath string) error {
+	return e.PostToEngine(ctx, scriptContent, outputPath)
+}
+
+// PostToEngine はスクリプトを解析してスタイルIDを解決し、セグメントを並列に合成・結合して outputPath に書き込みます。
+// --profile-stages が有効な場合は、各ステージの所要時間を context の profile.Profile に記録します。
+func (e *Engine) PostToEngine(ctx context.Context, scriptContent string, outputPath string) error {
+	// エンジンに到達できない場合は、話者一覧のキャッシュや全セグメントの再試行を待たずに中断する
+	if pinger, ok := e.synthesizer.(Pinger); ok {
+		stop := profile.Start(ctx, "ping")
+		err := pinger.Ping(ctx)
+		stop()
+		if err != nil {
+			return err
+		}
+	}
+	defer e.config.PriorityGate.enter(ctx)()
+
+	stop := profile.Start(ctx, "parse")
+	segments, err := parseSegments(ctx, scriptContent, e.splitFunc(ctx))
+	stop()
+	if err != nil {
+		return err
+	}
+
+	stop = profile.Start(ctx, "resolve")
+	speakerData, err := e.loadSpeakerData(ctx)
+	if err != nil {
+		stop()
+		return err
+	}
+	requests, err := resolveRequests(ctx, segments, speakerData, e.fallbackSpeakerTag())
+	stop()
+	if err != nil {
+		return err
+	}
+	if e.config.ForeignWords == script.ForeignWordKatakana {
+		applyKatakanaReadings(ctx, requests)
+	}
+	applyAutomations(requests, e.config.Automations)
+	if e.config.MergeSegments {
+		requests = e.mergeRequests(ctx, requests)
+	}
+	if err := e.checkResources(ctx, outputPath, requests); err != nil {
+		return err
+	}
+
+	stop = profile.Start(ctx, "synthesize")
+	orderedAudioDataList, err := e.synthesizeRequests(ctx, requests)
+	stop()
+	if err != nil {
+		if e.config.SavePartial {
+			if saveErr := e.savePartial(ctx, outputPath, requests, orderedAudioDataList, err); saveErr != nil {
+				slog.WarnContext(ctx, "部分WAVの保存に失敗しました。", "error", saveErr)
+			}
+		}
+		return err
+	}
+	if e.config.AllowPartial {
+		requests, orderedAudioDataList = dropFailedSegments(ctx, requests, orderedAudioDataList)
+	}
+	if e.config.DurationSanityCheck {
+		if err := checkDurations(ctx, requests, orderedAudioDataList); err != nil {
+			return err
+		}
+	}
+	stop = profile.Start(ctx, "effect")
+	err = applyEffects(ctx, requests, orderedAudioDataList)
+	stop()
+	if err != nil {
+		return err
+	}
+	if e.config.SegmentDir != "" {
+		stop = profile.Start(ctx, "write_segments")
+		seconds, err := e.writeSegments(ctx, requests, orderedAudioDataList)
+		stop()
+		if err != nil {
+			return err
+		}
+		if e.config.SegmentsOnly {
+			history.AddAudio(ctx, seconds)
+			slog.InfoContext(ctx, "セグメントごとの音声ファイルのみを出力するため、結合した音声は書き込みません。")
+			return nil
+		}
+	}
+
+	gaps := e.segmentGaps(requests)
+	stream, err := e.planOutput(outputPath, segmentBytes(orderedAudioDataList))
+	if err != nil {
+		return err
+	}
+	if stream {
+		slog.InfoContext(ctx, "メモリの上限を超えないよう、結合した音声をストリーミングで書き込みます。", "max_memory", e.config.MaxMemory)
+		defer profile.Start(ctx, "write")()
+		return e.writeStreamed(ctx, outputPath, requests, orderedAudioDataList, gaps)
+	}
+
+	stop = profile.Start(ctx, "combine")
+	combined, err := combineWavData(orderedAudioDataList, gaps, e.config.SegmentMarker)
+	stop()
+	if err != nil {
+		return fmt.Errorf("WAVデータの結合に失敗しました: %w", err)
+	}
+	var trimmed []audio.Span
+	if e.config.NormalizeSilence > 0 {
+		stop = profile.Start(ctx, "normalize_silence")
+		combined, trimmed, err = normalizeSilence(ctx, combined, e.config.NormalizeSilence)
+		stop()
+		if err != nil {
+			return err
+		}
+	}
+	if e.config.OutputSampleRate > 0 {
+		stop = profile.Start(ctx, "resample")
+		combined, err = resampleOutput(ctx, combined, e.config.OutputSampleRate)
+		stop()
+		if err != nil {
+			return err
+		}
+	}
+	var loudnessViolation error
+	if e.config.LoudnessGate.Action != "" {
+		stop = profile.Start(ctx, "loudness")
+		combined, loudnessViolation, err = e.checkLoudness(ctx, outputPath, combined)
+		stop()
+		if err != nil {
+			return err
+		}
+	}
+	if w, err := audio.ParseWAV(combined); err == nil {
+		history.AddAudio(ctx, w.Duration())
+		if err := e.checkTempDisk(outputPath, w.Duration()); err != nil {
+			return err
+		}
+	}
+
+	stop = profile.Start(ctx, "write")
+	err = e.writeOutputs(ctx, outputPath, scriptContent, combined, requests, orderedAudioDataList, gaps, trimmed)
+	stop()
+	if err != nil {
+		return err
+	}
+	return loudnessViolation
+}
+
+// segmentGaps は各セグメントの直前に挿入する無音の長さを返します。無音を挿入しない場合は nil を返します。
+func (e *Engine) segmentGaps(requests []synthRequest) []time.Duration {
+	if e.config.SegmentGap <= 0 {
+		return nil
+	}
+	gaps := make([]time.Duration, len(requests))
+	for i := 1; i < len(requests); i++ {
+		if e.config.GapOnSpeakerChangeOnly && requests[i].segment.SpeakerTag == requests[i-1].segment.SpeakerTag {
+			continue
+		}
+		gaps[i] = e.config.SegmentGap
+	}
+	return gaps
+}
+
+// parseSegments はスクリプトを解析して合成対象のセグメントを返します (parse ステージ)。
+// 話者タグの表記揺れは解析の前に既知の話者タグに正規化します。長すぎるテキストは split で分割します。split が nil の場合は句読点で分割します。
+func parseSegments(ctx context.Context, scriptContent string, split script.SplitFunc) ([]script.Segment, error) {
+	scriptContent, aliases := NormalizeSpeakerAliases(scriptContent)
+	if len(aliases) > 0 {
+		slog.InfoContext(ctx, "話者タグの表記揺れを正規化しました。", "tags", aliases)
+	}
+	segments := script.ParseWith(scriptContent, split)
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("スクリプトから合成対象のセグメントが見つかりませんでした")
+	}
+	return segments, nil
+}
+
+// resolveRequests は各セグメントのスタイルIDと合成パラメータタグを解決して合成リクエストに変換します (resolve ステージ)。
+// 話者を解決できないセグメントは警告を出してスキップします。
+func resolveRequests(ctx context.Context, segments []script.Segment, speakerData *SpeakerData, fallbackSpeakerTag string) ([]synthRequest, error) {
+	requests := make([]synthRequest, 0, len(segments))
+	for _, seg := range segments {
+		styleID, ok := speakerData.determineStyleID(seg, fallbackSpeakerTag)
+		if !ok {
+			slog.WarnContext(ctx, "未対応の話者タグのためセグメントをスキップします。", "speaker", seg.SpeakerTag, "line", seg.Line)
+			continue
+		}
+		requests = append(requests, synthRequest{segment: seg, styleID: styleID, params: prosodyParams(ctx, seg)})
+	}
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("合成可能なセグメントがありません。話者タグを確認してください")
+	}
+	return requests, nil
+}
+
+// synthesizeRequests は投入順序を決めてリクエストを並列に合成し、スクリプト順のWAVデータを返します (synthesize ステージ)。
+func (e *Engine) synthesizeRequests(ctx context.Context, requests []synthRequest) ([][]byte, error) {
+	order := sequentialOrder(len(requests))
+	if e.config.ScheduleByStyle {
+		scheduled := scheduleByStyle(requests)
+		slog.InfoContext(ctx, "スタイル単位で合成順序を最適化しました。",
+			"style_switches_before", countStyleSwitches(requests, order),
+			"style_switches_after", countStyleSwitches(requests, scheduled))
+		order = scheduled
+	}
+
+	slog.InfoContext(ctx, "セグメントの音声合成を開始します。", "segments", len(requests), "parallel", e.config.Concurrency, "rps", e.config.RequestsPerSecond)
+	return e.synthesizeAll(ctx, requests, order)
+}
+
+// writeOutputs は結合したWAVと付随するメタ情報を書き込みます (write ステージ)。
+// trimmed は NormalizeSilence で結合した音声から削除した区間です。
+func (e *Engine) writeOutputs(ctx context.Context, outputPath, scriptContent string, combined []byte, requests []synthRequest, segmentWavs [][]byte, gaps []time.Duration, trimmed []audio.Span) error {
+	data, contentType, err := encodeOutput(ctx, outputPath, combined, e.config.AudioBitrate)
+	if err != nil {
+		return err
+	}
+	if e.config.EmbedScript {
+		if data, err = e.embedScript(ctx, outputPath, data, contentType, scriptContent); err != nil {
+			return err
+		}
+	}
+	if err := e.writer.Write(ctx, outputPath, bytes.NewReader(data), contentType); err != nil {
+		return fmt.Errorf("音声ファイルの書き込みに失敗しました (%s): %w", outputPath, err)
+	}
+	return e.writeMetadata(ctx, outputPath, combined, requests, segmentWavs, gaps, trimmed)
+}
+
+// writeStreamed は結合した音声をメモリ上に作らずに、セグメントの PCM を順に読み出して書き込みます (MaxMemory)。
+// 結合した音声全体を必要とする処理 (streamBlocker) を併用していない場合にのみ使います。
+func (e *Engine) writeStreamed(ctx context.Context, outputPath string, requests []synthRequest, segmentWavs [][]byte, gaps []time.Duration) error {
+	r, duration, err := combinedWavReader(segmentWavs, gaps, e.config.SegmentMarker)
+	if err != nil {
+		return fmt.Errorf("WAVデータの結合に失敗しました: %w", err)
+	}
+	history.AddAudio(ctx, duration)
+	if err := e.writer.Write(ctx, outputPath, r, "audio/wav"); err != nil {
+		return fmt.Errorf("音声ファイルの書き込みに失敗しました (%s): %w", outputPath, err)
+	}
+	return e.writeMetadata(ctx, outputPath, nil, requests, segmentWavs, gaps, nil)
+}
+
+// segmentBytes は合成したセグメントの WAV の合計バイト数を返します。
+func segmentBytes(segmentWavs [][]byte) int64 {
+	var n int64
+	for _, data := range segmentWavs {
+		n += int64(len(data))
+	}
+	return n
+}
+
+// writeMetadata は字幕やスクリプト統計など、音声に付随するメタ情報を書き込みます。
+// combined はストリーミングで書き込んだ場合は nil で、その場合は結合した音声を必要とする出力 (streamBlocker) は無効になっています。
+// セグメントの区間の時刻は、trimmed (NormalizeSilence で削除した区間) を除いた音声の時刻に変換します。
+func (e *Engine) writeMetadata(ctx context.Context, outputPath string, combined []byte, requests []synthRequest, segmentWavs [][]byte, gaps []time.Duration, trimmed []audio.Span) error {
+	if e.config.StatsPath != "" {
+		if err := e.writeScriptStats(ctx, e.config.StatsPath, requests, segmentWavs, combined); err != nil {
+			return err
+		}
+	}
+
+	conclusion := hasConclusion(requests)
+	if e.config.AutoChapterSilence == 0 && e.config.ABPointsPath == "" && e.config.SubtitlePath == "" && e.config.SpeakerStatsPath == "" && !conclusion {
+		return nil
+	}
+	spans, err := segmentSpans(segmentWavs, gaps, e.config.SegmentMarker)
+	if err != nil {
+		return err
+	}
+	for i, span := range spans {
+		spans[i] = audio.Span{Start: audio.ShiftTime(span.Start, trimmed), End: audio.ShiftTime(span.End, trimmed)}
+	}
+	// 字幕などは結合前のセグメント単位で出力する
+	requests, spans = splitMergedSpans(requests, spans)
+
+	if e.config.AutoChapterSilence > 0 {
+		texts := make([]string, len(requests))
+		for i, req := range requests {
+			texts[i] = req.segment.Text
+		}
+		if err := e.writeChapters(ctx, combined, spans, texts, outputPath); err != nil {
+			return err
+		}
+	}
+	if e.config.ABPointsPath != "" {
+		if err := e.writeABPoints(ctx, e.config.ABPointsPath, requests, spans); err != nil {
+			return err
+		}
+	}
+	if e.config.SubtitlePath != "" {
+		if err := e.writeSubtitle(ctx, e.config.SubtitlePath, requests, spans); err != nil {
+			return err
+		}
+	}
+	if e.config.SpeakerStatsPath != "" {
+		if err := e.writeSpeakerStats(ctx, e.config.SpeakerStatsPath, requests, spans); err != nil {
+			return err
+		}
+	}
+	if conclusion {
+		if err := e.writeMarkers(ctx, requests, spans, outputPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SynthesizeText は1つのテキストを指定した話者・スタイル・合成パラメータで合成し、WAVデータを返します。
+// スタイルが見つからない場合は話者の既定スタイルで合成します。
+func (e *Engine) SynthesizeText(ctx context.Context, text, speakerTag, styleTag string, params SynthesisParams) ([]byte, error) {
+	speakerData, err := e.loadSpeakerData(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seg := script.Segment{SpeakerTag: speakerTag, StyleTag: styleTag, Text: text}
+	styleID, ok := speakerData.determineStyleID(seg, e.fallbackSpeakerTag())
+	if !ok {
+		return nil, fmt.Errorf("未対応の話者タグです: %s", speakerTag)
+	}
+	return e.pool.synthesize(ctx, synthRequest{segment: seg, styleID: styleID, params: params})
+}
+
+// loadSpeakerData はエンジンの話者情報を取得します。一度取得した結果は Engine の生存期間中再利用します。
+func (e *Engine) loadSpeakerData(ctx context.Context) (*SpeakerData, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.speakerData != nil {
+		return e.speakerData, nil
+	}
+	data, err := e.synthesizer.LoadSpeakers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	e.speakerData = data
+	return data, nil
+}
+
+// synthesizeAll は order の順にセグメントをエンジンへ投入し、スクリプト順に並べたWAVデータを返します。
+// いずれかのセグメントが失敗した場合は残りの処理を中断してエラーを返します。
+// その場合も、中断までに合成が完了したセグメントの WAV データ (未完了のセグメントは nil) を返します。
+func (e *Engine) synthesizeAll(ctx context.Context, requests []synthRequest, order []int) ([][]byte, error) {
+	orderedAudioDataList := make([][]byte, len(requests))
+	var firstErr error
+	failed, completed := 0, 0
+	e.pool.run(ctx, requests, order, func(result segmentResult) bool {
+		completed++
+		if result.err == nil {
+			orderedAudioDataList[result.index] = result.wavData
+			return firstErr == nil
+		}
+		err := fmt.Errorf("セグメント %d (行 %d) の合成に失敗しました: %w", result.index+1, requests[result.index].segment.Line, result.err)
+		// 呼び出し元のキャンセルや中断後のキャンセルは部分的な成功として扱わない
+		if e.config.AllowPartial && ctx.Err() == nil && firstErr == nil {
+			slog.WarnContext(ctx, "セグメントの合成に失敗しました。このセグメントを省略して続行します。", "segment", result.index+1, "line", requests[result.index].segment.Line, "error", result.err)
+			failed++
+			if e.exceedsFailFastRate(failed, completed, len(requests)) {
+				// 投入済みのセグメントはキャンセルで打ち切られ、残りの結果も受け取ってから返す
+				firstErr = fmt.Errorf("合成に失敗したセグメントの割合が上限 (%.0f%%) を超えたため中断しました (完了 %d 件中 %d 件が失敗): %w", e.config.FailFastRate*100, completed, failed, err)
+			}
+		} else if firstErr == nil {
+			firstErr = err
+		}
+		return firstErr == nil
+	})
+	if firstErr != nil {
+		return orderedAudioDataList, firstErr
+	}
+	if failed == len(requests) {
+		return nil, fmt.Errorf("すべてのセグメント (%d 件) の合成に失敗しました", failed)
+	}
+	return orderedAudioDataList, nil
+}
+
+// exceedsFailFastRate は完了した completed 件のセグメントのうち failed 件が失敗した時点で、失敗率が FailFastRate を超えたかを返します。
+// 完了数が failFastMinCompleted (セグメント数がそれより少ない場合はセグメント数) に達するまでは判定しません。
+func (e *Engine) exceedsFailFastRate(failed, completed, total int) bool {
+	if e.config.FailFastRate <= 0 || completed < min(failFastMinCompleted, total) {
+		return false
+	}
+	return float64(failed)/float64(completed) > e.config.FailFastRate
+}
+
+// dropFailedSegments は --allow-partial で合成に失敗したセグメント (WAVデータが nil) を取り除き、失敗した一覧を警告します。
+func dropFailedSegments(ctx context.Context, requests []synthRequest, wavs [][]byte) ([]synthRequest, [][]byte) {
+	var failedLines []int
+	keptRequests := make([]synthRequest, 0, len(requests))
+	keptWavs := make([][]byte, 0, len(wavs))
+	for i, data := range wavs {
+		if data == nil {
+			failedLines = append(failedLines, requests[i].segment.Line)
+			continue
+		}
+		keptRequests = append(keptRequests, requests[i])
+		keptWavs = append(keptWavs, data)
+	}
+	if len(failedLines) > 0 {
+		slog.WarnContext(ctx, "一部のセグメントの合成に失敗したため、省略して音声を出力します。",
+			"failed", len(failedLines), "total", len(requests), "failed_lines", failedLines)
+	}
+	return keptRequests, keptWavs
+}
@@ -0,0 +1,249 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+var testFormat = audio.Format{AudioFormat: 1, Channels: 1, SampleRate: 24000, ByteRate: 48000, BlockAlign: 2, BitsPerSample: 16}
+
+// stubSynthesizer は Engine の Synthesizer の差し替え口を確かめるための合成ステージです。
+// テキストごとに決まった PCM を返し、delays に指定したテキストは応答を遅らせて完了順をスクリプト順と入れ替えます。
+type stubSynthesizer struct {
+	pcm    map[string][]byte
+	delays map[string]time.Duration
+}
+
+func (s *stubSynthesizer) LoadSpeakers(context.Context) (*SpeakerData, error) {
+	return &SpeakerData{
+		StyleIDs:        map[string]int{"[ずんだもん][ノーマル]": 3, "[めたん][ノーマル]": 2},
+		DefaultStyleIDs: map[string]int{"[ずんだもん]": 3, "[めたん]": 2},
+	}, nil
+}
+
+func (s *stubSynthesizer) Synthesize(ctx context.Context, text string, _ int, _ SynthesisParams) ([]byte, error) {
+	select {
+	case <-time.After(s.delays[text]):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return audio.Encode(testFormat, s.pcm[text]), nil
+}
+
+// memoryWriter は書き込まれたファイルを保持する AudioWriter です。
+type memoryWriter struct {
+	mu    sync.Mutex
+	files map[string][]byte
+}
+
+func (w *memoryWriter) Write(_ context.Context, path string, r io.Reader, _ string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.files[path] = b
+	return nil
+}
+
+func TestEngineSynthesizerOrder(t *testing.T) {
+	synth := &stubSynthesizer{
+		pcm: map[string][]byte{
+			"一番目なのだ。": bytes.Repeat([]byte{1, 0}, 100),
+			"二番目ですわ。": bytes.Repeat([]byte{2, 0}, 200),
+			"三番目なのだ。": bytes.Repeat([]byte{3, 0}, 300),
+		},
+		// 先頭のセグメントほど遅く完了させる
+		delays: map[string]time.Duration{
+			"一番目なのだ。": 60 * time.Millisecond,
+			"二番目ですわ。": 30 * time.Millisecond,
+		},
+	}
+	writer := &memoryWriter{files: map[string][]byte{}}
+	engine := NewEngine(synth, writer, EngineConfig{})
+
+	scriptContent := "[ずんだもん][ノーマル] 一番目なのだ。\n[めたん][ノーマル] 二番目ですわ。\n[ずんだもん][ノーマル] 三番目なのだ。"
+	if err := engine.Execute(context.Background(), scriptContent, "out.wav"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	w, err := audio.ParseWAV(writer.files["out.wav"])
+	if err != nil {
+		t.Fatalf("output is not a valid WAV: %v", err)
+	}
+	var want []byte
+	for _, text := range []string{"一番目なのだ。", "二番目ですわ。", "三番目なのだ。"} {
+		want = append(want, synth.pcm[text]...)
+	}
+	if !bytes.Equal(w.Data, want) {
+		t.Errorf("PCM = %d bytes, want %d bytes in script order", len(w.Data), len(want))
+	}
+}
+
+func TestCombineWavData(t *testing.T) {
+	first := bytes.Repeat([]byte{1, 0}, 10)
+	second := bytes.Repeat([]byte{2, 0}, 20)
+	wavs := [][]byte{audio.Encode(testFormat, first), audio.Encode(testFormat, second)}
+
+	tests := []struct {
+		name string
+		gaps []time.Duration
+		want []byte
+	}{
+		{"連結", nil, append(append([]byte{}, first...), second...)},
+		// 24kHz・16bit モノラルで 1ms は 24 サンプル (48 バイト)
+		{"セグメント間の無音", []time.Duration{0, time.Millisecond}, append(append(append([]byte{}, first...), make([]byte, 48)...), second...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			combined, err := combineWavData(wavs, tt.gaps, SegmentMarker{})
+			if err != nil {
+				t.Fatalf("combineWavData() error = %v", err)
+			}
+			w, err := audio.ParseWAV(combined)
+			if err != nil {
+				t.Fatalf("combined data is not a valid WAV: %v", err)
+			}
+			if w.Format != testFormat {
+				t.Errorf("format = %+v, want %+v", w.Format, testFormat)
+			}
+			if !bytes.Equal(w.Data, tt.want) {
+				t.Errorf("PCM = %d bytes, want %d bytes", len(w.Data), len(tt.want))
+			}
+		})
+	}
+
+	if _, err := combineWavData(nil, nil, SegmentMarker{}); err == nil {
+		t.Error("combineWavData(nil) error = nil, want an error")
+	}
+	stereo := testFormat
+	stereo.Channels, stereo.BlockAlign, stereo.ByteRate = 2, 4, 96000
+	if _, err := combineWavData([][]byte{wavs[0], audio.Encode(stereo, second)}, nil, SegmentMarker{}); err == nil {
+		t.Error("combineWavData() with mismatched formats error = nil, want an error")
+	}
+}
+
+// wrappingWriter は書き込んだパスを記録して内側の AudioWriter に委譲するデコレータです。
+type wrappingWriter struct {
+	AudioWriter
+	mu    sync.Mutex
+	paths []string
+}
+
+func (w *wrappingWriter) Write(ctx context.Context, path string, r io.Reader, contentType string) error {
+	w.mu.Lock()
+	w.paths = append(w.paths, path)
+	w.mu.Unlock()
+	return w.AudioWriter.Write(ctx, path, r, contentType)
+}
+
+func (w *wrappingWriter) Unwrap() AudioWriter { return w.AudioWriter }
+
+func TestEngineSegmentsBypassDecorators(t *testing.T) {
+	synth := &stubSynthesizer{pcm: map[string][]byte{
+		"一番目なのだ。": bytes.Repeat([]byte{1, 0}, 100),
+		"二番目ですわ。": bytes.Repeat([]byte{2, 0}, 200),
+	}}
+	inner := &memoryWriter{files: map[string][]byte{}}
+	decorated := &wrappingWriter{AudioWriter: inner}
+	engine := NewEngine(synth, decorated, EngineConfig{SegmentDir: "segments"})
+
+	scriptContent := "[ずんだもん][ノーマル] 一番目なのだ。\n[めたん][ノーマル] 二番目ですわ。"
+	if err := engine.Execute(context.Background(), scriptContent, "out.wav"); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	for _, path := range []string{"segments/01_ずんだもん.wav", "segments/02_めたん.wav"} {
+		if _, ok := inner.files[path]; !ok {
+			t.Errorf("segment %s was not written; files: %v", path, inner.files)
+		}
+	}
+	for _, path := range decorated.paths {
+		if path != "out.wav" {
+			t.Errorf("decorator received %s, want only out.wav", path)
+		}
+	}
+}
+
+func TestSynthPoolConcurrencyForMemory(t *testing.T) {
+	// 15 文字は推定 2 秒 (96000 バイト) の音声になる
+	text := strings.Repeat("あ", 15)
+	requests := make([]synthRequest, 3)
+	for i := range requests {
+		requests[i].segment.Text = text
+	}
+	order := []int{0, 1, 2}
+
+	tests := []struct {
+		name  string
+		limit int64
+		want  int
+	}{
+		{"上限なし", 0, 6},
+		{"十分な上限", 1 << 30, 6},
+		// 保持する 288000 バイトに加えて、1リクエストあたり 192000 バイトの2つ分
+		{"上限に合わせて抑える", 288000 + 2*192000, 2},
+		{"上限を超えても1つは合成する", 100000, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newSynthPool(nil, EngineConfig{Concurrency: 6, MaxMemory: tt.limit})
+			if got := pool.concurrencyFor(requests, order); got != tt.want {
+				t.Errorf("concurrencyFor() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// concurrencySynthesizer は同時に合成中のリクエスト数の最大値を記録する Synthesizer です。
+type concurrencySynthesizer struct {
+	stubSynthesizer
+	mu           sync.Mutex
+	active, peak int
+}
+
+func (s *concurrencySynthesizer) Synthesize(ctx context.Context, text string, styleID int, params SynthesisParams) ([]byte, error) {
+	s.mu.Lock()
+	s.active++
+	s.peak = max(s.peak, s.active)
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	return s.stubSynthesizer.Synthesize(ctx, text, styleID, params)
+}
+
+func TestSynthPoolWaitsUnderMemoryPressure(t *testing.T) {
+	prev := heapInUse
+	heapInUse = func() uint64 { return 1 << 40 }
+	t.Cleanup(func() { heapInUse = prev })
+
+	synth := &concurrencySynthesizer{}
+	pool := newSynthPool(synth, EngineConfig{Concurrency: 4, MaxMemory: 1 << 30, SegmentTimeoutBase: time.Second})
+	requests := make([]synthRequest, 4)
+	completed := 0
+	pool.run(context.Background(), requests, []int{0, 1, 2, 3}, func(result segmentResult) bool {
+		if result.err != nil {
+			t.Errorf("segment %d error = %v", result.index, result.err)
+		}
+		completed++
+		return true
+	})
+	if completed != 4 {
+		t.Errorf("completed = %d, want 4", completed)
+	}
+	if synth.peak != 1 {
+		t.Errorf("peak concurrency = %d, want 1 under memory pressure", synth.peak)
+	}
+}
@@ -0,0 +1,40 @@
+package voicevox
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// applyKatakanaReadings は各合成リクエストの英語区間をカタカナ読みに変換した読みを設定します。
+// 字幕や A-B 区間リストには元のテキストを使うため、segment.Text は変更しません。
+func applyKatakanaReadings(ctx context.Context, requests []synthRequest) {
+	converted := 0
+	var unresolved []string
+	for i := range requests {
+		reading, words := script.ToKatakanaReading(requests[i].segment.Text)
+		if reading != requests[i].segment.Text {
+			requests[i].reading = reading
+			converted++
+		}
+		for _, w := range words {
+			if !slices.Contains(unresolved, w) {
+				unresolved = append(unresolved, w)
+			}
+		}
+	}
+	slog.InfoContext(ctx, "英語区間をカタカナ読みに変換しました。", "segments", converted)
+	if len(unresolved) > 0 {
+		slog.WarnContext(ctx, "カタカナ読みが不明な英語の語はそのままエンジンに渡します。", "words", unresolved)
+	}
+}
+
+// text はエンジンに渡す合成テキストを返します。読みが設定されていない場合はセグメントの本文です。
+func (r synthRequest) text() string {
+	if r.reading != "" {
+		return r.reading
+	}
+	return r.segment.Text
+}
@@ -0,0 +1,72 @@
+package voicevox
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// ラウドネス基準を外れた場合の動作です (--loudness-gate-action)。
+const (
+	// LoudnessActionWarn は基準外の測定値を警告し、そのまま書き込みます。
+	LoudnessActionWarn = "warn"
+	// LoudnessActionFix はゲインを補正してから書き込みます。
+	LoudnessActionFix = "fix"
+	// LoudnessActionFail はそのまま書き込んだ後にエラーを返し、終了コードで失敗を通知します。
+	LoudnessActionFail = "fail"
+)
+
+// LoudnessActions は --loudness-gate-action に指定できる値の一覧です。
+var LoudnessActions = []string{LoudnessActionWarn, LoudnessActionFix, LoudnessActionFail}
+
+// LoudnessGate は結合した音声の統合ラウドネスとピークを配信先の基準と照合する設定です (--loudness-gate)。
+// Action が空の場合は照合しません。
+type LoudnessGate struct {
+	Preset audio.LoudnessPreset
+	Action string
+}
+
+// checkLoudness はエンコード前の結合した WAV を LoudnessGate の基準と照合します。出力形式によらず、書き込む音声と同じ PCM を測定します。
+// Action が fix の場合は補正した WAV を返します。fail の場合は基準外であることを violation として返し、呼び出し元は音声を書き込んだ後にそのエラーを返します。
+func (e *Engine) checkLoudness(ctx context.Context, outputPath string, combined []byte) (fixed []byte, violation error, err error) {
+	gate := e.config.LoudnessGate
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ラウドネス測定のためのWAV解析に失敗しました: %w", err)
+	}
+
+	report := audio.MeasureLoudness(w)
+	problems := report.Check(gate.Preset)
+	attrs := []any{
+		"path", outputPath,
+		"preset", gate.Preset.Name,
+		"integrated_lufs", fmt.Sprintf("%.1f", report.IntegratedLUFS),
+		"peak_db", fmt.Sprintf("%.1f", report.PeakDB),
+	}
+	if len(problems) == 0 {
+		slog.InfoContext(ctx, "音声のラウドネスは配信基準の範囲内です。", attrs...)
+		return combined, nil, nil
+	}
+
+	if gate.Action == LoudnessActionFix {
+		gain := audio.NormalizeLoudness(w, gate.Preset)
+		after := audio.MeasureLoudness(w)
+		slog.InfoContext(ctx, "音声のラウドネスを配信基準に合わせて補正しました。", append(attrs,
+			"gain_db", fmt.Sprintf("%+.1f", gain),
+			"fixed_lufs", fmt.Sprintf("%.1f", after.IntegratedLUFS),
+			"fixed_peak_db", fmt.Sprintf("%.1f", after.PeakDB))...)
+		if rest := after.Check(gate.Preset); len(rest) > 0 {
+			slog.WarnContext(ctx, "補正後も配信基準を満たしていません。", "path", outputPath, "problems", rest)
+		}
+		return w.Bytes(), nil, nil
+	}
+
+	slog.WarnContext(ctx, "音声のラウドネスが配信基準を外れています。", append(attrs, "problems", problems)...)
+	if gate.Action == LoudnessActionFail {
+		violation = fmt.Errorf("音声のラウドネスが配信基準 (%s) を満たしていません (%s): %s", gate.Preset.Name, outputPath, strings.Join(problems, ", "))
+	}
+	return combined, violation, nil
+}
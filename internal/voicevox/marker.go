@@ -0,0 +1,73 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// markerConclusion は結論区間を表すマーカーの種類です。
+const markerConclusion = "conclusion"
+
+// Marker はスクリプトのマーカーで囲まれた、結合後の音声上の区間です。字幕やチャプターで位置を示すために使います。
+type Marker struct {
+	Type         string  `json:"type"`
+	FirstSegment int     `json:"first_segment"`
+	LastSegment  int     `json:"last_segment"`
+	Start        float64 `json:"start_sec"`
+	End          float64 `json:"end_sec"`
+}
+
+// hasConclusion は結論区間のセグメントを含むかを返します。
+func hasConclusion(requests []synthRequest) bool {
+	for _, req := range requests {
+		if req.segment.Conclusion {
+			return true
+		}
+	}
+	return false
+}
+
+// buildMarkers は連続する結論セグメントをまとめて、結論区間の一覧を組み立てます。
+func buildMarkers(requests []synthRequest, spans []audio.Span) []Marker {
+	var markers []Marker
+	for i, req := range requests {
+		if !req.segment.Conclusion {
+			continue
+		}
+		if n := len(markers); n > 0 && markers[n-1].LastSegment == i {
+			markers[n-1].LastSegment = i + 1
+			markers[n-1].End = spans[i].End
+			continue
+		}
+		markers = append(markers, Marker{
+			Type:         markerConclusion,
+			FirstSegment: i + 1,
+			LastSegment:  i + 1,
+			Start:        spans[i].Start,
+			End:          spans[i].End,
+		})
+	}
+	return markers
+}
+
+// writeMarkers は結論区間の一覧を <出力パス>.markers.json に書き込みます。
+func (e *Engine) writeMarkers(ctx context.Context, requests []synthRequest, spans []audio.Span, outputPath string) error {
+	markers := buildMarkers(requests, spans)
+	body, err := json.MarshalIndent(markers, "", "  ")
+	if err != nil {
+		return fmt.Errorf("マーカー区間のエンコードに失敗しました: %w", err)
+	}
+	markersPath := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".markers.json"
+	if err := e.writer.Write(ctx, markersPath, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("マーカー区間の書き込みに失敗しました (%s): %w", markersPath, err)
+	}
+	slog.InfoContext(ctx, "結論区間を出力しました。", "markers", len(markers), "path", markersPath)
+	return nil
+}
@@ -0,0 +1,102 @@
+package voicevox
+
+import (
+	"context"
+	"log/slog"
+	"unicode/utf8"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// mergeRequests は同じ話者・スタイルID・合成パラメータで隣接するリクエストを、合計の文字数が script.MaxSegmentCharLength 以内に
+// 収まる範囲で1つのリクエストに結合し、エンジンの呼び出し回数を減らします (MergeSegments)。
+// 結合したリクエストは元のリクエストを parts に保持し、字幕などのメタ情報は splitMergedSpans で元のセグメント単位に戻して書き込みます。
+// 音響効果の適用位置やセグメント間の無音・区切りマーカー音が変わらないよう、それらが関わる境界では結合しません。
+func (e *Engine) mergeRequests(ctx context.Context, requests []synthRequest) []synthRequest {
+	if e.config.SegmentMarker.Duration > 0 || (e.config.SegmentGap > 0 && !e.config.GapOnSpeakerChangeOnly) {
+		slog.InfoContext(ctx, "セグメントの境界ごとに無音または区切りマーカー音を挿入するため、セグメントを結合しません。")
+		return requests
+	}
+
+	merged := make([]synthRequest, 0, len(requests))
+	for _, req := range requests {
+		if n := len(merged); n > 0 && canMerge(merged[n-1], req) {
+			merged[n-1] = mergeRequest(merged[n-1], req)
+			continue
+		}
+		merged = append(merged, req)
+	}
+	if len(merged) < len(requests) {
+		slog.InfoContext(ctx, "隣接する同じスタイルのセグメントを結合しました。", "segments_before", len(requests), "segments_after", len(merged))
+	}
+	return merged
+}
+
+// canMerge は prev の後ろに next を結合して1回の呼び出しで合成できるかを返します。
+func canMerge(prev, next synthRequest) bool {
+	return prev.styleID == next.styleID &&
+		prev.segment.SpeakerTag == next.segment.SpeakerTag &&
+		prev.segment.Conclusion == next.segment.Conclusion &&
+		len(prev.segment.Effects) == 0 && len(next.segment.Effects) == 0 &&
+		prev.params.Equal(next.params) &&
+		utf8.RuneCountInString(script.JoinText(prev.text(), next.text())) <= script.MaxSegmentCharLength
+}
+
+// mergeRequest は prev の後ろに next を結合したリクエストを返します。行番号は prev の先頭のセグメントの行です。
+func mergeRequest(prev, next synthRequest) synthRequest {
+	parts := prev.parts
+	if parts == nil {
+		parts = []synthRequest{prev}
+	}
+	out := prev
+	out.parts = append(parts, next)
+	out.segment.Text = script.JoinText(prev.segment.Text, next.segment.Text)
+	out.reading = ""
+	if prev.reading != "" || next.reading != "" {
+		out.reading = script.JoinText(prev.text(), next.text())
+	}
+	return out
+}
+
+// partWeights は結合したリクエストの尺を元のセグメントに配分する割合を、読み上げる文字数の比で返します。
+func partWeights(req synthRequest) []float64 {
+	weights := make([]float64, len(req.parts))
+	var total int
+	for _, part := range req.parts {
+		total += countSpokenChars(part.text())
+	}
+	for i, part := range req.parts {
+		if total == 0 {
+			weights[i] = 1 / float64(len(req.parts))
+			continue
+		}
+		weights[i] = float64(countSpokenChars(part.text())) / float64(total)
+	}
+	return weights
+}
+
+// splitMergedSpans は結合したリクエストを元のセグメント単位のリクエストに戻し、その区間を読み上げる文字数の比で配分します。
+// 結合の境界の時刻は推定値ですが、字幕や A-B 区間のエントリは元のセグメントと同じ単位で出力できます。
+func splitMergedSpans(requests []synthRequest, spans []audio.Span) ([]synthRequest, []audio.Span) {
+	var outRequests []synthRequest
+	var outSpans []audio.Span
+	for i, req := range requests {
+		if req.parts == nil {
+			outRequests = append(outRequests, req)
+			outSpans = append(outSpans, spans[i])
+			continue
+		}
+		start, length := spans[i].Start, spans[i].End-spans[i].Start
+		for j, weight := range partWeights(req) {
+			end := start + length*weight
+			if j == len(req.parts)-1 {
+				end = spans[i].End
+			}
+			outRequests = append(outRequests, req.parts[j])
+			outSpans = append(outSpans, audio.Span{Start: start, End: end})
+			start = end
+		}
+	}
+	return outRequests, outSpans
+}
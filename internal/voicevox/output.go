@@ -0,0 +1,77 @@
+package voicevox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// MinOutputSampleRate と MaxOutputSampleRate は --output-sample-rate に指定できるサンプルレート (Hz) の範囲です。
+const (
+	MinOutputSampleRate = 8000
+	MaxOutputSampleRate = 192000
+)
+
+// resampleOutput は結合したWAVを rate Hz に変換します。元のサンプルレートと同じ場合はそのまま返します。
+func resampleOutput(ctx context.Context, combined []byte, rate int) ([]byte, error) {
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return nil, fmt.Errorf("サンプルレート変換のためのWAV解析に失敗しました: %w", err)
+	}
+	if w.Format.SampleRate == uint32(rate) {
+		return combined, nil
+	}
+	resampled, err := audio.Resample(w, uint32(rate))
+	if err != nil {
+		return nil, fmt.Errorf("サンプルレートの変換に失敗しました: %w", err)
+	}
+	slog.InfoContext(ctx, "出力のサンプルレートを変換しました。", "from", w.Format.SampleRate, "to", rate)
+	return resampled.Bytes(), nil
+}
+
+// normalizeSilence は結合した音声から maxSilence より長い無音を検出して maxSilence に詰め、詰めた音声と削除した区間を返します。
+func normalizeSilence(ctx context.Context, combined []byte, maxSilence time.Duration) ([]byte, []audio.Span, error) {
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return nil, nil, fmt.Errorf("無音の正規化のためのWAV解析に失敗しました: %w", err)
+	}
+	shortened, removed := audio.ShortenSilences(w, maxSilence.Seconds(), audio.DefaultSilenceLevelDB)
+	if len(removed) == 0 {
+		return combined, nil, nil
+	}
+	var seconds float64
+	for _, r := range removed {
+		seconds += r.Duration()
+	}
+	slog.InfoContext(ctx, "長すぎる無音を詰めました。", "silences", len(removed), "removed_sec", fmt.Sprintf("%.1f", seconds), "max_silence", maxSilence)
+	return shortened.Bytes(), removed, nil
+}
+
+// encodeOutput は出力ファイルの拡張子に応じて結合したWAVを圧縮形式にエンコードし、書き込むデータと MIME タイプを返します。
+// 拡張子が .wav または未知の場合は WAV のまま返します。bitrate が空の場合は形式ごとの既定値を使います。
+func encodeOutput(ctx context.Context, outputPath string, combined []byte, bitrate string) ([]byte, string, error) {
+	codec, ok := audio.CodecForPath(outputPath)
+	if !ok {
+		if ext := filepath.Ext(outputPath); !strings.EqualFold(ext, ".wav") {
+			slog.WarnContext(ctx, "未対応の拡張子のため WAV 形式で出力します。", "path", outputPath, "ext", ext)
+		}
+		return combined, "audio/wav", nil
+	}
+
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return nil, "", fmt.Errorf("エンコードのためのWAV解析に失敗しました: %w", err)
+	}
+	encoded, err := audio.Transcode(ctx, w, codec, bitrate)
+	if err != nil {
+		return nil, "", err
+	}
+	slog.InfoContext(ctx, "音声をエンコードしました。", "codec", codec.Name, "bitrate", cmp.Or(bitrate, codec.DefaultBitrate), "wav_bytes", len(combined), "encoded_bytes", len(encoded))
+	return encoded, codec.ContentType, nil
+}
@@ -0,0 +1,90 @@
+package voicevox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// prosodyRange は合成パラメータタグで指定できる値の範囲です。VOICEVOX エンジンの GUI で設定できる範囲に合わせています。
+type prosodyRange struct {
+	min, max float64
+}
+
+var (
+	speedRange      = prosodyRange{min: 0.5, max: 2.0}
+	pitchRange      = prosodyRange{min: -0.15, max: 0.15}
+	intonationRange = prosodyRange{min: 0, max: 2.0}
+)
+
+// SynthesisParams は audio_query の結果に上書きする合成パラメータです。nil のフィールドはエンジンの値をそのまま使います。
+type SynthesisParams struct {
+	SpeedScale      *float64
+	PitchScale      *float64
+	IntonationScale *float64
+	VolumeScale     *float64
+}
+
+// IsZero は上書きするパラメータが一つも無いかを返します。
+func (p SynthesisParams) IsZero() bool {
+	return p.SpeedScale == nil && p.PitchScale == nil && p.IntonationScale == nil && p.VolumeScale == nil
+}
+
+// Equal は2つの SynthesisParams が同じ値を上書きするかを返します。
+func (p SynthesisParams) Equal(o SynthesisParams) bool {
+	eq := func(a, b *float64) bool {
+		return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+	}
+	return eq(p.SpeedScale, o.SpeedScale) && eq(p.PitchScale, o.PitchScale) && eq(p.IntonationScale, o.IntonationScale) && eq(p.VolumeScale, o.VolumeScale)
+}
+
+// applyQueryParams は audio_query のJSONに合成パラメータを上書きします。
+// 未知のフィールドを失わないよう、クエリは汎用マップとして扱います。
+func applyQueryParams(query []byte, params SynthesisParams) ([]byte, error) {
+	if params.IsZero() {
+		return query, nil
+	}
+
+	var q map[string]any
+	if err := json.Unmarshal(query, &q); err != nil {
+		return nil, fmt.Errorf("audio_queryの解析に失敗しました: %w", err)
+	}
+	setIfPresent := func(key string, v *float64) {
+		if v != nil {
+			q[key] = *v
+		}
+	}
+	setIfPresent("speedScale", params.SpeedScale)
+	setIfPresent("pitchScale", params.PitchScale)
+	setIfPresent("intonationScale", params.IntonationScale)
+	setIfPresent("volumeScale", params.VolumeScale)
+
+	edited, err := json.Marshal(q)
+	if err != nil {
+		return nil, fmt.Errorf("audio_queryのエンコードに失敗しました: %w", err)
+	}
+	return edited, nil
+}
+
+// prosodyParams はセグメントの合成パラメータタグを SynthesisParams に変換します。
+// 範囲外の値は警告を出して範囲内に丸めます。
+func prosodyParams(ctx context.Context, seg script.Segment) SynthesisParams {
+	clamp := func(name string, v *float64, r prosodyRange) *float64 {
+		if v == nil {
+			return nil
+		}
+		clamped := max(r.min, min(r.max, *v))
+		if clamped != *v {
+			slog.WarnContext(ctx, "合成パラメータタグの値が範囲外のため丸めます。", "tag", name, "value", *v, "clamped", clamped, "line", seg.Line)
+		}
+		return &clamped
+	}
+	return SynthesisParams{
+		SpeedScale:      clamp(script.ProsodySpeed, seg.Prosody.Speed, speedRange),
+		PitchScale:      clamp(script.ProsodyPitch, seg.Prosody.Pitch, pitchRange),
+		IntonationScale: clamp(script.ProsodyIntonation, seg.Prosody.Intonation, intonationRange),
+	}
+}
@@ -0,0 +1,109 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// PartialMeta は合成の中断時に保存した部分WAVに含まれる範囲のメタ情報です (--save-partial)。
+type PartialMeta struct {
+	OutputPath string `json:"output_path"`
+	PartialWAV string `json:"partial_wav"`
+	// Segments は部分WAVに含まれる、先頭から連続して合成が完了したセグメントの数です。
+	Segments int `json:"segments"`
+	// TotalSegments はスクリプト全体のセグメント数です。
+	TotalSegments int `json:"total_segments"`
+	// Completed は合成が完了していたセグメントの総数です。並列合成のため、Segments より後ろのセグメントが完了している場合があります。
+	Completed int `json:"completed"`
+	// LastLine は部分WAVに含まれる最後のセグメントのスクリプト上の行番号です。
+	LastLine int `json:"last_line"`
+	// LastText は部分WAVに含まれる最後のセグメントの本文です。
+	LastText    string    `json:"last_text"`
+	DurationSec float64   `json:"duration_sec"`
+	Size        int       `json:"size"`
+	SHA256      string    `json:"sha256"`
+	Error       string    `json:"error"`
+	SavedAt     time.Time `json:"saved_at"`
+}
+
+// partialPaths は outputPath に対応する部分WAVとメタ情報のパスを返します (例: out.mp3 → out.partial.wav, out.partial.json)。
+func partialPaths(outputPath string) (wavPath, metaPath string) {
+	base := strings.TrimSuffix(outputPath, filepath.Ext(outputPath)) + ".partial"
+	return base + ".wav", base + ".json"
+}
+
+// savePartial は合成の中断時に、先頭から連続して合成が完了したセグメントを結合した部分WAVと、含まれる範囲のメタ情報を書き込みます。
+// 呼び出し元のキャンセルで中断した場合も書き込めるよう、キャンセルを引き継がない context で書き込みます。
+func (e *Engine) savePartial(ctx context.Context, outputPath string, requests []synthRequest, segmentWavs [][]byte, cause error) error {
+	n, completed := 0, 0
+	for i, data := range segmentWavs {
+		if data == nil {
+			continue
+		}
+		completed++
+		if n == i {
+			n++
+		}
+	}
+	if n == 0 {
+		slog.WarnContext(ctx, "先頭のセグメントの合成が完了していないため、部分WAVは保存しません。", "completed", completed, "total", len(requests))
+		return nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	total := len(requests)
+	requests, segmentWavs = requests[:n], segmentWavs[:n]
+	if err := applyEffects(ctx, requests, segmentWavs); err != nil {
+		return err
+	}
+	combined, err := combineWavData(segmentWavs, e.segmentGaps(requests), e.config.SegmentMarker)
+	if err != nil {
+		return fmt.Errorf("部分WAVの結合に失敗しました: %w", err)
+	}
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return fmt.Errorf("部分WAVの解析に失敗しました: %w", err)
+	}
+
+	wavPath, metaPath := partialPaths(outputPath)
+	sum := sha256.Sum256(combined)
+	last := requests[n-1].segment
+	meta := PartialMeta{
+		OutputPath:    outputPath,
+		PartialWAV:    wavPath,
+		Segments:      n,
+		TotalSegments: total,
+		Completed:     completed,
+		LastLine:      last.Line,
+		LastText:      last.Text,
+		DurationSec:   w.Duration(),
+		Size:          len(combined),
+		SHA256:        hex.EncodeToString(sum[:]),
+		Error:         cause.Error(),
+		SavedAt:       time.Now(),
+	}
+	body, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return fmt.Errorf("部分WAVのメタ情報のエンコードに失敗しました: %w", err)
+	}
+
+	if err := e.writer.Write(ctx, wavPath, bytes.NewReader(combined), "audio/wav"); err != nil {
+		return fmt.Errorf("部分WAVの書き込みに失敗しました (%s): %w", wavPath, err)
+	}
+	if err := e.writer.Write(ctx, metaPath, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("部分WAVのメタ情報の書き込みに失敗しました (%s): %w", metaPath, err)
+	}
+	slog.WarnContext(ctx, "合成が中断されたため、完成したセグメントまでの部分WAVを保存しました。",
+		"path", wavPath, "segments", n, "total", total, "last_line", last.Line, "meta", metaPath)
+	return nil
+}
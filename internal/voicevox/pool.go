@@ -0,0 +1,218 @@
+package voicevox
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"runtime/metrics"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// segmentMaxAttempts は1セグメントあたりの合成試行回数です。
+	segmentMaxAttempts = 3
+	// segmentRetryDelay は合成の再試行までの待機時間です。
+	segmentRetryDelay = 2 * time.Second
+	// memoryPressureRatio は使用中のヒープがメモリの上限のこの割合を超えた場合に、新しいリクエストの投入を控える閾値です。
+	memoryPressureRatio = 0.9
+	// memoryPressurePoll はメモリの使用量が下がるのを待つ間の確認間隔です。
+	memoryPressurePoll = 100 * time.Millisecond
+)
+
+// segmentResult はセグメント合成の結果です。
+type segmentResult struct {
+	index   int
+	wavData []byte
+	err     error
+}
+
+// synthPool は合成リクエストを同時実行数・秒間リクエスト数・ジョブの優先度の制限の下で並列に合成するワーカープールです。
+// 各リクエストには文字数に応じたタイムアウトを設け、再試行可能なエラーは segmentMaxAttempts 回まで再試行します。
+// スクリプト全体の合成 (Engine.synthesizeAll) と1文の合成 (Engine.SynthesizeText) で共有します。
+type synthPool struct {
+	synthesizer Synthesizer
+	// concurrency は同時に合成するリクエスト数の上限です。
+	concurrency int
+	// memoryLimit が 0 より大きい場合、合成した音声を保持するメモリの上限 (バイト) に収まるよう同時実行数を抑え、上限に近づいたら投入を待機します。
+	memoryLimit int64
+	// limiter は合成の開始を秒間リクエスト数で制限します。nil の場合は制限しません。
+	limiter *rateLimiter
+	// gate は他のプロセスのより優先度の高いジョブの実行中に合成の開始を待機させます。nil の場合は待機しません。
+	gate *PriorityGate
+	// timeoutBase と timeoutPerChar は1リクエストの合成のタイムアウトの基準値と、1文字あたりの加算分です。
+	timeoutBase    time.Duration
+	timeoutPerChar time.Duration
+	// progress が nil でない場合、run でリクエストの合成が1つ完了するたびに完了数と総数を通知します。
+	progress func(completed, total int)
+}
+
+// newSynthPool は config の同時実行数・秒間リクエスト数・優先度・タイムアウト・進捗の設定で synthesizer を呼び出す synthPool を生成します。
+// config の既定値は NewEngine で補完済みであることを前提とします。
+func newSynthPool(synthesizer Synthesizer, config EngineConfig) *synthPool {
+	return &synthPool{
+		synthesizer:    synthesizer,
+		concurrency:    config.Concurrency,
+		memoryLimit:    config.MaxMemory,
+		limiter:        newRateLimiter(config.RequestsPerSecond),
+		gate:           config.PriorityGate,
+		timeoutBase:    config.SegmentTimeoutBase,
+		timeoutPerChar: config.SegmentTimeoutPerChar,
+		progress:       config.Progress,
+	}
+}
+
+// run は order の順に requests を投入して並列に合成し、完了した順に結果を handle に渡します。
+// handle が false を返すと、未投入のリクエストを投入せずに合成中のリクエストをキャンセルし、以降は進捗を通知しません。
+// 中断後も、合成中だったリクエストとキャンセルしたリクエスト (エラーは ctx のエラー) を含むすべての結果を handle に渡してから戻ります。
+// handle と progress の呼び出しは run を呼び出したゴルーチンから順に行います。
+func (p *synthPool) run(ctx context.Context, requests []synthRequest, order []int, handle func(result segmentResult) bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := p.concurrencyFor(requests, order)
+	if concurrency < p.concurrency {
+		slog.InfoContext(ctx, "メモリの上限に収まるよう、合成の同時実行数を抑えます。", "concurrency", concurrency, "max_memory", p.memoryLimit)
+	}
+	semaphore := make(chan struct{}, concurrency)
+	resultsChan := make(chan segmentResult, len(order))
+
+	go func() {
+		for _, index := range order {
+			p.waitForMemory(ctx, func() int { return len(semaphore) })
+			select {
+			case semaphore <- struct{}{}:
+			case <-ctx.Done():
+				resultsChan <- segmentResult{index: index, err: ctx.Err()}
+				continue
+			}
+			go func(index int) {
+				defer func() { <-semaphore }()
+				wavData, err := p.synthesize(ctx, requests[index])
+				resultsChan <- segmentResult{index: index, wavData: wavData, err: err}
+			}(index)
+		}
+	}()
+
+	stopped := false
+	for completed := 1; completed <= len(order); completed++ {
+		if !handle(<-resultsChan) && !stopped {
+			stopped = true
+			cancel()
+		}
+		if p.progress != nil && !stopped {
+			p.progress(completed, len(order))
+		}
+	}
+}
+
+// concurrencyFor は memoryLimit の下で requests を合成する同時実行数を返します。
+// 合成済みのセグメントを保持したうえで、最も長いセグメントの応答を同時に受け取っても上限に収まる数に抑えます。
+// 尺は文字数からの推定のため、実際の使用量は waitForMemory で確かめます。
+func (p *synthPool) concurrencyFor(requests []synthRequest, order []int) int {
+	if p.memoryLimit <= 0 {
+		return p.concurrency
+	}
+	var retained, largest int64
+	for _, index := range order {
+		req := requests[index]
+		n := int64(estimatedSeconds(countSpokenChars(req.segment.Text), req.params) * engineBytesPerSecond)
+		retained += n
+		largest = max(largest, n)
+	}
+	if largest == 0 {
+		return p.concurrency
+	}
+	// 応答の受信中は、読み込み途中の本文と WAV データの2つ分を保持する
+	return max(1, min(p.concurrency, int((p.memoryLimit-retained)/(2*largest))))
+}
+
+// waitForMemory は使用中のヒープが memoryLimit の memoryPressureRatio を超えている間、新しいリクエストの投入を待機します。
+// 合成中のリクエスト (inFlight) が無くなった場合は、合成が進まなくなるのを避けるため待機をやめます。
+func (p *synthPool) waitForMemory(ctx context.Context, inFlight func() int) {
+	if p.memoryLimit <= 0 {
+		return
+	}
+	threshold := uint64(float64(p.memoryLimit) * memoryPressureRatio)
+	for waited := false; inFlight() > 0; waited = true {
+		heap := heapInUse()
+		if heap <= threshold {
+			return
+		}
+		if !waited {
+			slog.InfoContext(ctx, "メモリの使用量が上限に近いため、合成中のセグメントの完了を待ってから次を投入します。", "heap_bytes", heap, "max_memory", p.memoryLimit)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(memoryPressurePoll):
+		}
+	}
+}
+
+// heapInUse は Go ランタイムのヒープ上で使用中のオブジェクトのバイト数を返します。
+var heapInUse = func() uint64 {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample[0].Value.Uint64()
+}
+
+// synthesize は1リクエストの audio_query と synthesis を実行します。再試行可能なエラーは一定回数まで再試行します。
+// タイムアウトはリクエストの文字数から算出します (timeoutFor)。
+func (p *synthPool) synthesize(ctx context.Context, req synthRequest) ([]byte, error) {
+	timeout := p.timeoutFor(req.text())
+	var lastErr error
+	for attempt := 1; attempt <= segmentMaxAttempts; attempt++ {
+		wavData, err := p.synthesizeOnce(ctx, req, timeout)
+		if err == nil {
+			return wavData, nil
+		}
+		lastErr = err
+		if !isRetryable(err) || attempt == segmentMaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		slog.WarnContext(ctx, "セグメントの合成に失敗したため再試行します。", "line", req.segment.Line, "attempt", attempt, "timeout", timeout, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, errors.Join(lastErr, ctx.Err())
+		case <-time.After(segmentRetryDelay * time.Duration(attempt)):
+		}
+	}
+	return nil, lastErr
+}
+
+// timeoutFor はテキストの文字数 (ルーン数) に応じた1リクエストの合成のタイムアウトを返します。
+// 短文での無駄な待ちと、長文での誤タイムアウトを避けるため、基準値に文字数に比例した時間を加算します。
+func (p *synthPool) timeoutFor(text string) time.Duration {
+	return p.timeoutBase + time.Duration(utf8.RuneCountInString(text))*p.timeoutPerChar
+}
+
+// synthesizeOnce はタイムアウト付きで1回だけ合成を実行します。
+// 優先度の高いジョブの実行中や秒間リクエスト数の制限がある場合は、送出可能になるまで待機します。
+func (p *synthPool) synthesizeOnce(ctx context.Context, req synthRequest, timeout time.Duration) ([]byte, error) {
+	if err := p.gate.wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.limiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	return p.synthesizer.Synthesize(ctx, req.text(), req.styleID, req.params)
+}
+
+// isRetryable はエラーが再試行に値するかを判定します。
+// エンジンが 4xx を返した場合（テキストが不正など）は再試行しても結果が変わらないため対象外です。
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+	return !errors.Is(err, context.Canceled)
+}
@@ -0,0 +1,172 @@
+package voicevox
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// maxPreciseSplitProbes は1か所の分割位置を決めるために試す候補の数の上限です。候補ごとに2回の問い合わせが発生します。
+const maxPreciseSplitProbes = 8
+
+// AccentPhrase は VOICEVOX エンジンが解析したアクセント句です。分割位置の検証に必要なフィールドのみを持ちます。
+type AccentPhrase struct {
+	Moras     []Mora `json:"moras"`
+	PauseMora *Mora  `json:"pause_mora"`
+}
+
+// Mora はアクセント句を構成するモーラ (カナ1拍) です。
+type Mora struct {
+	Text string `json:"text"`
+}
+
+// AccentPhraseQuerier はテキストのアクセント句を問い合わせられる合成ステージです。*Client が満たします。
+type AccentPhraseQuerier interface {
+	AccentPhrases(ctx context.Context, text string, styleID int) ([]AccentPhrase, error)
+}
+
+// preciseSplitter は長いテキストを、VOICEVOX のアクセント句の境界に沿って分割します (--precise-split)。
+type preciseSplitter struct {
+	ctx     context.Context
+	querier AccentPhraseQuerier
+	styleID int
+	limiter *rateLimiter
+	// failed は問い合わせに失敗したかどうかです。失敗以降は問い合わせずに句読点・文字数で分割します。
+	failed bool
+}
+
+// splitFunc は --precise-split が有効な場合に、アクセント句の境界で分割する関数を返します。
+// 無効な場合や、エンジンにアクセント句を問い合わせられない場合は nil (句読点での分割) を返します。
+func (e *Engine) splitFunc(ctx context.Context) script.SplitFunc {
+	if !e.config.PreciseSplit {
+		return nil
+	}
+	querier, ok := e.synthesizer.(AccentPhraseQuerier)
+	if !ok {
+		slog.WarnContext(ctx, "アクセント句を問い合わせられないため、句読点で分割します。")
+		return nil
+	}
+	speakerData, err := e.loadSpeakerData(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "話者一覧を取得できないため、句読点で分割します。", "error", err)
+		return nil
+	}
+	styleID, ok := speakerData.DefaultStyleIDs[e.fallbackSpeakerTag()]
+	if !ok {
+		slog.WarnContext(ctx, "アクセント句の問い合わせに使うスタイルが見つからないため、句読点で分割します。", "speaker", e.fallbackSpeakerTag())
+		return nil
+	}
+	s := &preciseSplitter{ctx: ctx, querier: querier, styleID: styleID, limiter: e.pool.limiter}
+	return s.split
+}
+
+// split は maxLen ルーンを超えないよう、先頭から順に分割位置を決めてテキストを分割します。
+func (s *preciseSplitter) split(text string, maxLen int) []string {
+	var parts []string
+	rest := []rune(strings.TrimSpace(text))
+	for len(rest) > maxLen {
+		cut := s.findCut(rest, maxLen)
+		if part := strings.TrimSpace(string(rest[:cut])); part != "" {
+			parts = append(parts, part)
+		}
+		rest = []rune(strings.TrimSpace(string(rest[cut:])))
+	}
+	if len(rest) > 0 {
+		parts = append(parts, string(rest))
+	}
+	return parts
+}
+
+// findCut は先頭から maxLen ルーン以内の分割位置 (ルーン数) を返します。
+// 文末記号の直後があれば問い合わせずにそこで分割し、無ければ読点・文節らしき位置の候補のうち、
+// アクセント句の境界と確認できた最も後ろの位置で分割します。確認できない場合は読点、最後に文字数で分割します。
+func (s *preciseSplitter) findCut(runes []rune, maxLen int) int {
+	for i := maxLen; i > 0; i-- {
+		if strings.ContainsRune("。！？!?", runes[i-1]) {
+			return i
+		}
+	}
+
+	candidates := phraseCandidates(runes, maxLen)
+	if !s.failed && len(candidates) > 0 {
+		whole, err := s.query(string(runes))
+		for _, cut := range candidates[:min(len(candidates), maxPreciseSplitProbes)] {
+			if err != nil {
+				break
+			}
+			var ok bool
+			ok, err = s.isPhraseBoundary(whole, runes, cut)
+			if ok {
+				return cut
+			}
+		}
+		if err != nil {
+			s.failed = true
+			slog.WarnContext(s.ctx, "アクセント句の問い合わせに失敗したため、以降は句読点で分割します。", "error", err)
+		}
+	}
+
+	for i := maxLen; i > 0; i-- {
+		if strings.ContainsRune("、，,", runes[i-1]) {
+			return i
+		}
+	}
+	return maxLen
+}
+
+// isPhraseBoundary は cut の位置で分割しても、前後のアクセント句を連結したものが分割前と一致するか (アクセント句を途中で切らないか) を返します。
+func (s *preciseSplitter) isPhraseBoundary(whole []AccentPhrase, runes []rune, cut int) (bool, error) {
+	left, err := s.query(string(runes[:cut]))
+	if err != nil {
+		return false, err
+	}
+	right, err := s.query(string(runes[cut:]))
+	if err != nil {
+		return false, err
+	}
+	return slices.Equal(moraTexts(whole), append(moraTexts(left), moraTexts(right)...)), nil
+}
+
+// query は秒間リクエスト数の制限に従ってアクセント句を問い合わせます。
+func (s *preciseSplitter) query(text string) ([]AccentPhrase, error) {
+	if err := s.limiter.Wait(s.ctx); err != nil {
+		return nil, err
+	}
+	return s.querier.AccentPhrases(s.ctx, text, s.styleID)
+}
+
+// phraseCandidates は maxLen ルーン以内の分割位置の候補を、後ろの位置から順に返します。
+// 読点の直後を優先し、次にひらがな (助詞・語尾) から漢字・カタカナ・英数字に変わる文節らしき位置を候補にします。
+func phraseCandidates(runes []rune, maxLen int) []int {
+	var commas, bunsetsu []int
+	for i := min(maxLen, len(runes)-1); i > maxLen/2; i-- {
+		prev, next := runes[i-1], runes[i]
+		switch {
+		case strings.ContainsRune("、，,", prev):
+			commas = append(commas, i)
+		case unicode.Is(unicode.Hiragana, prev) && !unicode.Is(unicode.Hiragana, next) && !unicode.IsPunct(next):
+			bunsetsu = append(bunsetsu, i)
+		}
+	}
+	return append(commas, bunsetsu...)
+}
+
+// moraTexts はアクセント句をアクセント句ごとのモーラの読み (句末のポーズを区別) の列に変換します。
+func moraTexts(phrases []AccentPhrase) []string {
+	texts := make([]string, 0, len(phrases))
+	for _, p := range phrases {
+		var sb strings.Builder
+		for _, m := range p.Moras {
+			sb.WriteString(m.Text)
+		}
+		if p.PauseMora != nil {
+			sb.WriteString("、")
+		}
+		texts = append(texts, sb.String())
+	}
+	return texts
+}
@@ -0,0 +1,173 @@
+package voicevox
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Priority は同じエンジンを共有するジョブ間での合成の優先度です。
+type Priority int
+
+// Priority* はジョブの優先度です。優先度の低いジョブは、より高い優先度のジョブの実行中はセグメントの合成を待機します。
+const (
+	PriorityLow Priority = iota
+	PriorityNormal
+	PriorityHigh
+)
+
+// PriorityNames は --priority で指定できる優先度の名前です。
+var PriorityNames = []string{"low", "normal", "high"}
+
+const (
+	// priorityDirName はユーザーのキャッシュディレクトリに置く、実行中のジョブのマーカーファイルのディレクトリです。
+	priorityDirName = "prototypus/engine-priority"
+	// priorityHeartbeat は実行中のジョブがマーカーファイルの更新時刻を更新する間隔です。
+	priorityHeartbeat = 5 * time.Second
+	// priorityStaleAfter はマーカーファイルがこの時間以上更新されていない場合に、異常終了したジョブの残骸とみなす時間です。
+	priorityStaleAfter = 30 * time.Second
+	// priorityPollInterval は優先度の高いジョブの終了を確認する間隔です。
+	priorityPollInterval = 500 * time.Millisecond
+)
+
+// ParsePriority は優先度の名前を Priority に変換します。空文字は PriorityNormal とみなします。
+func ParsePriority(name string) (Priority, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "low":
+		return PriorityLow, nil
+	case "", "normal":
+		return PriorityNormal, nil
+	case "high":
+		return PriorityHigh, nil
+	}
+	return PriorityNormal, fmt.Errorf("優先度には %s のいずれかを指定してください: '%s'", strings.Join(PriorityNames, ", "), name)
+}
+
+// String は優先度の名前を返します。
+func (p Priority) String() string {
+	return PriorityNames[p]
+}
+
+// PriorityGate は同じエンジンを共有するプロセス間で、実行中のジョブのマーカーファイルを介して合成の優先度を調停します。
+// 長時間のバッチの裏で、対話的な確認用のジョブを優先して合成させるために使います。
+type PriorityGate struct {
+	dir      string
+	priority Priority
+
+	mu     sync.Mutex
+	marker string
+}
+
+// NewPriorityGate は dir をマーカーファイルのディレクトリとする PriorityGate を生成します。
+func NewPriorityGate(dir string, priority Priority) *PriorityGate {
+	return &PriorityGate{dir: dir, priority: priority}
+}
+
+// DefaultPriorityDir はエンジンURLごとに分けた、ユーザーのキャッシュディレクトリ上のマーカーファイルのディレクトリを返します。
+func DefaultPriorityDir(apiURL string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("キャッシュディレクトリを特定できません: %w", err)
+	}
+	sum := sha256.Sum256([]byte(strings.TrimRight(apiURL, "/")))
+	return filepath.Join(dir, priorityDirName, hex.EncodeToString(sum[:])[:12]), nil
+}
+
+// enter は実行中のジョブとしてマーカーファイルを作成し、ジョブの終了時に呼ぶ関数を返します。
+// 最も低い優先度のジョブは他のジョブを待たせないため、マーカーファイルを作成しません。
+// マーカーファイルの作成の失敗は合成を妨げないよう、ログ出力のみに留めます。
+func (g *PriorityGate) enter(ctx context.Context) (leave func()) {
+	if g == nil || g.priority == PriorityLow {
+		return func() {}
+	}
+	marker := filepath.Join(g.dir, fmt.Sprintf("%d-%d.%s", os.Getpid(), time.Now().UnixNano(), g.priority))
+	err := os.MkdirAll(g.dir, 0o755)
+	if err == nil {
+		err = os.WriteFile(marker, nil, 0o644)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "優先度のマーカーファイルを作成できませんでした。", "path", marker, "error", err)
+		return func() {}
+	}
+	g.mu.Lock()
+	g.marker = marker
+	g.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(priorityHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				_ = os.Chtimes(marker, now, now)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		g.mu.Lock()
+		g.marker = ""
+		g.mu.Unlock()
+		if err := os.Remove(marker); err != nil && !os.IsNotExist(err) {
+			slog.WarnContext(ctx, "優先度のマーカーファイルを削除できませんでした。", "path", marker, "error", err)
+		}
+	}
+}
+
+// wait は自分より高い優先度のジョブが実行中の間、待機します。
+func (g *PriorityGate) wait(ctx context.Context) error {
+	if g == nil || g.priority == PriorityHigh {
+		return nil
+	}
+	logged := false
+	for g.higherRunning() {
+		if !logged {
+			slog.InfoContext(ctx, "優先度の高いジョブの実行中のため、合成を待機します。", "priority", g.priority.String())
+			logged = true
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(priorityPollInterval):
+		}
+	}
+	return nil
+}
+
+// higherRunning は自分より高い優先度のジョブのマーカーファイルが、更新が途絶えずに存在するかを返します。
+func (g *PriorityGate) higherRunning() bool {
+	entries, err := os.ReadDir(g.dir)
+	if err != nil {
+		return false
+	}
+	g.mu.Lock()
+	own := filepath.Base(g.marker)
+	g.mu.Unlock()
+	for _, entry := range entries {
+		name := entry.Name()
+		ext := strings.TrimPrefix(filepath.Ext(name), ".")
+		if name == own || ext == "" {
+			continue
+		}
+		p, err := ParsePriority(ext)
+		if err != nil || p <= g.priority {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) > priorityStaleAfter {
+			continue
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,54 @@
+package voicevox
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter は秒間リクエスト数を制限するトークンバケットです。
+// バケットの容量を1とすることで、バーストを許さず一定間隔でリクエストを送り出します。
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+// newRateLimiter は秒間 rps 回までリクエストを許可する rateLimiter を生成します。rps が 0 以下の場合は nil を返します。
+func newRateLimiter(rps float64) *rateLimiter {
+	if rps <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rps)}
+}
+
+// Wait はトークンを取得できるまで待機します。nil の rateLimiter は待機しません。
+func (l *rateLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	// 待機中の呼び出し同士が同じ時刻を取り合わないよう、先に送出時刻を予約してから待機する
+	l.mu.Lock()
+	now := time.Now()
+	at := l.next
+	if at.Before(now) {
+		at = now
+	}
+	l.next = at.Add(l.interval)
+	l.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,178 @@
+package voicevox
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// engineSampleRate と engineBytesPerSecond は合成前の見積もりに使う、VOICEVOX の既定の出力 (24kHz・16bit・モノラル) のサンプルレートと1秒あたりのバイト数です。
+const (
+	engineSampleRate     = 24000
+	engineBytesPerSecond = engineSampleRate * 2
+)
+
+// byteSizeUnits はサイズの指定に使える単位と倍率です。いずれも 1024 倍ごとの単位として扱います。
+var byteSizeUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"GIB", 1 << 30}, {"GB", 1 << 30}, {"G", 1 << 30},
+	{"MIB", 1 << 20}, {"MB", 1 << 20}, {"M", 1 << 20},
+	{"KIB", 1 << 10}, {"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize は "512MiB" や "2G"、"1048576" のようなサイズの指定をバイト数に変換します。空の場合は 0 (制限なし) を返します。
+func ParseByteSize(s string) (int64, error) {
+	num := strings.ToUpper(strings.TrimSpace(s))
+	if num == "" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(num, u.suffix) {
+			multiplier, num = u.multiplier, strings.TrimSpace(strings.TrimSuffix(num, u.suffix))
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("サイズの形式が不正です (例: 512MiB, 2G): '%s'", s)
+	}
+	return int64(v * float64(multiplier)), nil
+}
+
+// estimateAudioSeconds は文字数と話速の指定から、合成後の音声全体の尺 (セグメント間の無音を含む) を推定します。
+func (e *Engine) estimateAudioSeconds(requests []synthRequest) float64 {
+	var seconds float64
+	for _, req := range requests {
+		seconds += estimatedSeconds(countSpokenChars(req.segment.Text), req.params)
+	}
+	for _, gap := range e.segmentGaps(requests) {
+		seconds += gap.Seconds()
+	}
+	return seconds
+}
+
+// BufferingWriter は書き込む内容全体をメモリ上に読み込む AudioWriter のデコレータが実装するインターフェースです。
+// 実装していない AudioWriter は、ストリーミングで渡した内容を読み込みながら書き込むものとみなします。
+type BufferingWriter interface {
+	// BufferingOption は path への書き込みで内容全体を読み込む場合に、その原因となるオプション名を返します。読み込まない場合は空文字を返します。
+	BufferingOption(path string) string
+}
+
+// WriterBufferingOption は w が BufferingWriter を実装している場合に、path への書き込みで内容全体を読み込む原因となるオプション名を返します。
+// デコレータが内側の AudioWriter を確かめる際にも使います。
+func WriterBufferingOption(w AudioWriter, path string) string {
+	if b, ok := w.(BufferingWriter); ok {
+		return b.BufferingOption(path)
+	}
+	return ""
+}
+
+// streamBlocker は結合した音声をストリーミングで書き込めない理由となるオプションを返します。書き込める場合は空文字を返します。
+// 圧縮形式へのエンコードやサンプルレートの変換、チャプター検出、ラウドネスの照合などは結合した音声全体をメモリ上に必要とします。
+// 書き込み先の AudioWriter が内容全体を読み込む場合 (--qc-report など) も、ストリーミングではメモリを抑えられません。
+func (e *Engine) streamBlocker(outputPath string) string {
+	switch _, encoded := audio.CodecForPath(outputPath); {
+	case encoded:
+		return "圧縮形式 (.mp3 など) での出力"
+	case e.config.OutputSampleRate > 0:
+		return "--output-sample-rate"
+	case e.config.AutoChapterSilence > 0:
+		return "--auto-chapter-silence"
+	case e.config.NormalizeSilence > 0:
+		return "--normalize-silence"
+	case e.config.StatsPath != "":
+		return "--stats-output"
+	case e.config.EmbedScript:
+		return "--embed-script"
+	case e.config.LoudnessGate.Action != "":
+		return "--loudness-gate"
+	}
+	return WriterBufferingOption(e.writer, outputPath)
+}
+
+// planOutput は合成したセグメントの PCM が pcmBytes バイトの場合に、MaxMemory の上限内で出力できるかを判定します。
+// 結合した音声をメモリ上に作ると上限を超える場合は、ストリーミングでの書き込みに切り替えるため true を返します。
+// ストリーミングでも上限を超える場合や、ストリーミングで書き込めないオプションを併用している場合はエラーを返します。
+func (e *Engine) planOutput(outputPath string, pcmBytes int64) (bool, error) {
+	limit := e.config.MaxMemory
+	if limit <= 0 {
+		return false, nil
+	}
+
+	// 合成したセグメントに加えて、結合した音声と、エンコード・サンプルレート変換後の音声を同時に保持する
+	buffered := 2 * pcmBytes
+	if _, encoded := audio.CodecForPath(outputPath); encoded {
+		buffered += pcmBytes
+	}
+	if e.config.OutputSampleRate > 0 {
+		buffered += pcmBytes * int64(e.config.OutputSampleRate) / engineSampleRate
+	}
+	if buffered <= limit {
+		return false, nil
+	}
+
+	if pcmBytes > limit {
+		return false, fmt.Errorf("合成した音声 (約 %s) だけでメモリの上限 (%s) を超えます。スクリプトを分割するか --max-memory を見直してください", formatBytes(pcmBytes), formatBytes(limit))
+	}
+	if blocker := e.streamBlocker(outputPath); blocker != "" {
+		return false, fmt.Errorf("結合した音声をメモリ上に作ると約 %s になり、メモリの上限 (%s) を超えます。%s は結合した音声全体を必要とするため、ストリーミングでの書き込みに切り替えられません", formatBytes(buffered), formatBytes(limit), blocker)
+	}
+	return true, nil
+}
+
+// checkResources は合成を始める前に、文字数から推定した音声の大きさが MaxMemory と MaxTempDisk の上限に収まるかを検査します。
+// 合成が終わってから上限を超えて失敗しないよう、明らかに収まらない場合は早期にエラーを返します。
+func (e *Engine) checkResources(ctx context.Context, outputPath string, requests []synthRequest) error {
+	if e.config.MaxMemory <= 0 && e.config.MaxTempDisk <= 0 {
+		return nil
+	}
+	seconds := e.estimateAudioSeconds(requests)
+	pcmBytes := int64(seconds * engineBytesPerSecond)
+	stream, err := e.planOutput(outputPath, pcmBytes)
+	if err != nil {
+		return fmt.Errorf("推定した音声の長さ (約 %.0f 秒) では上限を超えます: %w", seconds, err)
+	}
+	if stream {
+		slog.InfoContext(ctx, "推定した音声の大きさがメモリの上限に近いため、結合した音声をストリーミングで書き込む見込みです。", "estimated_bytes", pcmBytes, "max_memory", e.config.MaxMemory)
+	}
+	return e.checkTempDisk(outputPath, seconds)
+}
+
+// checkTempDisk は圧縮形式へのエンコードで一時ファイルに書き込む大きさが MaxTempDisk の上限に収まるかを検査します。
+// 一時ファイルの大きさは尺とビットレートから見積もります。
+func (e *Engine) checkTempDisk(outputPath string, seconds float64) error {
+	codec, encoded := audio.CodecForPath(outputPath)
+	if e.config.MaxTempDisk <= 0 || !encoded {
+		return nil
+	}
+	bitrate, err := audio.ParseBitrate(cmp.Or(e.config.AudioBitrate, codec.DefaultBitrate))
+	if err != nil {
+		return err
+	}
+	if need := int64(seconds * float64(bitrate) / 8); need > e.config.MaxTempDisk {
+		return fmt.Errorf("%s へのエンコードに使う一時ファイル (約 %s) が一時ディスクの上限 (%s) を超えます", codec.Name, formatBytes(need), formatBytes(e.config.MaxTempDisk))
+	}
+	return nil
+}
+
+// formatBytes はバイト数を "12.3MiB" のような表示用の文字列に変換します。
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1fGiB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
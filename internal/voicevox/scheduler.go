@@ -0,0 +1,77 @@
+package voicevox
+
+import (
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// estimatedSecondsPerChar は日本語の読み上げ1文字あたりの概算秒数です。
+const estimatedSecondsPerChar = 0.15
+
+// estimateDuration はテキストの文字数から合成後の再生時間を概算します。
+func estimateDuration(text string) time.Duration {
+	return time.Duration(float64(utf8.RuneCountInString(text)) * estimatedSecondsPerChar * float64(time.Second))
+}
+
+// styleGroup はスケジューリングのためにまとめた同一スタイルIDのリクエスト群です。
+type styleGroup struct {
+	styleID   int
+	indexes   []int
+	estimated time.Duration
+}
+
+// scheduleByStyle は同一スタイルIDのリクエストをまとめて投入する順序を返します。
+// エンジンのモデル切り替えを最小化するため、スタイルごとにグループ化し、推定合計尺の長いグループから投入します。
+// 結果はインデックスで並べ直すため、グループ内では元の順序を保ちます。
+func scheduleByStyle(requests []synthRequest) []int {
+	var groups []*styleGroup
+	byStyle := make(map[int]*styleGroup)
+	for i, req := range requests {
+		g, ok := byStyle[req.styleID]
+		if !ok {
+			g = &styleGroup{styleID: req.styleID}
+			byStyle[req.styleID] = g
+			groups = append(groups, g)
+		}
+		g.indexes = append(g.indexes, i)
+		g.estimated += estimateDuration(req.segment.Text)
+	}
+
+	slices.SortStableFunc(groups, func(a, b *styleGroup) int {
+		switch {
+		case a.estimated > b.estimated:
+			return -1
+		case a.estimated < b.estimated:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	order := make([]int, 0, len(requests))
+	for _, g := range groups {
+		order = append(order, g.indexes...)
+	}
+	return order
+}
+
+// sequentialOrder はスクリプト順の投入順序を返します。
+func sequentialOrder(n int) []int {
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	return order
+}
+
+// countStyleSwitches は投入順序におけるスタイルIDの切り替え回数を数えます。
+func countStyleSwitches(requests []synthRequest, order []int) int {
+	switches := 0
+	for i := 1; i < len(order); i++ {
+		if requests[order[i]].styleID != requests[order[i-1]].styleID {
+			switches++
+		}
+	}
+	return switches
+}
@@ -0,0 +1,83 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// EstimateStats は合成を行わずに、スクリプトのメタデータのレポートを作成します。
+// 各セグメントの再生時間は文字数と話速タグから概算するため、セグメント間の無音は含みません。
+func EstimateStats(ctx context.Context, scriptContent string) script.ScriptStats {
+	scriptContent, _ = NormalizeSpeakerAliases(scriptContent)
+	segments := script.Parse(scriptContent)
+	durations := make([]float64, len(segments))
+	for i, seg := range segments {
+		durations[i] = estimatedSeconds(countSpokenChars(seg.Text), prosodyParams(ctx, seg))
+	}
+	return script.Summarize(segments, durations, script.DurationEstimated)
+}
+
+// measuredStats は合成したセグメントの PCM 長から、スクリプトのメタデータのレポートを作成します。
+// 全体の再生時間は、セグメント間の無音や区切りマーカー音を含む結合後の音声の長さです。
+func measuredStats(requests []synthRequest, segmentWavs [][]byte, combined []byte) (script.ScriptStats, error) {
+	segments := make([]script.Segment, 0, len(requests))
+	durations := make([]float64, 0, len(requests))
+	for i, req := range requests {
+		w, err := audio.ParseWAV(segmentWavs[i])
+		if err != nil {
+			return script.ScriptStats{}, fmt.Errorf("セグメント %d のWAV解析に失敗しました: %w", i+1, err)
+		}
+		if req.parts == nil {
+			segments = append(segments, req.segment)
+			durations = append(durations, w.Duration())
+			continue
+		}
+		// 結合したセグメントは元のセグメントに戻し、尺を文字数の比で配分する
+		for j, weight := range partWeights(req) {
+			segments = append(segments, req.parts[j].segment)
+			durations = append(durations, w.Duration()*weight)
+		}
+	}
+	stats := script.Summarize(segments, durations, script.DurationMeasured)
+
+	w, err := audio.ParseWAV(combined)
+	if err != nil {
+		return script.ScriptStats{}, fmt.Errorf("結合したWAVの解析に失敗しました: %w", err)
+	}
+	stats.DurationSec = w.Duration()
+	return stats, nil
+}
+
+// writeScriptStats は合成結果から算出したスクリプトのメタデータのレポートを JSON で path に書き込みます。
+func (e *Engine) writeScriptStats(ctx context.Context, path string, requests []synthRequest, segmentWavs [][]byte, combined []byte) error {
+	stats, err := measuredStats(requests, segmentWavs, combined)
+	if err != nil {
+		return err
+	}
+	return WriteStats(ctx, e.writer, path, stats)
+}
+
+// WriteStats はスクリプトのメタデータのレポートをログに出力し、JSON で path に書き込みます。
+func WriteStats(ctx context.Context, w AudioWriter, path string, stats script.ScriptStats) error {
+	for _, s := range stats.Speakers {
+		slog.InfoContext(ctx, "話者別のスクリプト統計", "speaker", s.Speaker, "utterances", s.Utterances, "segments", s.Segments,
+			"chars", s.Chars, "duration_sec", fmt.Sprintf("%.1f", s.DurationSec))
+	}
+
+	body, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("スクリプト統計のエンコードに失敗しました: %w", err)
+	}
+	if err := w.Write(ctx, path, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("スクリプト統計の書き込みに失敗しました (%s): %w", path, err)
+	}
+	slog.InfoContext(ctx, "スクリプト統計を出力しました。", "path", path, "duration_source", stats.DurationSource,
+		"segments", stats.Segments, "chars", stats.Chars, "duration_sec", fmt.Sprintf("%.1f", stats.DurationSec))
+	return nil
+}
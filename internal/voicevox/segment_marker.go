@@ -0,0 +1,42 @@
+package voicevox
+
+import (
+	"fmt"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+const (
+	// DefaultSegmentMarkerFrequency は区切りマーカー音の既定の周波数 (Hz) です。
+	// VOICEVOX の出力 (24kHz) のナイキスト周波数未満で、発話の帯域から離れた高い周波数にしています。
+	DefaultSegmentMarkerFrequency = 10000
+	// DefaultSegmentMarkerDuration は区切りマーカー音の既定の長さです。
+	DefaultSegmentMarkerDuration = 20 * time.Millisecond
+	// segmentMarkerAmplitude は区切りマーカー音の振幅 (-20dBFS) です。聞き取りにくく、かつ後段ツールが検出できる音量にしています。
+	segmentMarkerAmplitude = 0.1
+)
+
+// SegmentMarker は後段ツールがセグメント境界を検出するために、境界へ挿入する短い正弦波トーンです。
+type SegmentMarker struct {
+	// Frequency はトーンの周波数 (Hz) です。
+	Frequency float64
+	// Duration はトーンの長さです。0 の場合はマーカー音を挿入しません。
+	Duration time.Duration
+}
+
+// validate はマーカー音が f のフォーマットで表現できるかを検証します。
+func (m SegmentMarker) validate(f audio.Format) error {
+	if m.Duration <= 0 {
+		return nil
+	}
+	if nyquist := float64(f.SampleRate) / 2; m.Frequency <= 0 || m.Frequency >= nyquist {
+		return fmt.Errorf("区切りマーカー音の周波数は 0Hz より大きく、サンプルレート %dHz の半分 (%.0fHz) 未満にしてください: %.0fHz", f.SampleRate, nyquist, m.Frequency)
+	}
+	return nil
+}
+
+// pcm は f のフォーマットのマーカー音の PCM を返します。マーカー音が無効な場合は nil を返します。
+func (m SegmentMarker) pcm(f audio.Format) []byte {
+	return audio.TonePCM(f, m.Frequency, m.Duration, segmentMarkerAmplitude)
+}
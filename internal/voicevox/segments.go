@@ -0,0 +1,78 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// segmentFileNameReplacer はファイル名に使えない文字や空白を "_" に置き換えます。
+var segmentFileNameReplacer = strings.NewReplacer(
+	"/", "_", `\`, "_", ":", "_", "*", "_", "?", "_", `"`, "_", "<", "_", ">", "_", "|", "_", " ", "_", "　", "_",
+)
+
+// segmentFileName はセグメントの個別WAVのファイル名を返します (例: 01_ずんだもん.wav)。
+// 番号はスクリプト順の1始まりで、セグメント数に応じて2桁以上にゼロ埋めします。
+func segmentFileName(index, total int, speaker string) string {
+	width := max(2, len(strconv.Itoa(total)))
+	return fmt.Sprintf("%0*d_%s.wav", width, index+1, segmentFileNameReplacer.Replace(speaker))
+}
+
+// WriterWrapper は AudioWriter を包むデコレータが実装するインターフェースです。
+type WriterWrapper interface {
+	// Unwrap は包んでいる内側の AudioWriter を返します。
+	Unwrap() AudioWriter
+}
+
+// segmentWriter はセグメントの個別WAVの書き込み先として、デコレータを外した最も内側の AudioWriter を返します。
+// 品質検査 (--qc-report) やチャンク分割 (--chunk-duration)、音声指紋 (--fingerprint-db) は結合した音声を対象とするため、セグメントには適用しません。
+func (e *Engine) segmentWriter() AudioWriter {
+	w := e.writer
+	for {
+		u, ok := w.(WriterWrapper)
+		if !ok {
+			return w
+		}
+		w = u.Unwrap()
+	}
+}
+
+// writeSegments は音響効果を適用した各セグメントの WAV を、番号と話者名を付けたファイル名で SegmentDir に書き込みます。
+// OutputSampleRate が指定されている場合は、結合した音声と同じサンプルレートに変換してから書き込みます。
+// 書き込んだセグメントの尺の合計 (秒) を返します。
+func (e *Engine) writeSegments(ctx context.Context, requests []synthRequest, segmentWavs [][]byte) (float64, error) {
+	// gs:// などのURIを壊さないよう、filepath.Join ではなく文字列で連結する
+	dir := strings.TrimRight(e.config.SegmentDir, "/")
+	writer := e.segmentWriter()
+	var total float64
+	for i, data := range segmentWavs {
+		w, err := audio.ParseWAV(data)
+		if err != nil {
+			return 0, fmt.Errorf("セグメント %d のWAV解析に失敗しました: %w", i+1, err)
+		}
+		if e.config.OutputSampleRate > 0 && w.Format.SampleRate != uint32(e.config.OutputSampleRate) {
+			if w, err = audio.Resample(w, uint32(e.config.OutputSampleRate)); err != nil {
+				return 0, fmt.Errorf("セグメント %d のサンプルレートの変換に失敗しました: %w", i+1, err)
+			}
+			data = w.Bytes()
+		}
+
+		speaker := requests[i].segment.Speaker()
+		if speaker == "" {
+			speaker = script.TrimBrackets(e.fallbackSpeakerTag())
+		}
+		path := dir + "/" + segmentFileName(i, len(segmentWavs), speaker)
+		if err := writer.Write(ctx, path, bytes.NewReader(data), "audio/wav"); err != nil {
+			return 0, fmt.Errorf("セグメントの音声ファイルの書き込みに失敗しました (%s): %w", path, err)
+		}
+		total += w.Duration()
+	}
+	slog.InfoContext(ctx, "セグメントごとの音声ファイルを出力しました。", "dir", e.config.SegmentDir, "segments", len(segmentWavs))
+	return total, nil
+}
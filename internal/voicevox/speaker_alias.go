@@ -0,0 +1,81 @@
+package voicevox
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// reLeadingSpeakerTag は行頭 (結論マーカーの直後を含む) の話者タグを取り出します。
+var reLeadingSpeakerTag = regexp.MustCompile(`^(\s*(?:` + regexp.QuoteMeta(script.ConclusionOpen) + `\s*)?)(\[[^\[\]]+\])`)
+
+func init() {
+	script.SetSpeakerMatcher(isKnownSpeakerTag)
+}
+
+// isKnownSpeakerTag は tag が SupportedSpeakers の話者、またはその表記揺れかどうかを返します。
+// 話者定義は実行時に差し替えられるため、呼び出しのたびに現在の定義と照合します。
+func isKnownSpeakerTag(tag string) bool {
+	if canonical := canonicalSpeakerTag(tag); canonical != tag {
+		return true
+	}
+	return slices.ContainsFunc(SupportedSpeakers, func(m SpeakerMapping) bool { return m.ToolTag == tag })
+}
+
+// aliasKey は話者タグの表記揺れを照合するため、括弧と空白を除いて小文字化したキーを返します。
+func aliasKey(name string) string {
+	name = strings.ToLower(script.TrimBrackets(strings.TrimSpace(name)))
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, name)
+}
+
+// canonicalSpeakerTag は話者タグの表記揺れ (例: "[ズンダモン]", "[Zundamon]") を SupportedSpeakers の話者タグに正規化します。
+// 既知の話者タグ、または正規化先が見つからないタグはそのまま返します。
+func canonicalSpeakerTag(tag string) string {
+	if slices.ContainsFunc(SupportedSpeakers, func(m SpeakerMapping) bool { return m.ToolTag == tag }) {
+		return tag
+	}
+	key := aliasKey(tag)
+	for _, m := range SupportedSpeakers {
+		if aliasKey(m.ToolTag) == key || aliasKey(m.APIName) == key {
+			return m.ToolTag
+		}
+	}
+	if canonical, ok := speakerAliases[key]; ok {
+		return canonical
+	}
+	return tag
+}
+
+// NormalizeSpeakerAliases はスクリプトの各行の行頭の話者タグの表記揺れを、既知の話者タグに置き換えます。
+// 2番目の戻り値は置き換えた表記揺れのタグの一覧 (重複なし) です。
+func NormalizeSpeakerAliases(content string) (string, []string) {
+	var replaced []string
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		m := reLeadingSpeakerTag.FindStringSubmatchIndex(line)
+		if m == nil {
+			continue
+		}
+		tag := line[m[4]:m[5]]
+		canonical := canonicalSpeakerTag(tag)
+		if canonical == tag {
+			continue
+		}
+		lines[i] = line[:m[4]] + canonical + line[m[5]:]
+		if !slices.Contains(replaced, tag) {
+			replaced = append(replaced, tag)
+		}
+	}
+	if len(replaced) == 0 {
+		return content, nil
+	}
+	return strings.Join(lines, "\n"), replaced
+}
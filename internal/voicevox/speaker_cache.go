@@ -0,0 +1,114 @@
+package voicevox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultSpeakerCacheTTL は話者一覧のキャッシュの既定の有効期間です。
+	DefaultSpeakerCacheTTL = 24 * time.Hour
+	// speakerCacheFileName はユーザーのキャッシュディレクトリに置く話者一覧のキャッシュのファイル名です。
+	speakerCacheFileName = "prototypus/speakers.json"
+)
+
+// speakerCacheEntry はキャッシュファイルの内容です。取得元のエンジンURLとバージョンが一致する場合のみ有効です。
+type speakerCacheEntry struct {
+	APIURL    string       `json:"api_url"`
+	Version   string       `json:"version"`
+	FetchedAt time.Time    `json:"fetched_at"`
+	Speakers  []apiSpeaker `json:"speakers"`
+}
+
+// SpeakerCache はエンジンの /speakers の応答を TTL 付きでファイルにキャッシュします。
+// エンジンの起動直後は /speakers の応答が遅いため、CLI を連続実行する際の待ち時間を減らします。
+type SpeakerCache struct {
+	path    string
+	ttl     time.Duration
+	refresh bool
+}
+
+// NewSpeakerCache は path をキャッシュファイルとする SpeakerCache を生成します。
+// refresh が true の場合はキャッシュを使わずに再取得し、結果でキャッシュを更新します。
+func NewSpeakerCache(path string, ttl time.Duration, refresh bool) *SpeakerCache {
+	return &SpeakerCache{path: path, ttl: ttl, refresh: refresh}
+}
+
+// DefaultSpeakerCachePath はユーザーのキャッシュディレクトリ ($XDG_CACHE_HOME など) 上のキャッシュファイルのパスを返します。
+func DefaultSpeakerCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("キャッシュディレクトリを特定できません: %w", err)
+	}
+	return filepath.Join(dir, speakerCacheFileName), nil
+}
+
+// load は有効なキャッシュがあればその話者一覧を返し、無ければエンジンから取得してキャッシュを更新します。
+// キャッシュの読み書きの失敗は取得を妨げないよう、ログ出力のみに留めます。
+func (sc *SpeakerCache) load(ctx context.Context, client *Client) ([]apiSpeaker, error) {
+	version, err := engineVersion(ctx, client)
+	if err != nil {
+		slog.WarnContext(ctx, "エンジンのバージョンを取得できないため、話者一覧のキャッシュを使いません。", "error", err)
+		return fetchSpeakers(ctx, client)
+	}
+
+	if !sc.refresh {
+		if entry, ok := sc.read(); ok && entry.APIURL == client.apiURL && entry.Version == version && time.Since(entry.FetchedAt) < sc.ttl {
+			slog.DebugContext(ctx, "キャッシュした話者一覧を使います。", "path", sc.path, "fetched_at", entry.FetchedAt)
+			return entry.Speakers, nil
+		}
+	}
+
+	speakers, err := fetchSpeakers(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	if err := sc.write(speakerCacheEntry{APIURL: client.apiURL, Version: version, FetchedAt: time.Now(), Speakers: speakers}); err != nil {
+		slog.WarnContext(ctx, "話者一覧のキャッシュを保存できませんでした。", "path", sc.path, "error", err)
+	}
+	return speakers, nil
+}
+
+// read はキャッシュファイルを読み込みます。存在しない・解析できない場合は false を返します。
+func (sc *SpeakerCache) read() (*speakerCacheEntry, bool) {
+	body, err := os.ReadFile(sc.path)
+	if err != nil {
+		return nil, false
+	}
+	var entry speakerCacheEntry
+	if err := json.Unmarshal(body, &entry); err != nil {
+		return nil, false
+	}
+	return &entry, true
+}
+
+// write はキャッシュファイルを書き込みます。
+func (sc *SpeakerCache) write(entry speakerCacheEntry) error {
+	if err := os.MkdirAll(filepath.Dir(sc.path), 0o755); err != nil {
+		return err
+	}
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(sc.path, body, 0o644)
+}
+
+// engineVersion はエンジンの /version を取得します。
+func engineVersion(ctx context.Context, client *Client) (string, error) {
+	body, err := client.Get(ctx, "/version")
+	if err != nil {
+		return "", err
+	}
+	var version string
+	if err := json.Unmarshal(body, &version); err != nil {
+		return strings.TrimSpace(string(body)), nil
+	}
+	return version, nil
+}
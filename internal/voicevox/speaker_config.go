@@ -0,0 +1,104 @@
+package voicevox
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+)
+
+// speakerConfigFile は話者定義ファイル (JSON) の形式です。
+//
+//	{
+//	  "speakers": [{"api_name": "春日部つむぎ", "tool_tag": "[つむぎ]"}],
+//	  "styles": {"ノーマル": "[ノーマル]"},
+//	  "aliases": {"ツムギ": "[つむぎ]"}
+//	}
+type speakerConfigFile struct {
+	Speakers []struct {
+		APIName string `json:"api_name"`
+		ToolTag string `json:"tool_tag"`
+	} `json:"speakers"`
+	// Styles は VOICEVOX のスタイル名をキーとするスタイルタグです。
+	Styles map[string]string `json:"styles"`
+	// Aliases は話者タグの表記揺れ (括弧なし) をキーとする、正規化先の話者タグです。
+	Aliases map[string]string `json:"aliases"`
+}
+
+// LoadSpeakersFromConfig は話者定義ファイルを読み込み、SupportedSpeakers とスタイルの対応を置き換えます。
+// merge が true の場合は組み込みの定義に追加し、同じ話者名・スタイル名の定義はファイルの内容で上書きします。
+// 話者の一覧やスタイルの対応が空の項目は、置き換えの場合も組み込みの定義を残します。
+// 話者タグの表記揺れ (aliases) は merge の指定によらず組み込みの定義に追加します。
+func LoadSpeakersFromConfig(path string, merge bool) error {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("話者定義ファイルの読み込みに失敗しました (%s): %w", path, err)
+	}
+	var cfg speakerConfigFile
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return fmt.Errorf("話者定義ファイルの解析に失敗しました (%s): %w", path, err)
+	}
+
+	var speakers []SpeakerMapping
+	for _, sp := range cfg.Speakers {
+		apiName := strings.TrimSpace(sp.APIName)
+		if apiName == "" {
+			return fmt.Errorf("話者定義ファイルに api_name の無い話者があります (%s)", path)
+		}
+		tag := normalizeTag(sp.ToolTag, apiName)
+		speakers = append(speakers, SpeakerMapping{APIName: apiName, ToolTag: tag})
+	}
+	styles := make(map[string]string, len(cfg.Styles))
+	for name, tag := range cfg.Styles {
+		name = strings.TrimSpace(name)
+		styles[name] = normalizeTag(tag, name)
+	}
+	for alias, tag := range cfg.Aliases {
+		key := aliasKey(alias)
+		if key == "" || strings.Trim(strings.TrimSpace(tag), "[]") == "" {
+			return fmt.Errorf("話者定義ファイルに空の表記揺れ、または正規化先の無い表記揺れがあります (%s): '%s'", path, alias)
+		}
+		speakerAliases[key] = normalizeTag(tag, "")
+	}
+
+	if merge {
+		for _, sp := range speakers {
+			if i := slices.IndexFunc(SupportedSpeakers, func(m SpeakerMapping) bool { return m.APIName == sp.APIName }); i >= 0 {
+				SupportedSpeakers[i] = sp
+				continue
+			}
+			SupportedSpeakers = append(SupportedSpeakers, sp)
+		}
+		maps.Copy(styleApiNameToToolTag, styles)
+		return nil
+	}
+
+	if len(speakers) > 0 {
+		SupportedSpeakers = speakers
+	}
+	if len(styles) > 0 {
+		styleApiNameToToolTag = styles
+	}
+	return nil
+}
+
+// SaveSpeakers は現在の話者・スタイル・表記揺れの定義を保存し、その時点の定義に戻す関数を返します。
+func SaveSpeakers() (restore func()) {
+	speakers := slices.Clone(SupportedSpeakers)
+	styles := maps.Clone(styleApiNameToToolTag)
+	aliases := maps.Clone(speakerAliases)
+	return func() {
+		SupportedSpeakers, styleApiNameToToolTag, speakerAliases = speakers, styles, aliases
+	}
+}
+
+// normalizeTag はタグを "[名前]" の形式に揃えます。空の場合は name からタグを作ります。
+func normalizeTag(tag, name string) string {
+	tag = strings.Trim(strings.TrimSpace(tag), "[]")
+	if tag == "" {
+		tag = name
+	}
+	return "[" + tag + "]"
+}
@@ -0,0 +1,187 @@
+package voicevox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// defaultStyleName はスタイルが解決できない場合にフォールバックするスタイル名です。
+const defaultStyleName = "ノーマル"
+
+// SpeakerMapping は VOICEVOX 上の話者名とスクリプトで使う話者タグの対応です。
+type SpeakerMapping struct {
+	APIName string
+	ToolTag string
+}
+
+// SupportedSpeakers はスクリプトで使用できる話者の一覧です。先頭の話者はタグ無しテキストのフォールバック先になります。
+var SupportedSpeakers = []SpeakerMapping{
+	{APIName: "ずんだもん", ToolTag: "[ずんだもん]"},
+	{APIName: "四国めたん", ToolTag: "[めたん]"},
+}
+
+// speakerAliases は AI が出力しがちな話者タグの表記揺れと、正規化先の話者タグの対応です。
+// キーは aliasKey の形式 (括弧と空白を除いて小文字化) です。SupportedSpeakers の話者名 (APIName) は登録しなくても正規化されます。
+var speakerAliases = map[string]string{
+	"ズンダモン":        "[ずんだもん]",
+	"zundamon":     "[ずんだもん]",
+	"メタン":          "[めたん]",
+	"シコクメタン":       "[めたん]",
+	"metan":        "[めたん]",
+	"shikokumetan": "[めたん]",
+}
+
+// styleApiNameToToolTag は VOICEVOX のスタイル名とスクリプトのスタイルタグの対応です。
+var styleApiNameToToolTag = map[string]string{
+	"ノーマル": "[ノーマル]",
+	"あまあま": "[あまあま]",
+	"ツンツン": "[ツンツン]",
+	"セクシー": "[セクシー]",
+	"ささやき": "[ささやき]",
+	"ヒソヒソ": "[ヒソヒソ]",
+	"ヘロヘロ": "[ヘロヘロ]",
+	"なみだめ": "[なみだめ]",
+}
+
+// SupportedStyleNames はスクリプトで使用できるスタイル名 (括弧なし) を、既定スタイルを先頭にして返します。
+func SupportedStyleNames() []string {
+	names := []string{defaultStyleName}
+	for name := range styleApiNameToToolTag {
+		if name != defaultStyleName {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names[1:])
+	return names
+}
+
+// SpeakerData はエンジンから取得した話者・スタイルとスタイルIDの対応表です。
+type SpeakerData struct {
+	// StyleIDs は "[話者タグ][スタイルタグ]" をキーとするスタイルIDです。
+	StyleIDs map[string]int
+	// DefaultStyleIDs は話者タグをキーとする、その話者の既定スタイルIDです。
+	DefaultStyleIDs map[string]int
+}
+
+// SpeakerStyles は1人の話者がエンジンで実際に使えるスタイルの一覧です。
+type SpeakerStyles struct {
+	// Speaker は括弧を除いた話者タグ名です (例: "ずんだもん")。
+	Speaker string
+	// Styles は括弧を除いたスタイルタグ名です。SupportedStyleNames の順に並びます。
+	Styles []string
+}
+
+// AvailableStyles は話者ごとに、エンジンにスタイルIDが存在するスタイルを SupportedSpeakers の順に返します。
+func (d *SpeakerData) AvailableStyles() []SpeakerStyles {
+	var result []SpeakerStyles
+	for _, mapping := range SupportedSpeakers {
+		ss := SpeakerStyles{Speaker: script.TrimBrackets(mapping.ToolTag)}
+		for _, name := range SupportedStyleNames() {
+			tag := styleApiNameToToolTag[name]
+			if _, ok := d.StyleIDs[mapping.ToolTag+tag]; ok {
+				ss.Styles = append(ss.Styles, script.TrimBrackets(tag))
+			}
+		}
+		if len(ss.Styles) > 0 {
+			result = append(result, ss)
+		}
+	}
+	return result
+}
+
+// apiSpeaker は /speakers レスポンスの1要素です。
+type apiSpeaker struct {
+	Name   string `json:"name"`
+	Styles []struct {
+		Name string `json:"name"`
+		ID   int    `json:"id"`
+	} `json:"styles"`
+}
+
+// LoadSpeakers はエンジンの /speakers を取得し、対応している話者のスタイルIDを解決します。
+// client に SpeakerCache が設定されている場合は、有効なキャッシュがあればエンジンへの問い合わせを省略します。
+func LoadSpeakers(ctx context.Context, client *Client) (*SpeakerData, error) {
+	if client.speakerCache != nil {
+		speakers, err := client.speakerCache.load(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		return buildSpeakerData(speakers)
+	}
+
+	speakers, err := fetchSpeakers(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+	return buildSpeakerData(speakers)
+}
+
+// fetchSpeakers はエンジンの /speakers を取得して解析します。
+func fetchSpeakers(ctx context.Context, client *Client) ([]apiSpeaker, error) {
+	body, err := client.Get(ctx, "/speakers")
+	if err != nil {
+		return nil, fmt.Errorf("話者一覧の取得に失敗しました: %w", err)
+	}
+
+	var speakers []apiSpeaker
+	if err := json.Unmarshal(body, &speakers); err != nil {
+		return nil, fmt.Errorf("話者一覧の解析に失敗しました: %w", err)
+	}
+	return speakers, nil
+}
+
+// buildSpeakerData は /speakers のレスポンスから SpeakerData を構築します。
+func buildSpeakerData(speakers []apiSpeaker) (*SpeakerData, error) {
+	data := &SpeakerData{
+		StyleIDs:        make(map[string]int),
+		DefaultStyleIDs: make(map[string]int),
+	}
+
+	for _, mapping := range SupportedSpeakers {
+		for _, sp := range speakers {
+			if sp.Name != mapping.APIName {
+				continue
+			}
+			for i, style := range sp.Styles {
+				if tag, ok := styleApiNameToToolTag[style.Name]; ok {
+					data.StyleIDs[mapping.ToolTag+tag] = style.ID
+				}
+				if i == 0 || style.Name == defaultStyleName {
+					data.DefaultStyleIDs[mapping.ToolTag] = style.ID
+				}
+			}
+		}
+	}
+
+	if len(data.DefaultStyleIDs) == 0 {
+		return nil, fmt.Errorf("VOICEVOXエンジンに対応する話者が見つかりません")
+	}
+	return data, nil
+}
+
+// determineStyleID はセグメントの話者・スタイルタグからスタイルIDを決定します。
+// タグ無しのセグメントは fallbackSpeakerTag の既定スタイルで、スタイルタグを省略したセグメントと未知のスタイルはその話者の既定スタイルで合成します。
+// 話者が解決できない場合は false を返します。
+func (d *SpeakerData) determineStyleID(seg script.Segment, fallbackSpeakerTag string) (int, bool) {
+	speakerTag, styleTag := seg.SpeakerTag, seg.StyleTag
+	if speakerTag == "" {
+		speakerTag = fallbackSpeakerTag
+	}
+
+	if id, ok := d.StyleIDs[speakerTag+styleTag]; ok {
+		return id, true
+	}
+	id, ok := d.DefaultStyleIDs[speakerTag]
+	if !ok {
+		return 0, false
+	}
+	if styleTag != "" {
+		slog.Warn("未定義のスタイルタグのため既定スタイルにフォールバックします。", "speaker", speakerTag, "style", styleTag, "line", seg.Line)
+	}
+	return id, true
+}
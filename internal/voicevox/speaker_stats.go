@@ -0,0 +1,86 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"prototypus-ai-doc-go/internal/audio"
+	"prototypus-ai-doc-go/internal/script"
+)
+
+// SpeakerStat は完成音声における1人の話者の発話統計です。尺は実際の合成結果から算出します。
+type SpeakerStat struct {
+	Speaker string `json:"speaker"`
+	// Utterances はスクリプトの行単位の発話回数です。長いセリフを分割したセグメントは1回と数えます。
+	Utterances int `json:"utterances"`
+	// Segments は合成したセグメントの数です。
+	Segments int `json:"segments"`
+	// TotalSec は発話の合計時間 (セグメント先頭の無音とセグメント間の無音を除く) です。
+	TotalSec float64 `json:"total_sec"`
+	// AverageSec は1回の発話の平均時間です。
+	AverageSec float64 `json:"average_sec"`
+	// Share は全話者の合計発話時間に占める割合 (0〜1) です。
+	Share float64 `json:"share"`
+}
+
+// speakerStats は各セグメントの区間から話者別の発話統計を、スクリプトでの初登場順に集計します。
+// 話者タグの無いセグメントは fallbackSpeakerTag の話者として数えます。
+func speakerStats(requests []synthRequest, spans []audio.Span, fallbackSpeakerTag string) []SpeakerStat {
+	var stats []SpeakerStat
+	index := make(map[string]int)
+	lastLine := make(map[string]int)
+	var total float64
+	for i, req := range requests {
+		speaker := req.segment.Speaker()
+		if speaker == "" {
+			speaker = script.TrimBrackets(fallbackSpeakerTag)
+		}
+		n, ok := index[speaker]
+		if !ok {
+			n = len(stats)
+			index[speaker] = n
+			stats = append(stats, SpeakerStat{Speaker: speaker})
+		}
+
+		d := spans[i].End - spans[i].Start
+		stats[n].Segments++
+		stats[n].TotalSec += d
+		if line, seen := lastLine[speaker]; !seen || line != req.segment.Line {
+			stats[n].Utterances++
+			lastLine[speaker] = req.segment.Line
+		}
+		total += d
+	}
+
+	for i := range stats {
+		if stats[i].Utterances > 0 {
+			stats[i].AverageSec = stats[i].TotalSec / float64(stats[i].Utterances)
+		}
+		if total > 0 {
+			stats[i].Share = stats[i].TotalSec / total
+		}
+	}
+	return stats
+}
+
+// writeSpeakerStats は話者別の発話統計をログに出力し、JSON で path に書き込みます。
+func (e *Engine) writeSpeakerStats(ctx context.Context, path string, requests []synthRequest, spans []audio.Span) error {
+	stats := speakerStats(requests, spans, e.fallbackSpeakerTag())
+	for _, s := range stats {
+		slog.InfoContext(ctx, "話者別の発話統計", "speaker", s.Speaker, "utterances", s.Utterances, "segments", s.Segments,
+			"total_sec", fmt.Sprintf("%.2f", s.TotalSec), "average_sec", fmt.Sprintf("%.2f", s.AverageSec), "share", fmt.Sprintf("%.1f%%", s.Share*100))
+	}
+
+	body, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("話者別の発話統計のエンコードに失敗しました: %w", err)
+	}
+	if err := e.writer.Write(ctx, path, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("話者別の発話統計の書き込みに失敗しました (%s): %w", path, err)
+	}
+	slog.InfoContext(ctx, "話者別の発話統計を出力しました。", "speakers", len(stats), "path", path)
+	return nil
+}
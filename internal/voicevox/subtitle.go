@@ -0,0 +1,43 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// renderSRT はセグメントごとの区間から SRT 形式の字幕を組み立てます。
+// 長いテキストを分割したセグメントもそれぞれ1つの字幕エントリとして扱います。
+// speakerPrefix が true の場合、本文の前に「ずんだもん: 」のような話者名を付けます。
+func renderSRT(requests []synthRequest, spans []audio.Span, speakerPrefix bool) []byte {
+	var b bytes.Buffer
+	for i, req := range requests {
+		text := req.segment.Text
+		if speaker := req.segment.Speaker(); speakerPrefix && speaker != "" {
+			text = speaker + ": " + text
+		}
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s\n\n", i+1, srtTimestamp(spans[i].Start), srtTimestamp(spans[i].End), strings.TrimSpace(text))
+	}
+	return b.Bytes()
+}
+
+// srtTimestamp は秒を SRT のタイムスタンプ (HH:MM:SS,mmm) に変換します。
+func srtTimestamp(sec float64) string {
+	ms := int64(math.Round(sec * 1000))
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// writeSubtitle はセグメントごとの字幕を SRT 形式で path に書き込みます。
+func (e *Engine) writeSubtitle(ctx context.Context, path string, requests []synthRequest, spans []audio.Span) error {
+	body := renderSRT(requests, spans, e.config.SubtitleSpeakerPrefix)
+	if err := e.writer.Write(ctx, path, bytes.NewReader(body), "application/x-subrip; charset=utf-8"); err != nil {
+		return fmt.Errorf("字幕ファイルの書き込みに失敗しました (%s): %w", path, err)
+	}
+	slog.InfoContext(ctx, "字幕ファイルを出力しました。", "entries", len(requests), "path", path)
+	return nil
+}
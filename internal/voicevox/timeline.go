@@ -0,0 +1,83 @@
+package voicevox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// ABPoint は A-B 区間リピート用の1セグメント分の区間です。
+type ABPoint struct {
+	Index      int     `json:"index"`
+	Speaker    string  `json:"speaker"`
+	Style      string  `json:"style"`
+	Text       string  `json:"text"`
+	Line       int     `json:"line"`
+	Start      float64 `json:"start_sec"`
+	End        float64 `json:"end_sec"`
+	Conclusion bool    `json:"conclusion,omitempty"`
+}
+
+// segmentSpans は結合後の音声における各セグメントの開始・終了時刻を、セグメントWAVとセグメント間の無音・区切りマーカー音の尺の累積から算出します。
+// VOICEVOX はセグメントの先頭に微小な無音を含むため、開始時刻は無音を除いた実際の発話開始位置に合わせます。
+func segmentSpans(segmentWavs [][]byte, gaps []time.Duration, marker SegmentMarker) ([]audio.Span, error) {
+	spans := make([]audio.Span, len(segmentWavs))
+	var elapsed float64
+	for i, data := range segmentWavs {
+		w, err := audio.ParseWAV(data)
+		if err != nil {
+			return nil, fmt.Errorf("セグメント %d のWAV解析に失敗しました: %w", i+1, err)
+		}
+		if i < len(gaps) {
+			elapsed += gapSeconds(w.Format, gaps[i])
+		}
+		if i > 0 {
+			elapsed += gapSeconds(w.Format, marker.Duration)
+		}
+		end := elapsed + w.Duration()
+		onset := min(elapsed+audio.LeadingSilence(w, audio.DefaultSilenceLevelDB), end)
+		spans[i] = audio.Span{Start: onset, End: end}
+		elapsed = end
+	}
+	return spans, nil
+}
+
+// gapSeconds は silencePCM (および SegmentMarker) が挿入する無音 (マーカー音) の尺を、フレーム数の端数の切り捨てを含めて秒で返します。
+func gapSeconds(f audio.Format, d time.Duration) float64 {
+	if d <= 0 || f.SampleRate == 0 {
+		return 0
+	}
+	return float64(int(d.Seconds()*float64(f.SampleRate))) / float64(f.SampleRate)
+}
+
+// writeABPoints はセグメント境界の時刻リストを JSON で path に書き込みます。
+func (e *Engine) writeABPoints(ctx context.Context, path string, requests []synthRequest, spans []audio.Span) error {
+	points := make([]ABPoint, len(requests))
+	for i, req := range requests {
+		points[i] = ABPoint{
+			Index:      i + 1,
+			Speaker:    req.segment.Speaker(),
+			Style:      req.segment.Style(),
+			Text:       req.segment.Text,
+			Line:       req.segment.Line,
+			Start:      spans[i].Start,
+			End:        spans[i].End,
+			Conclusion: req.segment.Conclusion,
+		}
+	}
+
+	body, err := json.MarshalIndent(points, "", "  ")
+	if err != nil {
+		return fmt.Errorf("A-B区間リストのエンコードに失敗しました: %w", err)
+	}
+	if err := e.writer.Write(ctx, path, bytes.NewReader(body), "application/json"); err != nil {
+		return fmt.Errorf("A-B区間リストの書き込みに失敗しました (%s): %w", path, err)
+	}
+	slog.InfoContext(ctx, "セグメント境界の時刻リストを出力しました。", "segments", len(points), "path", path)
+	return nil
+}
@@ -0,0 +1,237 @@
+package voicevox
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// UserDictEntry はユーザー辞書の1語です。
+type UserDictEntry struct {
+	// Surface はスクリプト中の表記です (例: "VOICEVOX")。
+	Surface string `json:"surface"`
+	// Pronunciation はカタカナの読みです (例: "ボイスボックス")。
+	Pronunciation string `json:"pronunciation"`
+	// AccentType はアクセント核の位置 (読みの先頭からのモーラ数) です。0 は平板型、nil の場合はエンジンの推定に任せます。
+	AccentType *int `json:"accent_type"`
+}
+
+// UserDict は固有名詞や専門用語の読みとアクセントを上書きするユーザー辞書です (--user-dict)。
+// エンジンの /user_dict への登録は同じエンジンを使う他のジョブにも影響するため行わず、
+// audio_query に渡すテキストの表記を読みに置き換え、得られたクエリのアクセント句のアクセント位置を上書きします。
+type UserDict struct {
+	// entries は表記の長い順に並べた辞書の語です。
+	entries  []UserDictEntry
+	replacer *strings.Replacer
+}
+
+// LoadUserDict は path のユーザー辞書を読み込みます。拡張子が .csv の場合は
+// "表記,読み[,アクセント型]" の CSV (先頭行が "surface" で始まる場合は見出しとして読み飛ばします) として、
+// それ以外は UserDictEntry の JSON 配列として解析します。
+func LoadUserDict(path string) (*UserDict, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ユーザー辞書の読み込みに失敗しました (%s): %w", path, err)
+	}
+
+	var entries []UserDictEntry
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		entries, err = parseUserDictCSV(bytes.NewReader(body))
+	} else {
+		err = json.Unmarshal(body, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ユーザー辞書の解析に失敗しました (%s): %w", path, err)
+	}
+	dict, err := NewUserDict(entries)
+	if err != nil {
+		return nil, fmt.Errorf("ユーザー辞書が不正です (%s): %w", path, err)
+	}
+	return dict, nil
+}
+
+// parseUserDictCSV は "表記,読み[,アクセント型]" 形式の CSV を解析します。
+func parseUserDictCSV(r io.Reader) ([]UserDictEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []UserDictEntry
+	for i, rec := range records {
+		if i == 0 && strings.EqualFold(strings.TrimSpace(rec[0]), "surface") {
+			continue
+		}
+		if len(rec) < 2 || len(rec) > 3 {
+			return nil, fmt.Errorf("%d行目: 表記,読み[,アクセント型] の形式ではありません", i+1)
+		}
+		entry := UserDictEntry{Surface: rec[0], Pronunciation: rec[1]}
+		if len(rec) == 3 && strings.TrimSpace(rec[2]) != "" {
+			accent, err := strconv.Atoi(strings.TrimSpace(rec[2]))
+			if err != nil {
+				return nil, fmt.Errorf("%d行目: アクセント型 '%s' が整数ではありません", i+1, rec[2])
+			}
+			entry.AccentType = &accent
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// NewUserDict は entries からユーザー辞書を生成します。読みはカタカナのみ、アクセント型は 0 以上読みのモーラ数以下である必要があります。
+func NewUserDict(entries []UserDictEntry) (*UserDict, error) {
+	d := &UserDict{}
+	for _, e := range entries {
+		e.Surface = strings.TrimSpace(e.Surface)
+		e.Pronunciation = strings.TrimSpace(e.Pronunciation)
+		if e.Surface == "" || e.Pronunciation == "" {
+			return nil, errors.New("表記または読みが空の語があります")
+		}
+		if !isKatakana(e.Pronunciation) {
+			return nil, fmt.Errorf("'%s' の読み '%s' がカタカナではありません", e.Surface, e.Pronunciation)
+		}
+		if e.AccentType != nil && (*e.AccentType < 0 || *e.AccentType > moraCount(e.Pronunciation)) {
+			return nil, fmt.Errorf("'%s' のアクセント型 %d が読みのモーラ数 (%d) の範囲外です", e.Surface, *e.AccentType, moraCount(e.Pronunciation))
+		}
+		if slices.ContainsFunc(d.entries, func(x UserDictEntry) bool { return x.Surface == e.Surface }) {
+			return nil, fmt.Errorf("'%s' が重複しています", e.Surface)
+		}
+		d.entries = append(d.entries, e)
+	}
+	slices.SortStableFunc(d.entries, func(a, b UserDictEntry) int {
+		return cmp.Compare(utf8.RuneCountInString(b.Surface), utf8.RuneCountInString(a.Surface))
+	})
+
+	// 同じ位置で複数の表記に一致する場合は、先に渡した (長い) 表記が優先されます。
+	pairs := make([]string, 0, len(d.entries)*2)
+	for _, e := range d.entries {
+		pairs = append(pairs, e.Surface, e.Pronunciation)
+	}
+	d.replacer = strings.NewReplacer(pairs...)
+	return d, nil
+}
+
+// Len は辞書の語数を返します。
+func (d *UserDict) Len() int {
+	return len(d.entries)
+}
+
+// Rewrite はテキスト中の辞書の表記を読みに置き換えます。
+func (d *UserDict) Rewrite(text string) string {
+	return d.replacer.Replace(text)
+}
+
+// overrideAccents はアクセント句のうち、モーラの並びが辞書の読みで始まる句のアクセント位置を辞書のアクセント型で上書きし、
+// 上書きした句があったかを返します。平板型 (0) の語はアクセント句の末尾まで下がらないよう、句のモーラ数を設定します。
+func (d *UserDict) overrideAccents(phrases []any) bool {
+	changed := false
+	for _, p := range phrases {
+		phrase, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		moras, _ := phrase["moras"].([]any)
+		var kana strings.Builder
+		for _, m := range moras {
+			if mora, ok := m.(map[string]any); ok {
+				text, _ := mora["text"].(string)
+				kana.WriteString(text)
+			}
+		}
+		for _, e := range d.entries {
+			if e.AccentType == nil || !strings.HasPrefix(kana.String(), e.Pronunciation) {
+				continue
+			}
+			accent := *e.AccentType
+			if accent == 0 {
+				accent = len(moras)
+			}
+			phrase["accent"] = accent
+			changed = true
+			break
+		}
+	}
+	return changed
+}
+
+// applyUserDict は audio_query のクエリのアクセント句にユーザー辞書のアクセント型を反映します。
+// アクセント位置を変えただけでは音高が変わらないため、上書きした場合は /mora_pitch で音高を再計算します。
+func (c *Client) applyUserDict(ctx context.Context, query []byte, styleID int) ([]byte, error) {
+	if c.userDict == nil {
+		return query, nil
+	}
+
+	var q map[string]any
+	if err := json.Unmarshal(query, &q); err != nil {
+		return nil, fmt.Errorf("audio_queryの解析に失敗しました: %w", err)
+	}
+	phrases, _ := q["accent_phrases"].([]any)
+	if !c.userDict.overrideAccents(phrases) {
+		return query, nil
+	}
+
+	body, err := json.Marshal(phrases)
+	if err != nil {
+		return nil, fmt.Errorf("アクセント句のエンコードに失敗しました: %w", err)
+	}
+	params := url.Values{}
+	params.Set("speaker", strconv.Itoa(styleID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+"/mora_pitch?"+params.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("mora_pitchリクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.do(req, "/mora_pitch")
+	if err != nil {
+		return nil, err
+	}
+	var updated []any
+	if err := json.Unmarshal(resp, &updated); err != nil {
+		return nil, fmt.Errorf("mora_pitchレスポンスの解析に失敗しました: %w", err)
+	}
+	q["accent_phrases"] = updated
+
+	edited, err := json.Marshal(q)
+	if err != nil {
+		return nil, fmt.Errorf("audio_queryのエンコードに失敗しました: %w", err)
+	}
+	return edited, nil
+}
+
+// isKatakana は s がカタカナと長音符のみからなるかを返します。
+func isKatakana(s string) bool {
+	for _, r := range s {
+		if (r < 'ァ' || r > 'ヴ') && r != 'ー' {
+			return false
+		}
+	}
+	return true
+}
+
+// moraCount はカタカナの読みのモーラ数を返します。拗音などの小書きの仮名は直前の仮名と合わせて1モーラと数えます。
+func moraCount(kana string) int {
+	n := 0
+	for _, r := range kana {
+		if !strings.ContainsRune("ァィゥェォャュョヮ", r) {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,151 @@
+package voicevox
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"prototypus-ai-doc-go/internal/audio"
+)
+
+// CombinedPCM は複数のWAVデータから連結した、ヘッダーを含まない生のPCMとそのフォーマットです。
+// 動画のオーディオトラックなど、WAV以外のコンテナに流し込む場合に使います。
+type CombinedPCM struct {
+	// Format はサンプルレート・チャンネル数・ビット深度などのフォーマット情報です。
+	Format audio.Format
+	// Data はリトルエンディアンのインターリーブされたPCMです。
+	Data []byte
+}
+
+// ExtractCombinedPCM は複数のWAVデータのPCMを隙間なく連結し、先頭ファイルのフォーマットとともに返します。
+// フォーマットが先頭と異なるデータがある場合はエラーを返します。
+func ExtractCombinedPCM(wavFiles [][]byte) (*CombinedPCM, error) {
+	return combinePCM(wavFiles, nil, SegmentMarker{})
+}
+
+// combineWavData は複数のWAVデータのPCMを連結し、先頭ファイルのフォーマットで1つのWAVにまとめます。
+// 無音・区切りマーカー音の挿入とフォーマットの検査は combinePCM と同じです。
+func combineWavData(wavFiles [][]byte, gaps []time.Duration, marker SegmentMarker) ([]byte, error) {
+	f, parts, err := pcmParts(wavFiles, gaps, marker)
+	if err != nil {
+		return nil, err
+	}
+	size := partsSize(parts)
+	header := audio.EncodeHeader(f, size)
+	buf := make([]byte, 0, len(header)+size)
+	buf = append(buf, header...)
+	for _, part := range parts {
+		buf = append(buf, part...)
+	}
+	return buf, nil
+}
+
+// combinedWavReader は combineWavData と同じ WAV を、PCM を連結したバッファを作らずに順に読み出す io.Reader と、その再生時間 (秒) を返します。
+// 各セグメントの PCM は wavFiles を参照するため、読み出し終わるまで wavFiles を変更しないでください。
+func combinedWavReader(wavFiles [][]byte, gaps []time.Duration, marker SegmentMarker) (io.Reader, float64, error) {
+	f, parts, err := pcmParts(wavFiles, gaps, marker)
+	if err != nil {
+		return nil, 0, err
+	}
+	size := partsSize(parts)
+	readers := []io.Reader{bytes.NewReader(audio.EncodeHeader(f, size))}
+	for _, part := range parts {
+		readers = append(readers, bytes.NewReader(part))
+	}
+	return io.MultiReader(readers...), float64(size) / float64(f.BlockAlign) / float64(f.SampleRate), nil
+}
+
+// combinePCM は複数のWAVデータのPCMを連結し、先頭ファイルのフォーマットとともに返します。
+func combinePCM(wavFiles [][]byte, gaps []time.Duration, marker SegmentMarker) (*CombinedPCM, error) {
+	f, parts, err := pcmParts(wavFiles, gaps, marker)
+	if err != nil {
+		return nil, err
+	}
+	pcm := make([]byte, 0, partsSize(parts))
+	for _, part := range parts {
+		pcm = append(pcm, part...)
+	}
+	return &CombinedPCM{Format: f, Data: pcm}, nil
+}
+
+// pcmParts は複数のWAVデータを連結する順に、無音・区切りマーカー音・各データのPCMの断片を先頭ファイルのフォーマットとともに返します。
+// gaps が nil でない場合、gaps[i] の長さの無音を i 番目のデータの直前に挿入します。
+// marker が有効な場合、2番目以降のデータの直前 (無音の後) に区切りマーカー音を挿入します。
+// フォーマット (チャンネル数・サンプルレート・ビット深度など) が先頭と異なるセグメントがある場合はエラーを返します。
+func pcmParts(wavFiles [][]byte, gaps []time.Duration, marker SegmentMarker) (audio.Format, [][]byte, error) {
+	if len(wavFiles) == 0 {
+		return audio.Format{}, nil, fmt.Errorf("結合するWAVデータがありません")
+	}
+
+	first, err := audio.ParseWAV(wavFiles[0])
+	if err != nil {
+		return audio.Format{}, nil, fmt.Errorf("セグメント 1 のWAV解析に失敗しました: %w", err)
+	}
+
+	// フォーマットの異なるPCMを連結すると壊れた音声になるため、結合前に全セグメントのfmtチャンクを先頭と照合する
+	for i, wavData := range wavFiles[1:] {
+		w, err := audio.ParseWAV(wavData)
+		if err != nil {
+			return audio.Format{}, nil, fmt.Errorf("セグメント %d のWAV解析に失敗しました: %w", i+2, err)
+		}
+		if w.Format != first.Format {
+			return audio.Format{}, nil, fmt.Errorf("セグメント %d のWAVフォーマットが先頭セグメントと一致しません (チャンネル数 %d/%d, サンプルレート %d/%d, ビット深度 %d/%d)",
+				i+2, w.Format.Channels, first.Format.Channels, w.Format.SampleRate, first.Format.SampleRate, w.Format.BitsPerSample, first.Format.BitsPerSample)
+		}
+	}
+
+	if err := marker.validate(first.Format); err != nil {
+		return audio.Format{}, nil, err
+	}
+
+	var parts [][]byte
+	for i, wavData := range wavFiles {
+		data, err := ExtractAudioData(wavData)
+		if err != nil {
+			return audio.Format{}, nil, fmt.Errorf("セグメント %d の音声データ抽出に失敗しました: %w", i+1, err)
+		}
+		if i < len(gaps) {
+			parts = append(parts, silencePCM(first.Format, gaps[i]))
+		}
+		if i > 0 {
+			parts = append(parts, marker.pcm(first.Format))
+		}
+		parts = append(parts, data)
+	}
+	return first.Format, parts, nil
+}
+
+// partsSize は PCM の断片の合計バイト数を返します。
+func partsSize(parts [][]byte) int {
+	size := 0
+	for _, part := range parts {
+		size += len(part)
+	}
+	return size
+}
+
+// ExtractAudioData はWAVデータから data チャンクのPCMを取り出します。
+func ExtractAudioData(wavData []byte) ([]byte, error) {
+	w, err := audio.ParseWAV(wavData)
+	if err != nil {
+		return nil, err
+	}
+	return w.Data, nil
+}
+
+// silencePCM は fmt チャンクのサンプルレートから算出した d の長さの無音PCMを返します。
+func silencePCM(f audio.Format, d time.Duration) []byte {
+	if d <= 0 {
+		return nil
+	}
+	frames := int(d.Seconds() * float64(f.SampleRate))
+	silence := make([]byte, frames*int(f.BlockAlign))
+	if f.BitsPerSample == 8 {
+		// 8bit PCM は符号なしのため、無音は中央値の 0x80 で表す
+		for i := range silence {
+			silence[i] = 0x80
+		}
+	}
+	return silence
+}